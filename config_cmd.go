@@ -23,6 +23,110 @@ pager: false
 width: 80
 # show all files, including hidden and ignored.
 all: false
+
+# pager keys: "default" or "vim", where gg goes to the top
+keyMode: "default"
+# keys bound to pager actions, in place of the usual ones
+# keys:
+#   copy: x
+#   lineDown: [j, ctrl+n]
+# lines the half page keys and the mouse wheel scroll by (0 for the usual)
+scrollStep: 0
+# animate jumps to the top, the bottom and headings
+smoothScroll: false
+
+# JSON style file to render with instead of style, reloaded with S
+glamourStylePath: ""
+# chroma theme to highlight code with, like "monokai" (empty for the style's)
+chromaTheme: ""
+# show the language of fenced code blocks above them
+codeBlockLabels: false
+# emphasis for inline code: any of bold, underline, reverse and background
+inlineCodeEmphasis: ""
+# column to wrap code files at (0 to not wrap them)
+codeWrapWidth: 0
+# colors to render with: truecolor, 256, 16 or none (empty to detect)
+colorDepth: ""
+# columns left blank on either side of the document
+contentMargin: 0
+# collapse runs of blank lines outside of code blocks
+compact: false
+# replace emoji shortcodes like :rocket: with the emoji
+renderEmoji: false
+# indicator shown in the gutter next to headings (empty for none)
+headingGutterIndicator: ""
+# show the front matter of documents above them
+showFrontMatter: false
+# number lines relative to the top of the view
+relativeLineNumbers: false
+# show the word count and reading time in the status bar
+showReadingTime: false
+# leave code blocks out of the word count
+readingTimeSkipCode: false
+# show the line position in the status bar
+showLinePosition: false
+# widest the help gets (0 for the terminal's width)
+helpMaxWidth: 0
+# keep the top line in place when toggling help
+pinHelpToggle: false
+# colors of the focused link (reverse video if neither is set)
+focusedLinkForeground: ""
+focusedLinkBackground: ""
+# encoding of local documents, like "latin1" ("auto" detects UTF-16 and UTF-8)
+encoding: "auto"
+
+# reopen documents where they were left
+rememberPositions: false
+# remember recently viewed documents between sessions, listed with ctrl+o
+rememberRecent: false
+# briefly highlight headings jumped to
+flashHeadingJumps: true
+# anchors shared by several headings: "suffix" or "first"
+duplicateHeadingSlugs: "suffix"
+# line that separates slides in presentation mode
+slideSeparator: "---"
+# allow showing git blame with B
+gitBlame: false
+# allow browsing the terms of definition lists and abbreviations
+definitionTooltips: false
+# follow links to local files that aren't markdown by editing them
+followNonMarkdownLinks: false
+# how many levels of links the link graph follows
+linkGraphDepth: 3
+# size in bytes past which following a link asks first or shows the source
+largeFileThreshold: 5242880
+# what to do with large files: "confirm" or "raw"
+largeFileAction: "confirm"
+# splice in <!-- include: file.md --> lines
+includes: false
+# reload documents when files they include change
+watchIncludes: false
+
+# scroll to the bottom when a document changes on disk, like tail -f
+follow: false
+# say so when a document is reloaded because it changed
+reloadIndicator: true
+# how long a document has to be left alone after changing before reloading
+watchDebounce: "150ms"
+# stop watching for changes after this long without key presses (0 for never)
+watchIdleTimeout: 0
+
+# zen mode: width of the reading column and dimming all but the middle line
+zenWidth: 80
+zenDimming: false
+
+# what to do when there's no clipboard: "none" or "file"
+clipboardFallback: "none"
+# format of copied links: "markdown" or "plain"
+linkListFormat: "markdown"
+# trailing newlines when copying the document: "verbatim", "single" or "strip"
+copyTrailingNewline: "verbatim"
+# leave out line numbers when copying the view as text
+copyStripGutter: true
+# largest content in bytes copied using OSC 52 (0 for no limit)
+osc52MaxBytes: 100000
+# count repeated status messages, like "Copied contents (x3)"
+countRepeatedStatusMessages: false
 `
 
 var configCmd = &cobra.Command{
@@ -1,7 +1,11 @@
 package main
 
 import (
+	"slices"
+	"strings"
 	"testing"
+
+	"github.com/spf13/viper"
 )
 
 func TestGlowFlags(t *testing.T) {
@@ -39,3 +43,72 @@ func TestGlowFlags(t *testing.T) {
 		}
 	}
 }
+
+func TestDefaultConfig(t *testing.T) {
+	v := viper.New()
+	v.SetConfigType("yaml")
+	if err := v.ReadConfig(strings.NewReader(defaultConfig)); err != nil {
+		t.Fatalf("default config doesn't parse: %v", err)
+	}
+
+	// Writing the config file shouldn't change anything, other than the
+	// settings it has always had.
+	original := []string{"style", "mouse", "pager", "width", "all"}
+	for _, key := range v.AllKeys() {
+		if slices.Contains(original, key) {
+			continue
+		}
+		var got, want any
+		switch v.Get(key).(type) {
+		case bool:
+			got, want = v.GetBool(key), viper.GetBool(key)
+		case int:
+			got, want = v.GetInt(key), viper.GetInt(key)
+		default:
+			got, want = v.GetString(key), viper.GetString(key)
+		}
+		if got != want {
+			t.Errorf("%s: expected the default, %v, got %v", key, want, got)
+		}
+	}
+}
+
+func TestChromaTheme(t *testing.T) {
+	t.Cleanup(func() { viper.Set("chromaTheme", "") })
+
+	viper.Set("chromaTheme", "Monokai")
+	if theme, warning := chromaTheme(); theme != "monokai" || warning != "" {
+		t.Errorf("expected the monokai theme, got %q and warning %q", theme, warning)
+	}
+	viper.Set("chromaTheme", "nope")
+	if theme, warning := chromaTheme(); theme != "" || warning == "" {
+		t.Errorf("expected a warning and the style's theme, got %q and warning %q", theme, warning)
+	}
+}
+
+func TestLargeFileAction(t *testing.T) {
+	t.Cleanup(func() { viper.Set("largeFileAction", "confirm") })
+
+	viper.Set("largeFileAction", "Raw")
+	if action, warning := largeFileAction(); action != "raw" || warning != "" {
+		t.Errorf("expected to show large files unrendered, got %q and warning %q", action, warning)
+	}
+	viper.Set("largeFileAction", "skip")
+	if action, warning := largeFileAction(); action != "confirm" || warning == "" {
+		t.Errorf("expected a warning and to ask first, got %q and warning %q", action, warning)
+	}
+}
+
+func TestReloadTUIConfig_NoConfigFile(t *testing.T) {
+	// Starting up writes a config file if there's none, so look for one by
+	// another name.
+	viper.SetConfigName("glow-missing")
+	t.Cleanup(func() {
+		viper.SetConfigName("glow")
+		_ = viper.ReadInConfig()
+	})
+
+	if _, err := reloadTUIConfig(rootCmd, ""); err != nil {
+		t.Fatalf("expected reloading without a config file to work, got %v", err)
+	}
+}
@@ -13,6 +13,7 @@ import (
 	"path/filepath"
 	"strings"
 
+	chromastyles "github.com/alecthomas/chroma/v2/styles"
 	"github.com/caarlos0/env/v11"
 	"github.com/charmbracelet/glamour"
 	"github.com/charmbracelet/glamour/styles"
@@ -162,6 +163,34 @@ func validateStyle(style string) error {
 	return nil
 }
 
+// chromaTheme returns the configured theme for highlighting code, or "" to
+// keep the one of the style if none is configured or it doesn't exist, in
+// which case it also returns a warning to show.
+func chromaTheme() (string, string) {
+	theme := strings.ToLower(viper.GetString("chromaTheme"))
+	if theme == "" {
+		return "", ""
+	}
+	if _, ok := chromastyles.Registry[theme]; !ok {
+		log.Warn("unknown chroma theme, using the style's", "theme", theme)
+		return "", fmt.Sprintf("Unknown chroma theme %q, using the style's", theme)
+	}
+	return theme, ""
+}
+
+// largeFileAction returns what to do when following a link to a large file,
+// or "confirm" if the configured action isn't known, in which case it also
+// returns a warning to show.
+func largeFileAction() (string, string) {
+	action := strings.ToLower(viper.GetString("largeFileAction"))
+	switch action {
+	case "confirm", "raw":
+		return action, ""
+	}
+	log.Warn("unknown large file action, asking first", "action", action)
+	return "confirm", fmt.Sprintf("Unknown large file action %q, asking first", action)
+}
+
 func validateOptions(cmd *cobra.Command) error {
 	// grab config values from Viper
 	width = viper.GetUint("width")
@@ -233,7 +262,7 @@ func execute(cmd *cobra.Command, args []string) error {
 	switch len(args) {
 	// TUI running on cwd
 	case 0:
-		return runTUI("", "")
+		return runTUI(cmd, "", "")
 
 	// TUI with possible dir argument
 	case 1:
@@ -243,7 +272,7 @@ func execute(cmd *cobra.Command, args []string) error {
 		if err == nil && info.IsDir() {
 			p, err := filepath.Abs(args[0])
 			if err == nil {
-				return runTUI(p, "")
+				return runTUI(cmd, p, "")
 			}
 		}
 		fallthrough
@@ -332,7 +361,7 @@ func executeCLI(cmd *cobra.Command, src *source, w io.Writer) error {
 		if !isURL(src.URL) {
 			path = src.URL
 		}
-		return runTUI(path, content)
+		return runTUI(cmd, path, content)
 	default:
 		if _, err = fmt.Fprint(w, out); err != nil {
 			return fmt.Errorf("unable to write to writer: %w", err)
@@ -341,11 +370,63 @@ func executeCLI(cmd *cobra.Command, src *source, w io.Writer) error {
 	}
 }
 
-func runTUI(path string, content string) error {
+func runTUI(cmd *cobra.Command, path string, content string) error {
+	cfg, err := tuiConfig(path)
+	if err != nil {
+		return err
+	}
+	cfg.ReloadConfig = func() (ui.Config, error) {
+		return reloadTUIConfig(cmd, path)
+	}
+
+	// Run Bubble Tea program
+	if _, err := ui.NewProgram(cfg, content).Run(); err != nil {
+		return fmt.Errorf("unable to run tui program: %w", err)
+	}
+
+	return nil
+}
+
+// reloadTUIConfig reads the configuration file again. If it can't be read,
+// the configuration in use stays as it is. Not having one is fine, as it is
+// at startup.
+func reloadTUIConfig(cmd *cobra.Command, path string) (ui.Config, error) {
+	if err := viper.ReadInConfig(); err != nil {
+		if _, ok := err.(viper.ConfigFileNotFoundError); !ok {
+			return ui.Config{}, fmt.Errorf("unable to read config: %w", err)
+		}
+	}
+	if err := validateOptions(cmd); err != nil {
+		return ui.Config{}, err
+	}
+	return tuiConfig(path)
+}
+
+// keyBindings returns the keys bound to pager actions in the configuration
+// file, where an action takes a key or a list of them:
+//
+//	keys:
+//	  copy: x
+//	  lineDown: [j, ctrl+n]
+func keyBindings() map[string][]string {
+	section := viper.GetStringMap("keys")
+	if len(section) == 0 {
+		return nil
+	}
+	bindings := make(map[string][]string, len(section))
+	for action := range section {
+		bindings[action] = viper.GetStringSlice("keys." + action)
+	}
+	return bindings
+}
+
+// tuiConfig returns the configuration of the TUI from the environment, the
+// command line and the configuration file.
+func tuiConfig(path string) (ui.Config, error) {
 	// Read environment to get debugging stuff
 	cfg, err := env.ParseAs[ui.Config]()
 	if err != nil {
-		return fmt.Errorf("error parsing config: %v", err)
+		return cfg, fmt.Errorf("error parsing config: %v", err)
 	}
 
 	// use style set in env, or auto if unset
@@ -359,13 +440,69 @@ func runTUI(path string, content string) error {
 	cfg.GlamourMaxWidth = width
 	cfg.EnableMouse = mouse
 	cfg.PreserveNewLines = preserveNewLines
-
-	// Run Bubble Tea program
-	if _, err := ui.NewProgram(cfg, content).Run(); err != nil {
-		return fmt.Errorf("unable to run tui program: %w", err)
-	}
-
-	return nil
+	cfg.ClipboardFallback = viper.GetString("clipboardFallback")
+	cfg.FlashHeadingJumps = viper.GetBool("flashHeadingJumps")
+	cfg.DuplicateHeadingSlugs = viper.GetString("duplicateHeadingSlugs")
+	cfg.SlideSeparator = viper.GetString("slideSeparator")
+	cfg.CodeWrapWidth = viper.GetUint("codeWrapWidth")
+	cfg.GitBlame = viper.GetBool("gitBlame")
+	cfg.InlineCodeEmphasis = viper.GetString("inlineCodeEmphasis")
+	var warning string
+	if cfg.ChromaTheme, warning = chromaTheme(); warning != "" {
+		cfg.Warnings = append(cfg.Warnings, warning)
+	}
+	cfg.CodeBlockLabels = viper.GetBool("codeBlockLabels")
+	cfg.HelpMaxWidth = viper.GetUint("helpMaxWidth")
+	cfg.PinHelpToggle = viper.GetBool("pinHelpToggle")
+	cfg.LinkListFormat = viper.GetString("linkListFormat")
+	cfg.HeadingGutterIndicator = viper.GetString("headingGutterIndicator")
+	cfg.CopyTrailingNewline = viper.GetString("copyTrailingNewline")
+	cfg.FocusedLinkForeground = viper.GetString("focusedLinkForeground")
+	cfg.FocusedLinkBackground = viper.GetString("focusedLinkBackground")
+	cfg.Encoding = viper.GetString("encoding")
+	cfg.CountRepeatedStatusMessages = viper.GetBool("countRepeatedStatusMessages")
+	cfg.ReloadIndicator = viper.GetBool("reloadIndicator")
+	cfg.LinkGraphDepth = viper.GetUint("linkGraphDepth")
+	cfg.OSC52MaxBytes = viper.GetUint("osc52MaxBytes")
+	cfg.CopyStripGutter = viper.GetBool("copyStripGutter")
+	cfg.LargeFileThreshold = viper.GetUint("largeFileThreshold")
+	if cfg.LargeFileAction, warning = largeFileAction(); warning != "" {
+		cfg.Warnings = append(cfg.Warnings, warning)
+	}
+	cfg.DefinitionTooltips = viper.GetBool("definitionTooltips")
+	cfg.ContentMargin = viper.GetUint("contentMargin")
+	cfg.ScrollStep = viper.GetUint("scrollStep")
+	cfg.SmoothScroll = viper.GetBool("smoothScroll")
+	cfg.Compact = viper.GetBool("compact")
+	cfg.Follow = viper.GetBool("follow")
+	cfg.WatchIdleTimeout = viper.GetDuration("watchIdleTimeout")
+	cfg.WatchDebounce = viper.GetDuration("watchDebounce")
+	cfg.ColorDepth = viper.GetString("colorDepth")
+	cfg.RelativeLineNumbers = viper.GetBool("relativeLineNumbers")
+	cfg.ShowFrontMatter = viper.GetBool("showFrontMatter")
+	cfg.ShowReadingTime = viper.GetBool("showReadingTime")
+	cfg.ReadingTimeSkipCode = viper.GetBool("readingTimeSkipCode")
+	cfg.ShowLinePosition = viper.GetBool("showLinePosition")
+	if viper.GetBool("rememberPositions") {
+		cfg.PositionsFile = stateFile("positions.json")
+	}
+	cfg.BookmarksFile = stateFile("bookmarks.json")
+	if viper.GetBool("rememberRecent") {
+		cfg.RecentFile = stateFile("recent.json")
+	}
+	cfg.KeyMode = viper.GetString("keyMode")
+	cfg.KeyBindings = keyBindings()
+	if path := viper.GetString("glamourStylePath"); path != "" {
+		cfg.GlamourStylePath = utils.ExpandPath(path)
+	}
+	cfg.Includes = viper.GetBool("includes")
+	cfg.WatchIncludes = viper.GetBool("watchIncludes")
+	cfg.RenderEmoji = viper.GetBool("renderEmoji")
+	cfg.FollowNonMarkdownLinks = viper.GetBool("followNonMarkdownLinks")
+	cfg.ZenWidth = viper.GetUint("zenWidth")
+	cfg.ZenDimming = viper.GetBool("zenDimming")
+
+	return cfg, nil
 }
 
 func main() {
@@ -419,16 +556,33 @@ func init() {
 	viper.SetDefault("style", styles.AutoStyle)
 	viper.SetDefault("width", 0)
 	viper.SetDefault("all", true)
+	viper.SetDefault("clipboardFallback", "none")
+	viper.SetDefault("flashHeadingJumps", true)
+	viper.SetDefault("duplicateHeadingSlugs", "suffix")
+	viper.SetDefault("slideSeparator", "---")
+	viper.SetDefault("linkListFormat", "markdown")
+	viper.SetDefault("copyTrailingNewline", "verbatim")
+	viper.SetDefault("encoding", "auto")
+	viper.SetDefault("reloadIndicator", true)
+	viper.SetDefault("linkGraphDepth", 3)
+	viper.SetDefault("osc52MaxBytes", 100000)
+	viper.SetDefault("copyStripGutter", true)
+	viper.SetDefault("largeFileThreshold", 5*1024*1024)
+	viper.SetDefault("largeFileAction", "confirm")
+	viper.SetDefault("zenWidth", 80)
+	viper.SetDefault("watchDebounce", "150ms")
+	viper.SetDefault("keyMode", "default")
 
 	rootCmd.AddCommand(configCmd, manCmd)
 }
 
-func tryLoadConfigFromDefaultPlaces() {
+// configDirs returns the directories the configuration is looked for in,
+// most preferred first.
+func configDirs() ([]string, error) {
 	scope := gap.NewScope(gap.User, "glow")
 	dirs, err := scope.ConfigDirs()
 	if err != nil {
-		fmt.Println("Could not load find configuration directory.")
-		os.Exit(1)
+		return nil, fmt.Errorf("unable to find configuration directory: %w", err)
 	}
 
 	if c := os.Getenv("XDG_CONFIG_HOME"); c != "" {
@@ -438,6 +592,25 @@ func tryLoadConfigFromDefaultPlaces() {
 	if c := os.Getenv("GLOW_CONFIG_HOME"); c != "" {
 		dirs = append([]string{c}, dirs...)
 	}
+	return dirs, nil
+}
+
+// stateFile returns a file in the configuration directory where state is
+// kept between sessions, or "" if there's nowhere to keep it.
+func stateFile(name string) string {
+	dirs, err := configDirs()
+	if err != nil || len(dirs) == 0 {
+		return ""
+	}
+	return filepath.Join(dirs[0], name)
+}
+
+func tryLoadConfigFromDefaultPlaces() {
+	dirs, err := configDirs()
+	if err != nil {
+		fmt.Println("Could not load find configuration directory.")
+		os.Exit(1)
+	}
 
 	for _, v := range dirs {
 		viper.AddConfigPath(v)
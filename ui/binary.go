@@ -0,0 +1,94 @@
+package ui
+
+import (
+	"bytes"
+	"os/exec"
+	"runtime"
+	"unicode/utf8"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// How much of a file we look at to decide whether it's binary.
+const binarySniffLen = 8000
+
+// isBinaryContent reports whether data looks like a binary file: it contains
+// null bytes, or more than a tenth of it isn't valid UTF-8.
+func isBinaryContent(data []byte) bool {
+	if len(data) > binarySniffLen {
+		data = data[:binarySniffLen]
+		// Don't count a rune we cut in half as invalid.
+		for i := 0; i < utf8.UTFMax && len(data) > 0 && !utf8.Valid(data); i++ {
+			data = data[:len(data)-1]
+		}
+	}
+	if bytes.IndexByte(data, 0) >= 0 {
+		return true
+	}
+
+	var runes, invalid int
+	for len(data) > 0 {
+		r, size := utf8.DecodeRune(data)
+		if r == utf8.RuneError && size == 1 {
+			invalid++
+		}
+		runes++
+		data = data[size:]
+	}
+	return invalid*10 > runes
+}
+
+// binaryNotice is shown instead of a binary file, telling which key opens it
+// with the default application, if any.
+func binaryNotice(k keyBindings) string {
+	notice := "\n  Binary file, not displayed.\n"
+	if open := k.help(actionOpen); open != "" {
+		notice += "\n  Press " + open + " to open it with the default application.\n"
+	}
+	return notice
+}
+
+type (
+	externalOpenMsg struct{ err error }
+	browserOpenMsg  struct{ err error }
+)
+
+// startOpener starts a command opening something with the system's default
+// application, and waits for it in the background so it doesn't linger once
+// it's done. It's a variable so it can be stubbed out in tests.
+var startOpener = func(cmd *exec.Cmd) error {
+	if err := cmd.Start(); err != nil {
+		return err //nolint:wrapcheck
+	}
+	go cmd.Wait() //nolint:errcheck
+	return nil
+}
+
+// openerCommand returns the command that opens a file or URL with the
+// system's default application.
+func openerCommand(target string) *exec.Cmd {
+	switch runtime.GOOS {
+	case "darwin":
+		return exec.Command("open", target)
+	case "windows":
+		return exec.Command("rundll32", "url.dll,FileProtocolHandler", target)
+	default:
+		return exec.Command("xdg-open", target)
+	}
+}
+
+// COMMANDS
+
+// openExternally opens path with the system's default application.
+func openExternally(path string) tea.Cmd {
+	return func() tea.Msg {
+		return externalOpenMsg{startOpener(openerCommand(path))}
+	}
+}
+
+// openInBrowser opens a web page in the system's browser.
+func openInBrowser(url string) tea.Cmd {
+	return func() tea.Msg {
+		return browserOpenMsg{startOpener(openerCommand(url))}
+	}
+}
@@ -0,0 +1,64 @@
+package ui
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestIsBinaryContent(t *testing.T) {
+	cases := []struct {
+		name string
+		data []byte
+		want bool
+	}{
+		{name: "markdown", data: []byte("# Hello\n\nWorld ✨\n"), want: false},
+		{name: "empty", data: nil, want: false},
+		{name: "null_bytes", data: []byte("PK\x03\x04\x00\x00\x08\x00"), want: true},
+		{name: "invalid_utf8", data: []byte("\xff\xfe\xfd\xfc\xfb abc"), want: true},
+		{name: "long_text_cut_mid_rune", data: []byte(strings.Repeat("é", binarySniffLen)), want: false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := isBinaryContent(tc.data); got != tc.want {
+				t.Fatalf("expected %v, got %v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestLoadLocalMarkdown_Binary(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "image.md")
+	mustWriteFile(t, path, "\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR\x00\x00")
+
+	md := &markdown{localPath: path, Note: "image.md"}
+	msg := loadLocalMarkdown(md, "")()
+	if _, ok := msg.(fetchedMarkdownMsg); !ok {
+		t.Fatalf("expected fetchedMarkdownMsg, got %T", msg)
+	}
+	if !md.binary || md.Body != "" {
+		t.Fatalf("expected binary document without a body, got binary=%v body=%q", md.binary, md.Body)
+	}
+
+	m := newTestPager(t, Config{}, "image.md", 80)
+	m.currentDocument = *md
+	out, err := glamourRender(m, md.Body)
+	if err != nil {
+		t.Fatalf("glamourRender returned error: %v", err)
+	}
+	if out != binaryNotice(m.keys) || !strings.Contains(out, "Press o to open") {
+		t.Fatalf("expected binary notice, got %q", out)
+	}
+
+	// The notice tells the key that's actually bound.
+	m = newTestPager(t, Config{KeyBindings: map[string][]string{"open": {"ctrl+e"}}}, "image.md", 80)
+	m.currentDocument = *md
+	if out, _ := glamourRender(m, md.Body); !strings.Contains(out, "Press ctrl+e to open") {
+		t.Fatalf("expected the notice to name the open key, got %q", out)
+	}
+	m = newTestPager(t, Config{KeyBindings: map[string][]string{"open": {}}}, "image.md", 80)
+	m.currentDocument = *md
+	if out, _ := glamourRender(m, md.Body); strings.Contains(out, "Press") {
+		t.Fatalf("expected no key to be named when open isn't bound, got %q", out)
+	}
+}
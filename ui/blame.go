@@ -0,0 +1,147 @@
+package ui
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/log"
+)
+
+// blameLine is a single line of `git blame` output.
+type blameLine struct {
+	Commit  string
+	Author  string
+	Time    time.Time
+	Line    int
+	Content string
+}
+
+type blameMsg struct {
+	lines []blameLine
+	err   error
+}
+
+// parseBlamePorcelain parses the output of `git blame --porcelain`. Commit
+// details are only printed the first time a commit shows up, so we remember
+// them as we go.
+func parseBlamePorcelain(out []byte) ([]blameLine, error) {
+	type commitInfo struct {
+		author string
+		time   time.Time
+	}
+
+	var (
+		lines   []blameLine
+		commits = map[string]*commitInfo{}
+		current *blameLine
+		info    *commitInfo
+	)
+
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		if strings.HasPrefix(line, "\t") {
+			if current == nil {
+				return nil, errors.New("unexpected content line in blame output")
+			}
+			current.Content = line[1:]
+			current.Author = info.author
+			current.Time = info.time
+			lines = append(lines, *current)
+			current = nil
+			continue
+		}
+
+		if current == nil {
+			fields := strings.Fields(line)
+			if len(fields) < 3 {
+				return nil, fmt.Errorf("unexpected blame header: %q", line)
+			}
+			n, err := strconv.Atoi(fields[2])
+			if err != nil {
+				return nil, fmt.Errorf("unexpected blame header: %q", line)
+			}
+			current = &blameLine{Commit: fields[0], Line: n}
+			if info = commits[fields[0]]; info == nil {
+				info = &commitInfo{}
+				commits[fields[0]] = info
+			}
+			continue
+		}
+
+		key, value, _ := strings.Cut(line, " ")
+		switch key {
+		case "author":
+			info.author = value
+		case "author-time":
+			if ts, err := strconv.ParseInt(value, 10, 64); err == nil {
+				info.time = time.Unix(ts, 0)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading blame output: %w", err)
+	}
+	return lines, nil
+}
+
+func blameOverlayItems(lines []blameLine) []overlayItem {
+	const authorWidth = 16
+
+	items := make([]overlayItem, 0, len(lines))
+	for _, l := range lines {
+		commit := l.Commit
+		if len(commit) > 8 {
+			commit = commit[:8]
+		}
+		author := []rune(l.Author)
+		if len(author) > authorWidth {
+			author = append(author[:authorWidth-1], []rune(ellipsis)...)
+		}
+		items = append(items, overlayItem{
+			Label: fmt.Sprintf("%s %-*s %s %4d │ %s",
+				commit, authorWidth, string(author), l.Time.Format("2006-01-02"), l.Line, l.Content),
+		})
+	}
+	return items
+}
+
+// COMMANDS
+
+func gitBlame(path string) tea.Cmd {
+	return func() tea.Msg {
+		cmd := exec.Command("git", "-C", filepath.Dir(path), "blame", "--porcelain", "--", filepath.Base(path)) //nolint:gosec
+		out, err := cmd.Output()
+		if err != nil {
+			log.Debug("error running git blame", "file", path, "error", err)
+			return blameMsg{err: gitError(err)}
+		}
+		lines, err := parseBlamePorcelain(out)
+		return blameMsg{lines: lines, err: err}
+	}
+}
+
+// gitError returns what git had to say about why it failed, like "not a git
+// repository", or the error itself if it didn't get to run.
+func gitError(err error) error {
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) {
+		return err
+	}
+	msg, _, _ := strings.Cut(strings.TrimSpace(string(exitErr.Stderr)), "\n")
+	msg = strings.TrimPrefix(msg, "fatal: ")
+	if msg == "" {
+		return err
+	}
+	return errors.New(msg)
+}
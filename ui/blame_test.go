@@ -0,0 +1,91 @@
+package ui
+
+import (
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseBlamePorcelain(t *testing.T) {
+	// Commit details are only given the first time a commit shows up.
+	const out = "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa 1 1 2\n" +
+		"author Ada\n" +
+		"author-time 1700000000\n" +
+		"summary First\n" +
+		"filename notes.md\n" +
+		"\t# Notes\n" +
+		"bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb 1 2 1\n" +
+		"author Grace\n" +
+		"author-time 1710000000\n" +
+		"filename notes.md\n" +
+		"\tChanged\n" +
+		"aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa 2 3\n" +
+		"\tText\n"
+
+	lines, err := parseBlamePorcelain([]byte(out))
+	if err != nil {
+		t.Fatalf("parseBlamePorcelain returned error: %v", err)
+	}
+	want := []blameLine{
+		{Commit: strings.Repeat("a", 40), Author: "Ada", Time: time.Unix(1700000000, 0), Line: 1, Content: "# Notes"},
+		{Commit: strings.Repeat("b", 40), Author: "Grace", Time: time.Unix(1710000000, 0), Line: 2, Content: "Changed"},
+		{Commit: strings.Repeat("a", 40), Author: "Ada", Time: time.Unix(1700000000, 0), Line: 3, Content: "Text"},
+	}
+	if len(lines) != len(want) {
+		t.Fatalf("expected %d lines, got %+v", len(want), lines)
+	}
+	for i := range want {
+		if lines[i] != want[i] {
+			t.Errorf("line %d: expected %+v, got %+v", i, want[i], lines[i])
+		}
+	}
+
+	if _, err := parseBlamePorcelain([]byte("\tno header\n")); err == nil {
+		t.Error("expected content without a header to be an error")
+	}
+	if _, err := parseBlamePorcelain([]byte("abc 1\n")); err == nil {
+		t.Error("expected a short header to be an error")
+	}
+}
+
+func TestGitBlame_Error(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	dir := t.TempDir()
+	path := filepath.Join(dir, "notes.md")
+	mustWriteFile(t, path, "# Notes\n")
+
+	// Outside of a repository, and with the file untracked in one, git's
+	// own explanation is passed on.
+	msg := gitBlame(path)().(blameMsg)
+	if msg.err == nil || !strings.Contains(msg.err.Error(), "not a git repository") {
+		t.Fatalf("expected git's error outside of a repository, got %v", msg.err)
+	}
+	if err := exec.Command("git", "-C", dir, "init", "-q").Run(); err != nil {
+		t.Skipf("git init failed: %v", err)
+	}
+	msg = gitBlame(path)().(blameMsg)
+	if msg.err == nil || strings.Contains(msg.err.Error(), "not a git repository") || strings.HasPrefix(msg.err.Error(), "fatal:") {
+		t.Fatalf("expected git's error for an untracked file, got %v", msg.err)
+	}
+}
+
+func TestBlameTurnedOff(t *testing.T) {
+	for _, enabled := range []bool{false, true} {
+		m := newTestPager(t, Config{GitBlame: enabled}, "notes.md", 80)
+		if got := strings.Contains(strings.Join(m.helpRows(), "\n"), "git blame"); got != enabled {
+			t.Errorf("gitBlame %v: expected the help entry to be shown only when enabled, got %v", enabled, got)
+		}
+	}
+
+	m := newTestPager(t, Config{}, "notes.md", 80)
+	m.currentDocument.localPath = "notes.md"
+	m, _ = m.update(contentRenderedMsg("# Notes\n"))
+	m = typeKeys(t, m, "B")
+	if !strings.HasPrefix(m.statusMessage, "Git blame is turned off") {
+		t.Fatalf("expected B to say blame is off, got %q", m.statusMessage)
+	}
+}
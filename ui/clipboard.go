@@ -0,0 +1,92 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/atotto/clipboard"
+	"github.com/muesli/termenv"
+	"golang.org/x/term"
+)
+
+// What to do when content can't be copied to the clipboard.
+const (
+	clipboardFallbackNone = "none"
+	clipboardFallbackFile = "file"
+)
+
+// How to treat trailing newlines in copied content.
+const (
+	trailingNewlineVerbatim = "verbatim"
+	trailingNewlineSingle   = "single"
+	trailingNewlineStrip    = "strip"
+)
+
+var (
+	errClipboardUnavailable = errors.New("clipboard unavailable")
+	errOSC52Unsupported     = errors.New("terminal does not support OSC 52")
+	errOSC52Skipped         = errors.New("content too large for OSC 52")
+)
+
+// Clipboard backends. These are variables so they can be stubbed out in
+// tests.
+var (
+	osc52Copy  = copyOSC52
+	nativeCopy = clipboard.WriteAll
+)
+
+func copyOSC52(s string) error {
+	if !term.IsTerminal(int(os.Stdout.Fd())) {
+		return errOSC52Unsupported
+	}
+	switch os.Getenv("TERM") {
+	case "", "dumb", "linux":
+		return errOSC52Unsupported
+	}
+	termenv.Copy(s)
+	return nil
+}
+
+// copyToClipboard copies s using both OSC 52, unless told not to, and the
+// native system clipboard. It only fails if neither of them worked.
+func copyToClipboard(s string, useOSC52 bool) error {
+	osc52Err := errOSC52Skipped
+	if useOSC52 {
+		osc52Err = osc52Copy(s)
+	}
+	nativeErr := nativeCopy(s)
+	if osc52Err != nil && nativeErr != nil {
+		return fmt.Errorf("%w: %w", errClipboardUnavailable, errors.Join(osc52Err, nativeErr))
+	}
+	return nil
+}
+
+// trimTrailingNewlines makes s end in exactly one newline, or none at all,
+// depending on mode. Any other mode leaves s untouched.
+func trimTrailingNewlines(s, mode string) string {
+	switch mode {
+	case trailingNewlineSingle:
+		return strings.TrimRight(s, "\r\n") + "\n"
+	case trailingNewlineStrip:
+		return strings.TrimRight(s, "\r\n")
+	default:
+		return s
+	}
+}
+
+// writeClipboardFallback writes s to a temporary file and returns its path.
+// It's used in lieu of the clipboard when the clipboard isn't available.
+func writeClipboardFallback(s string) (string, error) {
+	f, err := os.CreateTemp("", "glow-copy-*.md")
+	if err != nil {
+		return "", fmt.Errorf("unable to create temp file: %w", err)
+	}
+	defer f.Close() //nolint:errcheck
+
+	if _, err := f.WriteString(s); err != nil {
+		return "", fmt.Errorf("unable to write temp file: %w", err)
+	}
+	return f.Name(), nil
+}
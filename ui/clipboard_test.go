@@ -0,0 +1,225 @@
+package ui
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func stubClipboard(t *testing.T, osc52, native func(string) error) {
+	t.Helper()
+	origOSC52, origNative := osc52Copy, nativeCopy
+	osc52Copy, nativeCopy = osc52, native
+	t.Cleanup(func() {
+		osc52Copy, nativeCopy = origOSC52, origNative
+	})
+}
+
+func failingClipboard(string) error { return errors.New("no clipboard") }
+
+func TestCopyToClipboard_Unavailable(t *testing.T) {
+	stubClipboard(t, failingClipboard, failingClipboard)
+
+	err := copyToClipboard("hello", true)
+	if !errors.Is(err, errClipboardUnavailable) {
+		t.Fatalf("expected errClipboardUnavailable, got %v", err)
+	}
+}
+
+func TestCopyToClipboard_OneBackendSuffices(t *testing.T) {
+	ok := func(string) error { return nil }
+
+	stubClipboard(t, failingClipboard, ok)
+	if err := copyToClipboard("hello", true); err != nil {
+		t.Fatalf("expected native clipboard to suffice, got %v", err)
+	}
+
+	stubClipboard(t, ok, failingClipboard)
+	if err := copyToClipboard("hello", true); err != nil {
+		t.Fatalf("expected OSC 52 to suffice, got %v", err)
+	}
+}
+
+func TestPagerCopyContents_Fallback(t *testing.T) {
+	stubClipboard(t, failingClipboard, failingClipboard)
+
+	cases := []struct {
+		name       string
+		fallback   string
+		wantPrefix string
+	}{
+		{name: "none", fallback: clipboardFallbackNone, wantPrefix: "Clipboard unavailable"},
+		{name: "file", fallback: clipboardFallbackFile, wantPrefix: "Clipboard unavailable, wrote "},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv("TMPDIR", t.TempDir())
+
+			common := &commonModel{cfg: Config{ClipboardFallback: tc.fallback}}
+			m := pagerModel{common: common}
+			_ = m.copyContents("# Hello\n", "Copied contents")
+
+			if !strings.HasPrefix(m.statusMessage, tc.wantPrefix) {
+				t.Fatalf("expected status message %q, got %q", tc.wantPrefix, m.statusMessage)
+			}
+			if tc.fallback != clipboardFallbackFile {
+				return
+			}
+
+			path := strings.TrimPrefix(m.statusMessage, tc.wantPrefix)
+			b, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("reading fallback file: %v", err)
+			}
+			if string(b) != "# Hello\n" {
+				t.Fatalf("unexpected fallback contents: %q", b)
+			}
+		})
+	}
+}
+
+func TestTrimTrailingNewlines(t *testing.T) {
+	cases := []struct {
+		mode string
+		in   string
+		want string
+	}{
+		{mode: trailingNewlineVerbatim, in: "# Hi\n\n\n", want: "# Hi\n\n\n"},
+		{mode: trailingNewlineVerbatim, in: "# Hi", want: "# Hi"},
+		{mode: trailingNewlineSingle, in: "# Hi\n\n\n", want: "# Hi\n"},
+		{mode: trailingNewlineSingle, in: "# Hi", want: "# Hi\n"},
+		{mode: trailingNewlineSingle, in: "# Hi\r\n", want: "# Hi\n"},
+		{mode: trailingNewlineStrip, in: "# Hi\n\n", want: "# Hi"},
+		{mode: trailingNewlineStrip, in: "# Hi", want: "# Hi"},
+	}
+	for _, tc := range cases {
+		if got := trimTrailingNewlines(tc.in, tc.mode); got != tc.want {
+			t.Errorf("%s(%q): expected %q, got %q", tc.mode, tc.in, tc.want, got)
+		}
+	}
+}
+
+func TestPagerCopyContents_OSC52Limit(t *testing.T) {
+	var osc52Calls int
+	countingOSC52 := func(string) error {
+		osc52Calls++
+		return nil
+	}
+	ok := func(string) error { return nil }
+
+	cases := []struct {
+		name       string
+		native     func(string) error
+		content    string
+		wantOSC52  int
+		wantStatus string
+	}{
+		{name: "at_limit", native: ok, content: strings.Repeat("a", 10), wantOSC52: 1, wantStatus: "Copied contents"},
+		{name: "over_limit", native: ok, content: strings.Repeat("a", 11), wantOSC52: 0, wantStatus: "Copied contents (too large for OSC 52, native clipboard only)"},
+		{name: "over_limit_without_native", native: failingClipboard, content: strings.Repeat("a", 11), wantOSC52: 0, wantStatus: "Clipboard unavailable"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			osc52Calls = 0
+			stubClipboard(t, countingOSC52, tc.native)
+
+			m := pagerModel{common: &commonModel{cfg: Config{OSC52MaxBytes: 10}}}
+			_ = m.copyContents(tc.content, "Copied contents")
+
+			if osc52Calls != tc.wantOSC52 {
+				t.Fatalf("expected %d OSC 52 copies, got %d", tc.wantOSC52, osc52Calls)
+			}
+			if m.statusMessage != tc.wantStatus {
+				t.Fatalf("expected status %q, got %q", tc.wantStatus, m.statusMessage)
+			}
+		})
+	}
+}
+
+func TestPagerCopyDocumentPath(t *testing.T) {
+	var copied string
+	stubClipboard(t, func(s string) error {
+		copied = s
+		return nil
+	}, failingClipboard)
+
+	dir := t.TempDir()
+	t.Chdir(dir)
+
+	m := newTestPager(t, Config{}, "notes/todo.md", 80)
+	m.currentDocument.localPath = filepath.Join("notes", "todo.md")
+	m = typeKeys(t, m, "Y")
+
+	want := filepath.Join(dir, "notes", "todo.md")
+	if copied != want {
+		t.Fatalf("expected %q on the clipboard, got %q", want, copied)
+	}
+	if m.statusMessage != "Copied "+want {
+		t.Fatalf("unexpected status %q", m.statusMessage)
+	}
+}
+
+func TestPagerCopyLinkPath(t *testing.T) {
+	var copied string
+	stubClipboard(t, func(s string) error {
+		copied = s
+		return nil
+	}, failingClipboard)
+
+	root := absEvalSymlinks(t, t.TempDir())
+	current := filepath.Join(root, "index.md")
+	body := "See [the guide](docs/guide.md).\n"
+	mustWriteFile(t, current, body)
+	mustWriteFile(t, filepath.Join(root, "docs", "guide.md"), "")
+
+	m := newTestPager(t, Config{}, "index.md", 80)
+	m.common.cwd = root
+	m.currentDocument = markdown{localPath: current, Note: "index.md", Body: body}
+	m.extractLinks()
+
+	m = typeKeys(t, m, "y")
+	if m.statusMessage != "No link focused" || copied != "" {
+		t.Fatalf("expected nothing to be copied, got %q (%q)", copied, m.statusMessage)
+	}
+
+	m = typeKeys(t, m, keyTab, "y")
+	if want := filepath.Join(root, "docs", "guide.md"); copied != want {
+		t.Fatalf("expected %q on the clipboard, got %q", want, copied)
+	}
+	if m.statusMessage != "Copied link path" {
+		t.Fatalf("unexpected status %q", m.statusMessage)
+	}
+}
+
+func TestPagerCopyRendered(t *testing.T) {
+	var copied string
+	stubClipboard(t, func(s string) error {
+		copied = s
+		return nil
+	}, failingClipboard)
+
+	body := "# Title\n\nSome **bold** text.\n"
+	m := newTestPager(t, Config{}, "README.md", 80)
+	m.currentDocument.Body = body
+	out, err := glamourRender(m, body)
+	if err != nil {
+		t.Fatalf("glamourRender returned error: %v", err)
+	}
+	m, _ = m.update(contentRenderedMsg(out))
+
+	m = typeKeys(t, m, "X")
+	if copied != m.rendered || !strings.Contains(copied, "\x1b[") {
+		t.Fatalf("expected the rendered output with its colors on the clipboard, got %q", copied)
+	}
+	if m.statusMessage != "Copied rendered output" {
+		t.Fatalf("unexpected status %q", m.statusMessage)
+	}
+
+	m = typeKeys(t, m, "c")
+	if copied != body || m.statusMessage != "Copied contents" {
+		t.Fatalf("expected c to still copy the source, got %q (%q)", copied, m.statusMessage)
+	}
+}
@@ -1,5 +1,7 @@
 package ui
 
+import "time"
+
 // Config contains TUI-specific configuration.
 type Config struct {
 	ShowAllFiles     bool
@@ -11,9 +13,194 @@ type Config struct {
 	EnableMouse      bool
 	PreserveNewLines bool
 
+	// What to do when copying fails because no clipboard is available:
+	// "none" or "file".
+	ClipboardFallback string
+
+	// Briefly highlight the target heading after jumping to it.
+	FlashHeadingJumps bool
+
+	// How to resolve anchors shared by several headings: "suffix" or
+	// "first".
+	DuplicateHeadingSlugs string
+
+	// Line that separates slides in presentation mode.
+	SlideSeparator string
+
+	// Column at which to wrap code files, regardless of the terminal width.
+	// Zero disables wrapping.
+	CodeWrapWidth uint
+
+	// Allow showing git blame for the current document.
+	GitBlame bool
+
+	// Extra emphasis for inline code spans: any of "bold", "underline",
+	// "reverse" and "background", separated by commas.
+	InlineCodeEmphasis string
+
+	// Chroma theme to highlight code with, like "monokai", instead of the
+	// style's own colors.
+	ChromaTheme string
+
+	// What was wrong with the configuration, like an unknown chroma theme,
+	// to be shown in the status bar once there's a document.
+	Warnings []string
+
+	// Show the language of fenced code blocks above them.
+	CodeBlockLabels bool
+
+	// Maximum width of the help overlay's content. Zero means the full
+	// terminal width.
+	HelpMaxWidth uint
+
+	// Keep the top line of the document in place when toggling help, even
+	// if that leaves blank space below the end of the document, rather than
+	// scrolling up to fill the screen.
+	PinHelpToggle bool
+
+	// Format used when copying all links: "markdown" or "plain".
+	LinkListFormat string
+
+	// Indicator shown in the gutter next to headings in markdown documents,
+	// marking them as linkable anchors. Empty disables it.
+	HeadingGutterIndicator string
+
+	// How to treat trailing newlines when copying the document: "verbatim",
+	// "single" or "strip".
+	CopyTrailingNewline string
+
+	// Colors for the focused link. When neither is set the link is shown in
+	// reverse video.
+	FocusedLinkForeground string
+	FocusedLinkBackground string
+
+	// Encoding of local documents, like "latin1" or "utf-16le". "auto"
+	// detects UTF-16 and UTF-8 by their byte order mark.
+	Encoding string
+
+	// Append a count to status messages repeated while still shown, like
+	// "Copied contents (x3)".
+	CountRepeatedStatusMessages bool
+
+	// Briefly say so in the status bar when the document is reloaded because
+	// it changed on disk.
+	ReloadIndicator bool
+
+	// How many levels of links the link graph follows.
+	LinkGraphDepth uint
+
+	// Largest content, in bytes, copied using OSC 52. Larger content only
+	// goes to the native clipboard. Zero means no limit.
+	OSC52MaxBytes uint
+
+	// Leave out the line number gutter when copying the view as text.
+	CopyStripGutter bool
+
+	// Size in bytes past which following a link to a file asks for
+	// confirmation or shows it unrendered, depending on LargeFileAction
+	// ("confirm" or "raw"). Zero disables the check.
+	LargeFileThreshold uint
+	LargeFileAction    string
+
+	// Allow browsing the terms of definition lists and abbreviations, and
+	// showing what they mean.
+	DefinitionTooltips bool
+
+	// Columns left blank on either side of the document, on top of the
+	// style's own margins.
+	ContentMargin uint
+
+	// Lines the half page keys and the mouse wheel scroll by. Zero scrolls
+	// by half a page and three lines respectively.
+	ScrollStep uint
+
+	// Animate jumps to the top, the bottom and headings over a few frames,
+	// except in high performance mode.
+	SmoothScroll bool
+
+	// Collapse runs of blank lines in documents, outside of code blocks.
+	Compact bool
+
+	// Scroll to the bottom when a document changes on disk, like tail -f.
+	Follow bool
+
+	// Stop watching the document for changes after this long without key
+	// presses, until the next one. Zero means never.
+	WatchIdleTimeout time.Duration
+
+	// How long the document has to be left alone after changing before
+	// it's reloaded, so that a burst of writes only reloads it once.
+	WatchDebounce time.Duration
+
+	// Number of colors to render with: truecolor, 256, 16 or none. Detected
+	// from the terminal if empty.
+	ColorDepth string
+
+	// Make links to local files that aren't markdown, like source code,
+	// followable by opening them in the editor.
+	FollowNonMarkdownLinks bool
+
+	// Replace <!-- include: file.md --> lines with the contents of the file,
+	// and whether to reload the document when included files change too.
+	Includes      bool
+	WatchIncludes bool
+
+	// Replace GitHub emoji shortcodes like :rocket: with the emoji.
+	RenderEmoji bool
+
+	// Width of the centered reading column in zen mode, and whether to dim
+	// everything but the line in the middle of the screen there.
+	ZenWidth   uint
+	ZenDimming bool
+
+	// JSON style file to render with instead of GlamourStyle, which is
+	// used if the file can't be loaded. Reloaded with S.
+	GlamourStylePath string
+
+	// Show the front matter of markdown documents above them instead of
+	// hiding it. Toggled with -.
+	ShowFrontMatter bool
+
+	// Number lines relative to the top of the view, like vim's
+	// relativenumber. Toggled with #.
+	RelativeLineNumbers bool
+
+	// Show the word count and reading time of markdown documents in the
+	// status bar. Toggled with t.
+	ShowReadingTime bool
+
+	// Leave code blocks out of the word count.
+	ReadingTimeSkipCode bool
+
+	// Show the line at the top of the view and the number of lines in the
+	// status bar. Toggled with ctrl+g.
+	ShowLinePosition bool
+
+	// File the scroll position of documents is kept in, so that they're
+	// reopened where they were left. Empty to not remember positions.
+	PositionsFile string
+
+	// File bookmarks are kept in. Empty to only keep them for the session.
+	BookmarksFile string
+
+	// File the recently viewed documents are kept in. Empty to only
+	// remember them for the session.
+	RecentFile string
+
+	// Preset of pager keys, "default" or "vim", where gg goes to the top.
+	KeyMode string
+
+	// Keys of pager actions, by the name of the action, in place of the
+	// keys of the preset.
+	KeyBindings map[string][]string
+
 	// Working directory or file path
 	Path string
 
+	// Reads the configuration again, for reloading it while running. Nil if
+	// that's not possible.
+	ReloadConfig func() (Config, error)
+
 	// For debugging the UI
 	HighPerformancePager bool `env:"GLOW_HIGH_PERFORMANCE_PAGER" envDefault:"true"`
 	GlamourEnabled       bool `env:"GLOW_ENABLE_GLAMOUR"         envDefault:"true"`
@@ -0,0 +1,85 @@
+package ui
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/htmlindex"
+	"golang.org/x/text/encoding/unicode"
+)
+
+// Special document encodings. Anything else is looked up by name, like
+// "latin1" or "utf-16le".
+const (
+	encodingUTF8 = "utf-8"
+	encodingAuto = "auto"
+)
+
+var (
+	errUnknownEncoding = errors.New("unknown encoding")
+	errInvalidEncoding = errors.New("it isn't valid in that encoding")
+)
+
+// decodeDocument converts data from the named encoding to UTF-8. Decoders
+// replace what they can't make sense of rather than failing, so data that
+// comes out with replacement characters that don't encode back to it, or
+// with a byte order mark the wrong way round, is taken to be in some other
+// encoding.
+func decodeDocument(data []byte, name string) ([]byte, error) {
+	enc, err := documentEncoding(data, name)
+	if err != nil {
+		return nil, err
+	}
+	if enc == nil {
+		return data, nil
+	}
+	out, err := enc.NewDecoder().Bytes(data)
+	if err == nil && (bytes.HasPrefix(out, []byte("\uFFFE")) || !roundTrips(enc, data, out)) {
+		err = errInvalidEncoding
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to decode document as %s: %w", name, err)
+	}
+	return out, nil
+}
+
+// documentEncoding returns the encoding to decode data with, or nil if it's
+// UTF-8 already. With "auto" the encoding is picked by the byte order mark,
+// assuming UTF-8 if there's none.
+func documentEncoding(data []byte, name string) (encoding.Encoding, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "", encodingUTF8, "utf8":
+		return nil, nil
+	case encodingAuto:
+		switch {
+		case bytes.HasPrefix(data, []byte{0xEF, 0xBB, 0xBF}):
+			return unicode.UTF8BOM, nil
+		case bytes.HasPrefix(data, []byte{0xFF, 0xFE}):
+			return unicode.UTF16(unicode.LittleEndian, unicode.ExpectBOM), nil
+		case bytes.HasPrefix(data, []byte{0xFE, 0xFF}):
+			return unicode.UTF16(unicode.BigEndian, unicode.ExpectBOM), nil
+		}
+		return nil, nil
+	}
+
+	enc, err := htmlindex.Get(name)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %q", errUnknownEncoding, name)
+	}
+	return enc, nil
+}
+
+// roundTrips reports whether data decoded to out without replacing anything,
+// that is, either there are no replacement characters or they were in data
+// to begin with and encode back to it.
+func roundTrips(enc encoding.Encoding, data, out []byte) bool {
+	if !bytes.ContainsRune(out, utf8.RuneError) {
+		return true
+	}
+	in, err := enc.NewEncoder().Bytes(out)
+	return err == nil && bytes.Equal(in, data)
+}
@@ -0,0 +1,77 @@
+package ui
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+func TestDecodeDocument(t *testing.T) {
+	cases := []struct {
+		name     string
+		encoding string
+		data     []byte
+		want     string
+	}{
+		{name: "utf8", encoding: encodingUTF8, data: []byte("# Café\n"), want: "# Café\n"},
+		{name: "latin1", encoding: "latin1", data: []byte("# Caf\xe9\n"), want: "# Café\n"},
+		{name: "utf16le_named", encoding: "utf-16le", data: []byte("#\x00 \x00\xe9\x00\n\x00"), want: "# é\n"},
+		{name: "utf16le_bom", encoding: encodingAuto, data: []byte("\xff\xfe#\x00 \x00\xe9\x00\n\x00"), want: "# é\n"},
+		{name: "utf16be_bom", encoding: encodingAuto, data: []byte("\xfe\xff\x00#\x00 \x00\xe9\x00\n"), want: "# é\n"},
+		{name: "utf8_bom", encoding: encodingAuto, data: []byte("\xef\xbb\xbf# Café\n"), want: "# Café\n"},
+		{name: "auto_without_bom", encoding: encodingAuto, data: []byte("# Café\n"), want: "# Café\n"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := decodeDocument(tc.data, tc.encoding)
+			if err != nil {
+				t.Fatalf("decodeDocument returned error: %v", err)
+			}
+			if string(got) != tc.want {
+				t.Fatalf("expected %q, got %q", tc.want, got)
+			}
+		})
+	}
+
+	if _, err := decodeDocument([]byte("x"), "klingon"); !errors.Is(err, errUnknownEncoding) {
+		t.Fatalf("expected errUnknownEncoding, got %v", err)
+	}
+
+	// Data that doesn't make sense in the encoding is reported rather than
+	// shown garbled.
+	for _, tc := range []struct {
+		encoding string
+		data     []byte
+	}{
+		{"latin1", []byte("# Caf\x81\n")},
+		{"utf-16le", []byte("\xfe\xff\x00#\x00\n")},
+		{encodingAuto, []byte("\xff\xfe#\x00\n")},
+		{"shift_jis", []byte("# \x82\xff\n")},
+	} {
+		if _, err := decodeDocument(tc.data, tc.encoding); !errors.Is(err, errInvalidEncoding) {
+			t.Errorf("%s %q: expected errInvalidEncoding, got %v", tc.encoding, tc.data, err)
+		}
+	}
+	if got, err := decodeDocument([]byte("\xff\xfe\xfd\xff\n\x00"), encodingAuto); err != nil || string(got) != "\uFFFD\n" {
+		t.Fatalf("expected a replacement character in the document to be kept, got %q and %v", got, err)
+	}
+}
+
+func TestLoadLocalMarkdown_UTF16(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "notes.md")
+	mustWriteFile(t, path, "\xff\xfe#\x00 \x00H\x00i\x00\n\x00")
+
+	md := &markdown{localPath: path, Note: "notes.md"}
+	if msg, ok := loadLocalMarkdown(md, encodingAuto)().(fetchedMarkdownMsg); !ok {
+		t.Fatalf("expected fetchedMarkdownMsg, got %T", msg)
+	}
+	if md.binary || md.Body != "# Hi\n" {
+		t.Fatalf("expected decoded document, got binary=%v body=%q", md.binary, md.Body)
+	}
+
+	// A document that isn't in the configured encoding is said to be so.
+	msg, ok := loadLocalMarkdown(md, "utf-16be")().(errMsg)
+	if !ok || !errors.Is(msg.err, errInvalidEncoding) {
+		t.Fatalf("expected an error for the wrong encoding, got %v", msg)
+	}
+}
@@ -0,0 +1,96 @@
+package ui
+
+import (
+	"bytes"
+	"os/exec"
+	"path/filepath"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/log"
+)
+
+// gitFileStatus is the state of a file according to git.
+type gitFileStatus int
+
+const (
+	// Not in a git repository, ignored, or not known yet.
+	gitStatusUnknown gitFileStatus = iota
+	gitStatusClean
+	gitStatusModified
+	gitStatusUntracked
+)
+
+// Colors of the git state indicator in the status bar.
+var (
+	gitCleanFg     = green
+	gitModifiedFg  = lipgloss.AdaptiveColor{Light: "#E0A100", Dark: "#F5C542"}
+	gitUntrackedFg = red
+)
+
+type gitStatusMsg struct {
+	path   string
+	status gitFileStatus
+}
+
+// parseGitStatus returns the state of a single file from the output of
+// `git status --porcelain --ignored` for it.
+func parseGitStatus(out []byte) gitFileStatus {
+	line, _, _ := bytes.Cut(out, []byte("\n"))
+	switch {
+	case len(bytes.TrimSpace(line)) == 0:
+		return gitStatusClean
+	case bytes.HasPrefix(line, []byte("??")):
+		return gitStatusUntracked
+	case bytes.HasPrefix(line, []byte("!!")):
+		return gitStatusUnknown
+	default:
+		return gitStatusModified
+	}
+}
+
+// gitStatusView returns the indicator of the git state of the document for
+// the status bar, or "" if there's nothing to show. It takes the background
+// of the bar, which is different while a status message is shown.
+func (m pagerModel) gitStatusView(bg lipgloss.TerminalColor) string {
+	if m.currentDocument.localPath == "" || m.gitStatusPath != m.currentDocument.localPath {
+		return ""
+	}
+	var fg lipgloss.TerminalColor
+	switch m.gitStatus {
+	case gitStatusClean:
+		fg = gitCleanFg
+	case gitStatusModified:
+		fg = gitModifiedFg
+	case gitStatusUntracked:
+		fg = gitUntrackedFg
+	default:
+		return ""
+	}
+	return lipgloss.NewStyle().Foreground(fg).Background(bg).Render(" ●")
+}
+
+// checkGitStatus finds out the git state of the document again, if it's a
+// local file.
+func (m pagerModel) checkGitStatus() tea.Cmd {
+	if m.currentDocument.localPath == "" {
+		return nil
+	}
+	return gitStatus(m.currentDocument.localPath)
+}
+
+// COMMANDS
+
+// gitStatus finds out whether a file is modified, untracked or clean. Files
+// outside of git repositories are left unknown, without complaint.
+func gitStatus(path string) tea.Cmd {
+	return func() tea.Msg {
+		cmd := exec.Command("git", "-C", filepath.Dir(path), "status", "--porcelain", "--ignored", "--", filepath.Base(path)) //nolint:gosec
+		out, err := cmd.Output()
+		if err != nil {
+			log.Debug("error running git status", "file", path, "error", err)
+			return gitStatusMsg{path: path, status: gitStatusUnknown}
+		}
+		return gitStatusMsg{path: path, status: parseGitStatus(out)}
+	}
+}
@@ -0,0 +1,158 @@
+package ui
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/glamour"
+	"github.com/charmbracelet/glamour/ansi"
+	"github.com/charmbracelet/glamour/styles"
+	"github.com/charmbracelet/glow/v2/utils"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/log"
+	"github.com/muesli/termenv"
+)
+
+// Ways to make inline code spans stand out. They can be combined with
+// commas, e.g. "bold,background".
+const (
+	inlineCodeBold       = "bold"
+	inlineCodeUnderline  = "underline"
+	inlineCodeReverse    = "reverse"
+	inlineCodeBackground = "background"
+)
+
+// Color depths that can be forced instead of detecting what the terminal
+// supports.
+const (
+	colorDepthTrueColor = "truecolor"
+	colorDepth256       = "256"
+	colorDepth16        = "16"
+	colorDepthNone      = "none"
+)
+
+// colorProfile returns the color profile to render with: the configured
+// color depth, or what the terminal supports.
+func colorProfile(depth string) termenv.Profile {
+	switch depth {
+	case colorDepthTrueColor:
+		return termenv.TrueColor
+	case colorDepth256:
+		return termenv.ANSI256
+	case colorDepth16:
+		return termenv.ANSI
+	case colorDepthNone:
+		return termenv.Ascii
+	}
+	return lipgloss.ColorProfile()
+}
+
+// fallbackGlamourStyle is used when the configured style can't be loaded.
+const fallbackGlamourStyle = styles.AutoStyle
+
+// styleError is returned when a document can't be rendered because the
+// configured style can't be loaded.
+type styleError struct {
+	style string
+	err   error
+}
+
+func (e *styleError) Error() string {
+	return "error loading style " + e.style + ": " + e.err.Error()
+}
+
+func (e *styleError) Unwrap() error { return e.err }
+
+// styleFallbackMsg reports that a document was rendered with the fallback
+// style because the configured one couldn't be loaded.
+type styleFallbackMsg struct {
+	style string
+}
+
+// styleName returns the style to render with: the style file if one is
+// configured, or else the named style.
+func styleName(cfg Config) string {
+	if cfg.GlamourStylePath != "" {
+		return cfg.GlamourStylePath
+	}
+	return cfg.GlamourStyle
+}
+
+// glamourStyle returns the glamour style for rendering a document, applying
+// any tweaks from the config on top of the configured style.
+func glamourStyle(cfg Config, isCode bool) glamour.TermRendererOption {
+	style := styleName(cfg)
+	if cfg.ChromaTheme == "" && (isCode || cfg.InlineCodeEmphasis == "") {
+		return utils.GlamourStyle(style, isCode)
+	}
+
+	sc, err := utils.GlamourStyleConfig(style)
+	if err != nil {
+		log.Debug("unable to load style config", "style", style, "error", err)
+		return utils.GlamourStyle(style, isCode)
+	}
+	if isCode {
+		// Source files are a single code block, which isn't indented.
+		var margin uint
+		sc.CodeBlock.Margin = &margin
+	} else {
+		emphasizeInlineCode(&sc, cfg.InlineCodeEmphasis, isLightStyle(style))
+	}
+	if cfg.ChromaTheme != "" {
+		sc.CodeBlock.Theme = cfg.ChromaTheme
+		// Colors of the style's own take precedence over a theme.
+		sc.CodeBlock.Chroma = nil
+	}
+	return glamour.WithStyles(sc)
+}
+
+// isLightStyle reports whether a style is meant for light backgrounds, like
+// the auto style is on a terminal with a light background.
+func isLightStyle(style string) bool {
+	switch style {
+	case styles.LightStyle:
+		return true
+	case styles.AutoStyle:
+		return !lipgloss.HasDarkBackground()
+	}
+	return false
+}
+
+// reloadStyle renders the document again to pick up changes to the style
+// file.
+func (m *pagerModel) reloadStyle() tea.Cmd {
+	if m.common.cfg.GlamourStylePath == "" {
+		return m.showStatusMessage(pagerStatusMessage{"No style file configured", false})
+	}
+	a := m.currentScrollAnchor()
+	m.pendingAnchor = &a
+
+	// A broken style file is reported by the fallback once rendered.
+	return tea.Batch(
+		m.showStatusMessage(pagerStatusMessage{"Reloaded style", false}),
+		renderWithGlamour(*m, m.currentDocument.Body),
+	)
+}
+
+// emphasizeInlineCode makes inline code spans more prominent. Only colors
+// and attributes are changed so the printable text stays the same, which
+// keeps link label matching intact.
+func emphasizeInlineCode(sc *ansi.StyleConfig, emphasis string, light bool) {
+	enabled := true
+	for _, e := range strings.Split(emphasis, ",") {
+		switch strings.TrimSpace(e) {
+		case inlineCodeBold:
+			sc.Code.Bold = &enabled
+		case inlineCodeUnderline:
+			sc.Code.Underline = &enabled
+		case inlineCodeReverse:
+			sc.Code.Inverse = &enabled
+		case inlineCodeBackground:
+			bg := "238"
+			if light {
+				bg = "253"
+			}
+			sc.Code.BackgroundColor = &bg
+		}
+	}
+}
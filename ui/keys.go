@@ -1,5 +1,15 @@
 package ui
 
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/viewport"
+)
+
 const (
 	keyTab       = "tab"
 	keyShiftTab  = "shift+tab"
@@ -7,3 +17,480 @@ const (
 	keyBackspace = "backspace"
 	keyEsc       = "esc"
 )
+
+// keyAction is something the pager does when a key is pressed. Its name is
+// how it's bound to other keys in the configuration.
+type keyAction string
+
+const (
+	actionLineUp           keyAction = "lineUp"
+	actionLineDown         keyAction = "lineDown"
+	actionPageUp           keyAction = "pageUp"
+	actionPageDown         keyAction = "pageDown"
+	actionHalfPageUp       keyAction = "halfPageUp"
+	actionHalfPageDown     keyAction = "halfPageDown"
+	actionScrollLeft       keyAction = "scrollLeft"
+	actionScrollRight      keyAction = "scrollRight"
+	actionTop              keyAction = "top"
+	actionCenter           keyAction = "center"
+	actionBottom           keyAction = "bottom"
+	actionNextLink         keyAction = "nextLink"
+	actionPrevLink         keyAction = "prevLink"
+	actionFollowLink       keyAction = "followLink"
+	actionBack             keyAction = "back"
+	actionForward          keyAction = "forward"
+	actionNextHeading      keyAction = "nextHeading"
+	actionPrevHeading      keyAction = "prevHeading"
+	actionNextCodeBlock    keyAction = "nextCodeBlock"
+	actionPrevCodeBlock    keyAction = "prevCodeBlock"
+	actionSearch           keyAction = "search"
+	actionNextMatch        keyAction = "nextMatch"
+	actionPrevMatch        keyAction = "prevMatch"
+	actionCommand          keyAction = "command"
+	actionNextBrokenLink   keyAction = "nextBrokenLink"
+	actionFirstVisibleLink keyAction = "firstVisibleLink"
+	actionLinkHints        keyAction = "linkHints"
+	actionLinks            keyAction = "links"
+	actionContents         keyAction = "contents"
+	actionTable            keyAction = "table"
+	actionRelated          keyAction = "related"
+	actionLinkGraph        keyAction = "linkGraph"
+	actionLinkDirectory    keyAction = "linkDirectory"
+	actionDefinitions      keyAction = "definitions"
+	actionFootnote         keyAction = "footnote"
+	actionEdit             keyAction = "edit"
+	actionEditLink         keyAction = "editLink"
+	actionOpen             keyAction = "open"
+	actionCopy             keyAction = "copy"
+	actionCopyView         keyAction = "copyView"
+	actionCopyRendered     keyAction = "copyRendered"
+	actionCopyLinks        keyAction = "copyLinks"
+	actionCopyLink         keyAction = "copyLink"
+	actionCopyPath         keyAction = "copyPath"
+	actionSelect           keyAction = "select"
+	actionReload           keyAction = "reload"
+	actionReloadConfig     keyAction = "reloadConfig"
+	actionReloadStyle      keyAction = "reloadStyle"
+	actionStats            keyAction = "stats"
+	actionBlame            keyAction = "blame"
+	actionPresent          keyAction = "present"
+	actionZen              keyAction = "zen"
+	actionCompact          keyAction = "compact"
+	actionWrap             keyAction = "wrap"
+	actionRaw              keyAction = "raw"
+	actionRelativeNumbers  keyAction = "relativeNumbers"
+	actionFrontMatter      keyAction = "frontMatter"
+	actionFollow           keyAction = "follow"
+	actionWatch            keyAction = "watch"
+	actionReadingTime      keyAction = "readingTime"
+	actionLinePosition     keyAction = "linePosition"
+	actionBookmark         keyAction = "bookmark"
+	actionBookmarks        keyAction = "bookmarks"
+	actionRecent           keyAction = "recent"
+	actionHelp             keyAction = "help"
+)
+
+// defaultKeyBindings are the keys of each pager action, unless they're bound
+// to others in the configuration.
+var defaultKeyBindings = []struct {
+	action keyAction
+	keys   []string
+}{
+	{actionLineUp, []string{"k", "up"}},
+	{actionLineDown, []string{"j", "down"}},
+	{actionPageUp, []string{"b", "pgup"}},
+	{actionPageDown, []string{"f", "pgdown", " "}},
+	{actionHalfPageUp, []string{"u", "ctrl+u"}},
+	{actionHalfPageDown, []string{"d", "ctrl+d"}},
+	{actionScrollLeft, []string{"h", "left"}},
+	{actionScrollRight, []string{"l", "right"}},
+	{actionTop, []string{"g", "home"}},
+	{actionBottom, []string{"G", "end"}},
+	{actionCenter, []string{"z"}},
+	{actionNextLink, []string{keyTab}},
+	{actionPrevLink, []string{keyShiftTab, "backtab"}},
+	{actionFollowLink, []string{keyEnter}},
+	{actionBack, []string{keyBackspace}},
+	{actionForward, []string{">"}},
+	{actionNextHeading, []string{"]"}},
+	{actionPrevHeading, []string{"["}},
+	{actionNextCodeBlock, []string{"}"}},
+	{actionPrevCodeBlock, []string{"{"}},
+	{actionSearch, []string{"/"}},
+	{actionNextMatch, []string{"n"}},
+	{actionPrevMatch, []string{"N"}},
+	{actionCommand, []string{":"}},
+	{actionNextBrokenLink, []string{"!"}},
+	{actionFirstVisibleLink, []string{"v"}},
+	{actionLinkHints, []string{"H"}},
+	{actionLinks, []string{"L"}},
+	{actionContents, []string{"T"}},
+	{actionTable, []string{"|"}},
+	{actionRelated, []string{"s"}},
+	{actionLinkGraph, []string{"M"}},
+	{actionLinkDirectory, []string{"O"}},
+	{actionDefinitions, []string{"D"}},
+	{actionFootnote, []string{"^"}},
+	{actionEdit, []string{"e"}},
+	{actionEditLink, []string{"E"}},
+	{actionOpen, []string{"o"}},
+	{actionCopy, []string{"c"}},
+	{actionCopyView, []string{"C"}},
+	{actionCopyRendered, []string{"X"}},
+	{actionCopyLinks, []string{"A"}},
+	{actionCopyLink, []string{"y"}},
+	{actionCopyPath, []string{"Y"}},
+	{actionSelect, []string{"V"}},
+	{actionReload, []string{"r"}},
+	{actionReloadConfig, []string{"R"}},
+	{actionReloadStyle, []string{"S"}},
+	{actionStats, []string{"I"}},
+	{actionBlame, []string{"B"}},
+	{actionPresent, []string{"P"}},
+	{actionZen, []string{"Z"}},
+	{actionCompact, []string{"="}},
+	{actionWrap, []string{"w"}},
+	{actionRaw, []string{"~"}},
+	{actionRelativeNumbers, []string{"#"}},
+	{actionFrontMatter, []string{"-"}},
+	{actionFollow, []string{"F"}},
+	{actionWatch, []string{"W"}},
+	{actionReadingTime, []string{"t"}},
+	{actionLinePosition, []string{"ctrl+g"}},
+	{actionBookmark, []string{"m"}},
+	{actionBookmarks, []string{"'"}},
+	{actionRecent, []string{"ctrl+o"}},
+	{actionHelp, []string{"?"}},
+}
+
+// Presets of key bindings, which the key bindings in the configuration are
+// applied on top of.
+const (
+	keyModeDefault = "default"
+	keyModeVim     = "vim"
+)
+
+// vimKeyBindings are the keys of the vim preset that differ from the default
+// ones. Going to the top takes g twice, like gg.
+var vimKeyBindings = map[keyAction][]string{
+	actionTop:          {"g", "home"},
+	actionLineUp:       {"k", "up", "ctrl+y"},
+	actionLineDown:     {"j", "down", "ctrl+e"},
+	actionPageUp:       {"ctrl+b", "b", "pgup"},
+	actionPageDown:     {"ctrl+f", "f", "pgdown", " "},
+	actionHalfPageUp:   {"ctrl+u", "u"},
+	actionHalfPageDown: {"ctrl+d", "d"},
+}
+
+// Names of keys in the help, where they're not the key itself.
+var keyHelpNames = map[string]string{
+	"up":        "↑",
+	"down":      "↓",
+	"left":      "←",
+	"right":     "→",
+	"pgdown":    "pgdn",
+	"backspace": "⌫",
+	keyShiftTab: "⇧tab",
+	" ":         "space",
+}
+
+// reservedKeys work the same everywhere, so they can't be bound to pager
+// actions.
+var reservedKeys = []string{"q", keyEsc, "ctrl+c", "ctrl+z", "delete"}
+
+// keyBindings maps keys to pager actions.
+type keyBindings struct {
+	actions map[string]keyAction
+	keys    map[keyAction][]string
+
+	// Keys that have to be pressed twice, like ]].
+	twice map[string]bool
+}
+
+// newKeyBindings binds pager actions to the keys of a preset, or to the keys
+// in the configuration. It returns warnings about presets and actions that
+// don't exist and keys that can't be bound as asked.
+//
+// A key bound to an action in the configuration is taken away from the
+// action it belongs to in the preset. If two actions in the configuration
+// share a key, the first one gets it.
+func newKeyBindings(mode string, config map[string][]string) (keyBindings, []string) {
+	k := keyBindings{
+		actions: map[string]keyAction{},
+		keys:    map[keyAction][]string{},
+		twice:   map[string]bool{},
+	}
+	// The character keys of these actions are pressed twice. Others, like
+	// home, are pressed once.
+	twice := map[keyAction]bool{actionNextHeading: true, actionPrevHeading: true, actionCenter: true}
+	var warnings []string
+
+	preset := map[keyAction][]string{}
+	switch strings.ToLower(mode) {
+	case "", keyModeDefault:
+	case keyModeVim:
+		preset = vimKeyBindings
+		twice[actionTop] = true
+	default:
+		warnings = append(warnings, fmt.Sprintf("unknown key mode %q", mode))
+	}
+
+	// The configuration doesn't keep the case of action names.
+	byName := map[string]keyAction{}
+	for _, b := range defaultKeyBindings {
+		byName[strings.ToLower(string(b.action))] = b.action
+	}
+	custom := map[keyAction][]string{}
+	names := make([]string, 0, len(config))
+	for name := range config {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		action, ok := byName[strings.ToLower(name)]
+		if !ok {
+			warnings = append(warnings, fmt.Sprintf("unknown key binding %q", name))
+			continue
+		}
+		custom[action] = config[name]
+	}
+
+	bind := func(action keyAction, key string) {
+		k.actions[key] = action
+		k.keys[action] = append(k.keys[action], key)
+		if twice[action] && utf8.RuneCountInString(key) == 1 {
+			k.twice[key] = true
+		}
+	}
+	for _, b := range defaultKeyBindings {
+		keys, ok := custom[b.action]
+		if !ok {
+			continue
+		}
+		k.keys[b.action] = []string{}
+		for _, key := range keys {
+			if key == "space" {
+				key = " "
+			}
+			switch other, taken := k.actions[key]; {
+			case slices.Contains(reservedKeys, key):
+				warnings = append(warnings, fmt.Sprintf("%q can't be bound to %s", key, b.action))
+			case len(key) == 1 && key[0] >= '0' && key[0] <= '9':
+				warnings = append(warnings, fmt.Sprintf("%q can't be bound to %s, digits are counts", key, b.action))
+			case taken && other != b.action:
+				warnings = append(warnings, fmt.Sprintf("%q is bound to both %s and %s", key, other, b.action))
+			case !taken:
+				bind(b.action, key)
+			}
+		}
+	}
+	for _, b := range defaultKeyBindings {
+		if _, ok := custom[b.action]; ok {
+			continue
+		}
+		keys := b.keys
+		if p, ok := preset[b.action]; ok {
+			keys = p
+		}
+		for _, key := range keys {
+			if other, taken := k.actions[key]; taken {
+				warnings = append(warnings, fmt.Sprintf("%q is bound to %s instead of %s", key, other, b.action))
+				continue
+			}
+			bind(b.action, key)
+		}
+	}
+	return k, warnings
+}
+
+// action returns the action bound to a key, or "".
+func (k keyBindings) action(key string) keyAction {
+	return k.actions[key]
+}
+
+// viewportKeyMap returns the scrolling keys of the viewport.
+func (k keyBindings) viewportKeyMap() viewport.KeyMap {
+	binding := func(action keyAction) key.Binding {
+		return key.NewBinding(key.WithKeys(k.keys[action]...))
+	}
+	return viewport.KeyMap{
+		PageDown:     binding(actionPageDown),
+		PageUp:       binding(actionPageUp),
+		HalfPageUp:   binding(actionHalfPageUp),
+		HalfPageDown: binding(actionHalfPageDown),
+		Down:         binding(actionLineDown),
+		Up:           binding(actionLineUp),
+		Left:         binding(actionScrollLeft),
+		Right:        binding(actionScrollRight),
+	}
+}
+
+// help returns the keys of actions for the help: the first two keys of a
+// single action, or the first key of each of several, like n/N. It returns
+// "" if any of them isn't bound to a key.
+func (k keyBindings) help(actions ...keyAction) string {
+	var names []string
+	for _, action := range actions {
+		var keys []string
+		for _, key := range k.keys[action] {
+			// Shift+tab goes by two names.
+			if key == "backtab" {
+				continue
+			}
+			name := key
+			if n, ok := keyHelpNames[key]; ok {
+				name = n
+			}
+			if k.twice[key] {
+				name += name
+			}
+			keys = append(keys, name)
+		}
+		if len(keys) == 0 {
+			return ""
+		}
+		if len(actions) == 1 {
+			return strings.Join(keys[:min(2, len(keys))], "/")
+		}
+		names = append(names, keys[0])
+	}
+	return strings.Join(names, "/")
+}
+
+// pagerHelpEntry is an entry of the pager help: what one or more actions do,
+// like n/N, or keys that aren't an action on their own.
+type pagerHelpEntry struct {
+	actions []keyAction
+	keys    func(keyBindings) string
+	desc    string
+}
+
+func actionsHelp(desc string, actions ...keyAction) pagerHelpEntry {
+	return pagerHelpEntry{actions: actions, desc: desc}
+}
+
+func keysHelp(desc string, keys func(keyBindings) string) pagerHelpEntry {
+	return pagerHelpEntry{keys: keys, desc: desc}
+}
+
+// firstKey returns the first key of an action in the help, or "".
+func (k keyBindings) firstKey(action keyAction) string {
+	keys, _, _ := strings.Cut(k.help(action), "/")
+	return keys
+}
+
+// pagerHelp is what's in the two columns of the pager help. Actions show up
+// in the help by having an entry here, with whatever keys they're bound to.
+var pagerHelp = [2][]pagerHelpEntry{
+	{
+		actionsHelp("up", actionLineUp),
+		actionsHelp("down", actionLineDown),
+		actionsHelp("page up", actionPageUp),
+		actionsHelp("page down", actionPageDown),
+		actionsHelp("½ page up", actionHalfPageUp),
+		actionsHelp("½ page down", actionHalfPageDown),
+		keysHelp("go to line N", func(k keyBindings) string {
+			top, bottom := k.firstKey(actionTop), k.firstKey(actionBottom)
+			if top == "" || bottom == "" {
+				return ""
+			}
+			return "N" + bottom + "/N" + top
+		}),
+		actionsHelp("prev/next code block", actionPrevCodeBlock, actionNextCodeBlock),
+		actionsHelp("prev/next heading", actionPrevHeading, actionNextHeading),
+		actionsHelp("go to line", actionCommand),
+		keysHelp("open a path", func(k keyBindings) string {
+			if command := k.firstKey(actionCommand); command != "" {
+				return command + "open"
+			}
+			return ""
+		}),
+		actionsHelp("table of contents", actionContents),
+		actionsHelp("list links", actionLinks),
+		actionsHelp("link hints", actionLinkHints),
+		actionsHelp("show table", actionTable),
+		actionsHelp("related documents", actionRelated),
+		actionsHelp("recent documents", actionRecent),
+		actionsHelp("link graph", actionLinkGraph),
+		actionsHelp("definitions", actionDefinitions),
+		actionsHelp("document statistics", actionStats),
+		actionsHelp("git blame", actionBlame),
+		actionsHelp("presentation mode", actionPresent),
+		actionsHelp("zen mode", actionZen),
+		actionsHelp("toggle wrapping", actionWrap),
+		actionsHelp("toggle compact mode", actionCompact),
+		actionsHelp("relative line numbers", actionRelativeNumbers),
+		actionsHelp("raw source", actionRaw),
+		actionsHelp("front matter", actionFrontMatter),
+		actionsHelp("footnote and back", actionFootnote),
+		actionsHelp("reading time", actionReadingTime),
+		actionsHelp("line position", actionLinePosition),
+		actionsHelp("bookmark this spot", actionBookmark),
+		actionsHelp("bookmarks", actionBookmarks),
+	},
+	{
+		actionsHelp("go to top", actionTop),
+		actionsHelp("go to bottom", actionBottom),
+		actionsHelp("center top line", actionCenter),
+		actionsHelp("next link", actionNextLink),
+		actionsHelp("prev link", actionPrevLink),
+		actionsHelp("follow link", actionFollowLink),
+		actionsHelp("go back/forward", actionBack, actionForward),
+		actionsHelp("first link in view", actionFirstVisibleLink),
+		actionsHelp("next broken link", actionNextBrokenLink),
+		actionsHelp("search", actionSearch),
+		actionsHelp("next/prev match", actionNextMatch, actionPrevMatch),
+		actionsHelp("copy contents", actionCopy),
+		actionsHelp("select lines to copy", actionSelect),
+		actionsHelp("copy view as text", actionCopyView),
+		actionsHelp("copy all links", actionCopyLinks),
+		actionsHelp("copy link path", actionCopyLink),
+		actionsHelp("copy document path", actionCopyPath),
+		actionsHelp("edit this document", actionEdit),
+		actionsHelp("edit link target", actionEditLink),
+		actionsHelp("list link directory", actionLinkDirectory),
+		actionsHelp("open in another app", actionOpen),
+		actionsHelp("reload this document", actionReload),
+		actionsHelp("reload configuration", actionReloadConfig),
+		actionsHelp("follow changes", actionFollow),
+		keysHelp("back to files", func(keyBindings) string { return keyEsc }),
+		keysHelp("quit", func(keyBindings) string { return "q" }),
+		actionsHelp("scroll sideways", actionScrollLeft, actionScrollRight),
+		actionsHelp("reload style file", actionReloadStyle),
+		keysHelp("toggle task", func(k keyBindings) string {
+			if selectKey := k.firstKey(actionSelect); selectKey != "" {
+				return selectKey + " space"
+			}
+			return ""
+		}),
+		actionsHelp("toggle watching", actionWatch),
+		actionsHelp("copy rendered output", actionCopyRendered),
+		actionsHelp("help", actionHelp),
+	},
+}
+
+// helpColumns returns the keys and what they do for each column of the pager
+// help, leaving out actions that aren't bound to any key and those that are
+// hidden, like ones that are turned off.
+func (k keyBindings) helpColumns(hidden ...keyAction) [2][]helpEntry {
+	var columns [2][]helpEntry
+	for col, entries := range pagerHelp {
+		for _, e := range entries {
+			if len(e.actions) > 0 && !slices.ContainsFunc(e.actions, func(a keyAction) bool {
+				return !slices.Contains(hidden, a)
+			}) {
+				continue
+			}
+			var keys string
+			if e.keys != nil {
+				keys = e.keys(k)
+			} else {
+				keys = k.help(e.actions...)
+			}
+			if keys != "" {
+				columns[col] = append(columns[col], helpEntry{key: keys, val: e.desc})
+			}
+		}
+	}
+	return columns
+}
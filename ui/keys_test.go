@@ -0,0 +1,160 @@
+package ui
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestKeyBindings(t *testing.T) {
+	keys, warnings := newKeyBindings("", map[string][]string{
+		"copy":        {"x"},
+		"linedown":    {"j", "ctrl+n"},
+		"pageDown":    {"space", "c"},
+		"zen":         {"q"},
+		"readingTime": {"5"},
+		"teleport":    {"T"},
+	})
+
+	for key, want := range map[string]keyAction{
+		"x":      actionCopy,
+		"ctrl+n": actionLineDown,
+		"j":      actionLineDown,
+		" ":      actionPageDown,
+		"c":      actionPageDown,
+		"f":      "",
+		"Z":      "",
+		"q":      "",
+		"X":      actionCopyRendered,
+	} {
+		if got := keys.action(key); got != want {
+			t.Errorf("expected %q to be bound to %q, got %q", key, want, got)
+		}
+	}
+
+	for _, want := range []string{
+		`unknown key binding "teleport"`,
+		`"q" can't be bound to zen`,
+		`"5" can't be bound to readingTime, digits are counts`,
+	} {
+		if !slices.Contains(warnings, want) {
+			t.Errorf("expected warning %q, got %q", want, warnings)
+		}
+	}
+
+	// Two actions asking for the same key.
+	_, warnings = newKeyBindings("", map[string][]string{"copy": {"x"}, "wrap": {"x"}})
+	if !slices.Equal(warnings, []string{`"x" is bound to both copy and wrap`}) {
+		t.Errorf("expected a conflict, got %q", warnings)
+	}
+
+	// Without configuration, every default key is bound.
+	if _, warnings := newKeyBindings("", nil); len(warnings) > 0 {
+		t.Errorf("expected the default keys not to conflict, got %q", warnings)
+	}
+}
+
+func TestPagerCustomKeys(t *testing.T) {
+	m := newTestPager(t, Config{KeyBindings: map[string][]string{"relativeNumbers": {"c"}}}, "notes.md", 80)
+	m.currentDocument.Body = "# Notes\n"
+	m, cmd := m.update(contentRenderedMsg("# Notes\n"))
+	if !strings.HasPrefix(m.statusMessage, `Key bindings: "c" is bound to relativeNumbers instead of copy`) || cmd == nil {
+		t.Fatalf("expected the conflict to be shown once there's a document, got %q", m.statusMessage)
+	}
+
+	m = typeKeys(t, m, "c")
+	if m.statusMessage != "No line numbers" {
+		t.Fatalf("expected c to do what it's bound to, got status %q", m.statusMessage)
+	}
+	help := strings.Join(m.helpRows(), "\n")
+	if !strings.Contains(help, "c        relative line numbers") || strings.Contains(help, "copy contents") {
+		t.Fatalf("expected the help to show the keys in use, got\n%s", help)
+	}
+
+	// Scrolling keys go to the viewport.
+	m = newTestPager(t, Config{KeyBindings: map[string][]string{"lineDown": {"ctrl+n"}}}, "notes.md", 80)
+	m, _ = m.update(contentRenderedMsg(strings.Repeat("line\n", 100)))
+	m, _ = m.update(tea.KeyMsg{Type: tea.KeyCtrlN})
+	m = typeKeys(t, m, "j")
+	if m.viewport.YOffset != 1 {
+		t.Fatalf("expected only ctrl+n to scroll down, got offset %d", m.viewport.YOffset)
+	}
+}
+
+func TestVimKeys(t *testing.T) {
+	m := newTestPager(t, Config{KeyMode: "vim"}, "app.log", 80)
+	m, _ = m.update(contentRenderedMsg(strings.Repeat("line\n", 100)))
+	m.viewport.SetYOffset(50)
+
+	m = typeKeys(t, m, "g")
+	if m.viewport.YOffset != 50 {
+		t.Fatalf("expected a single g to wait for the second, got offset %d", m.viewport.YOffset)
+	}
+	m = typeKeys(t, m, "g")
+	if m.viewport.YOffset != 0 {
+		t.Fatalf("expected gg to go to the top, got offset %d", m.viewport.YOffset)
+	}
+
+	m, _ = m.update(tea.KeyMsg{Type: tea.KeyCtrlF})
+	if m.viewport.YOffset == 0 {
+		t.Fatal("expected ctrl+f to page down")
+	}
+
+	// A count carries over to the second g.
+	m = typeKeys(t, m, "1", "0", "g", "g")
+	if m.count != 0 || m.pendingKey != "" {
+		t.Fatalf("expected 10gg to be done with, got count %d", m.count)
+	}
+
+	// Keys other than g go to the top with a single press.
+	m.viewport.SetYOffset(50)
+	m, _ = m.update(tea.KeyMsg{Type: tea.KeyHome})
+	if m.viewport.YOffset != 0 || m.pendingKey != "" {
+		t.Fatalf("expected a single home to go to the top, got offset %d", m.viewport.YOffset)
+	}
+
+	for action, want := range map[keyAction]string{
+		actionTop:          "gg/home",
+		actionBottom:       "G/end",
+		actionPageDown:     "ctrl+f/f",
+		actionHalfPageDown: "ctrl+d/d",
+		actionNextHeading:  "]]",
+	} {
+		if got := m.keys.help(action); got != want {
+			t.Errorf("expected the help to show %q for %s, got %q", want, action, got)
+		}
+	}
+	help := stripANSI(m.helpView())
+	if !strings.Contains(help, "NG/Ngg ") {
+		t.Errorf("expected NG/Ngg in the help, got\n%s", help)
+	}
+
+	if _, warnings := newKeyBindings("emacs", nil); !slices.Equal(warnings, []string{`unknown key mode "emacs"`}) {
+		t.Errorf("expected an unknown preset to be warned about, got %q", warnings)
+	}
+}
+
+func TestPagerHelpCoversActions(t *testing.T) {
+	described := map[keyAction]bool{}
+	for _, entries := range pagerHelp {
+		for _, e := range entries {
+			for _, action := range e.actions {
+				described[action] = true
+			}
+		}
+	}
+	for _, b := range defaultKeyBindings {
+		if !described[b.action] {
+			t.Errorf("expected %s to have an entry in the help", b.action)
+		}
+	}
+
+	// Unbound actions are left out rather than leaving a gap.
+	m := newTestPager(t, Config{KeyBindings: map[string][]string{"lineUp": {}}}, "notes.md", 100)
+	lines := strings.Split(stripANSI(m.helpView()), "\n")
+	if !strings.Contains(lines[1], "j/↓      down") {
+		t.Fatalf("expected the next entry to move up, got %q", lines[1])
+	}
+}
@@ -1,11 +1,13 @@
 package ui
 
 import (
+	"bytes"
 	"fmt"
 	"math"
 	"time"
 	"unicode"
 
+	"github.com/charmbracelet/glow/v2/utils"
 	"github.com/charmbracelet/log"
 	"github.com/dustin/go-humanize"
 	"golang.org/x/text/runes"
@@ -23,11 +25,38 @@ type markdown struct {
 	// field is ephemeral, and should only be referenced during filtering.
 	filterValue string
 
+	// Whether the file looks binary, in which case we don't render it.
+	binary bool
+
+	// Show the source as is instead of rendering it.
+	raw bool
+
+	// Front matter header of the document, without the --- lines around
+	// it. It's not part of the body.
+	frontMatter string
+
+	// Number of lines of the source before the body, which line numbers
+	// count from so they match the file.
+	bodyOffset int
+
 	Body    string
 	Note    string
 	Modtime time.Time
 }
 
+// setContent sets the body of the document, keeping the front matter of
+// markdown documents apart. Other files are kept whole, so that their line
+// numbers match the source.
+func (m *markdown) setContent(content []byte) {
+	if !utils.IsMarkdownFile(m.Note) {
+		m.Body, m.frontMatter, m.bodyOffset = string(content), "", 0
+		return
+	}
+	front, body := utils.SplitFrontmatter(content)
+	m.Body, m.frontMatter = string(body), string(front)
+	m.bodyOffset = bytes.Count(content[:len(content)-len(body)], []byte("\n"))
+}
+
 // Generate the value we're doing to filter against.
 func (m *markdown) buildFilterValue() {
 	note, err := normalize(m.Note)
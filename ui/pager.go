@@ -1,34 +1,45 @@
 package ui
 
 import (
+	"errors"
 	"fmt"
 	"math"
+	"os"
 	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
+	"unicode/utf8"
 
-	"github.com/atotto/clipboard"
+	"github.com/charmbracelet/bubbles/textinput"
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/glamour"
 	"github.com/charmbracelet/glow/v2/utils"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/charmbracelet/log"
+	"github.com/dustin/go-humanize"
 	"github.com/fsnotify/fsnotify"
 	runewidth "github.com/mattn/go-runewidth"
 	"github.com/muesli/reflow/ansi"
 	"github.com/muesli/reflow/truncate"
-	"github.com/muesli/termenv"
+	"github.com/muesli/reflow/wrap"
 )
 
 const (
 	statusBarHeight = 1
 	lineNumberWidth = 4
+
+	// Narrowest the document gets because of content margins.
+	minContentWidth = 20
+
+	// Upper bound for count prefixes, so they don't overflow.
+	maxCount = 1_000_000
 )
 
 var (
-	pagerHelpHeight int
-
 	mintGreen = lipgloss.AdaptiveColor{Light: "#89F0CB", Dark: "#89F0CB"}
 	darkGreen = lipgloss.AdaptiveColor{Light: "#1C8760", Dark: "#1C8760"}
 
@@ -80,8 +91,22 @@ var (
 type (
 	contentRenderedMsg string
 	reloadMsg          struct{}
+	viewportSyncMsg    struct{}
+
+	// The file was removed or renamed, and whether it's gone for good is
+	// checked a little later.
+	fileRemovedMsg      struct{}
+	fileRemovalCheckMsg struct{}
+
+	// A file the document includes changed.
+	includeChangedMsg struct{}
 )
 
+// How long to wait after the file is removed or renamed before calling it
+// deleted. Editors that save by replacing the file, like vim, create it
+// again right away.
+const fileRemovalGrace = 200 * time.Millisecond
+
 type pagerState int
 
 const (
@@ -100,9 +125,17 @@ type pagerModel struct {
 	state    pagerState
 	showHelp bool
 
+	// The page of the help being shown, when it doesn't fit at once.
+	helpPage int
+
 	statusMessage      string
 	statusMessageTimer *time.Timer
 
+	// The status message as requested, before counting repeats, and how
+	// many times in a row it was shown.
+	statusMessageBase   string
+	statusMessageRepeat int
+
 	// Current document being rendered, sans-glamour rendering. We cache
 	// it here so we can re-render it on resize.
 	currentDocument markdown
@@ -113,11 +146,193 @@ type pagerModel struct {
 	focusedLink int
 	history     []navEntry
 
+	// Documents we went back from, most recent last.
+	forward []navEntry
+
+	// Links that look followable but aren't, and the one that's focused, or
+	// -1.
+	brokenLinks   []brokenLink
+	focusedBroken int
+
+	// Definitions being browsed, and the focused term, or -1.
+	definitions []definition
+	focusedTerm int
+
 	pendingRestoreYOffset *int
 
-	watcher     *fsnotify.Watcher
-	watchedDir  string
-	watchCancel chan struct{}
+	// Smooth scroll under way, if any.
+	smoothScroll *smoothScroll
+
+	// Where documents were left, kept between sessions, the documents whose
+	// positions changed since they were saved, and the document whose
+	// position was last restored.
+	positions      scrollPositions
+	movedPositions map[string]bool
+	positionPath   string
+
+	// Spots in documents to jump back to, kept between sessions.
+	bookmarks []bookmark
+
+	// Absolute paths of the documents viewed lately, most recent first, and
+	// of those viewed since they were saved, in the order they were.
+	recent       []string
+	viewedRecent []string
+
+	// Headings of the current document and where they are in the rendered
+	// output. Recomputed every time the document is rendered.
+	headings []heading
+
+	// Heading anchor to jump to once the document we're loading is rendered.
+	pendingFragment string
+
+	// Rendered line briefly highlighted after a jump, or -1.
+	flashLine int
+
+	// Selectable list drawn in place of the viewport, if any.
+	overlay *listOverlay
+
+	// Presentation mode, where the document is split into slides that are
+	// shown one at a time.
+	presenting          bool
+	slides              []string
+	slide               int
+	presentationYOffset int
+
+	// Line we were looking at when git blame was requested.
+	blameLine int
+
+	// Text selected with the mouse.
+	mouseSelection *mouseSelection
+
+	// Keys of the pager's actions, and what was wrong with the configuration,
+	// like the keys bound in it, to be shown once there's a document.
+	keys     keyBindings
+	warnings []string
+
+	// Numeric prefix typed before a command, like the 42 in 42G.
+	count int
+
+	// First press of a key that's pressed twice, like ]], waiting for the
+	// second.
+	pendingKey string
+
+	// Render without wrapping lines to the window width, and where to
+	// scroll to once the document is re-rendered after toggling it.
+	noWrap        bool
+	pendingAnchor *scrollAnchor
+
+	// Columns the content is scrolled sideways by, when lines are wider
+	// than the viewport.
+	xOffset int
+
+	// Source line, counting from 1, to scroll to once the document is
+	// rendered, after switching between it and its source.
+	pendingSourceLine int
+
+	// Collapse runs of blank lines in the rendered document.
+	compact bool
+
+	// Show line numbers relative to the top of the viewport, and the
+	// offset they were last numbered for.
+	relativeNumbers bool
+	numberedTop     int
+
+	// Show the front matter of the document above it.
+	showFrontMatter bool
+
+	// Show the word count and reading time in the status bar, and the
+	// number of words of the document.
+	showReadingTime bool
+	words           int
+
+	// Show the line at the top of the viewport and the number of lines in
+	// the status bar.
+	showLinePosition bool
+
+	// State of the document according to git, and the file it's for.
+	gitStatus     gitFileStatus
+	gitStatusPath string
+
+	// Scroll to the bottom when the document is reloaded.
+	follow bool
+
+	// Fenced code blocks of the document.
+	codeBlocks []codeBlock
+
+	// Tables of the document, and the one shown in an overlay, if any.
+	tables []table
+	table  *tableOverlay
+
+	// Footnote references and definitions, and the reference we last
+	// jumped to a definition from.
+	footnotes      []footnoteMark
+	footnoteReturn footnoteMark
+
+	// Lines selected for copying, or nil when not selecting.
+	selection *lineSelection
+
+	// Numbered link hints, or nil when they're not shown.
+	hints *linkHints
+
+	// Layout to restore when leaving zen mode, or nil if we're not in it.
+	zen *zenState
+
+	// Whether a high performance sync is scheduled for a jump to the top or
+	// bottom. Further jumps until then ride along with it.
+	syncPending bool
+
+	// Large file the user was warned about. Following a link to it again
+	// opens it.
+	confirmLargeFile string
+
+	// Whether the document being rendered was reloaded because it changed
+	// on disk, and whether the link that was focused is gone since.
+	reloading       bool
+	focusedLinkGone bool
+
+	// Whether the file of the document was deleted. It's reloaded if it
+	// comes back.
+	fileDeleted bool
+
+	// Whether watching the document was turned off, and when it was last
+	// modified at that point.
+	watchPaused   bool
+	pausedModTime time.Time
+
+	// Contents we last wrote to the file of the document ourselves, like
+	// when toggling a task, so that the watcher doesn't reload it for that.
+	ownWrite string
+
+	// In-document search. The history lives for the whole session, so it
+	// survives unloading the document.
+	searching        bool
+	searchInput      textinput.Model
+	searchOptions    searchOptions
+	searchQuery      string
+	searchPattern    *regexp.Regexp
+	searchMatches    []searchMatch
+	searchMatch      int
+	searchHistory    []string
+	searchHistoryPos int
+	searchDraft      string
+
+	// Prompt for a line number to jump to or a command like open.
+	promptingCommand bool
+	commandInput     textinput.Model
+
+	watcher    *fsnotify.Watcher
+	watchedDir string
+
+	// Directories of files the document includes that are watched too.
+	watchedIncludeDirs []string
+	watchCancel        chan struct{}
+
+	// When the user last did something, and whether we stopped watching
+	// since, along with when the document was last modified at that point.
+	lastActivity     time.Time
+	idleCheckPending bool
+	idle             bool
+	idleModTime      time.Time
 }
 
 func newPagerModel(common *commonModel) pagerModel {
@@ -126,47 +341,223 @@ func newPagerModel(common *commonModel) pagerModel {
 	vp.HighPerformanceRendering = common.cfg.HighPerformancePager
 
 	m := pagerModel{
-		common:      common,
-		state:       pagerStateBrowse,
-		viewport:    vp,
-		focusedLink: -1,
+		common:           common,
+		state:            pagerStateBrowse,
+		viewport:         vp,
+		focusedLink:      -1,
+		focusedBroken:    -1,
+		focusedTerm:      -1,
+		flashLine:        -1,
+		footnoteReturn:   footnoteMark{Line: -1},
+		searchInput:      newSearchInput(),
+		commandInput:     newCommandInput(),
+		compact:          common.cfg.Compact,
+		relativeNumbers:  common.cfg.RelativeLineNumbers,
+		showFrontMatter:  common.cfg.ShowFrontMatter,
+		showReadingTime:  common.cfg.ShowReadingTime,
+		showLinePosition: common.cfg.ShowLinePosition,
+		follow:           common.cfg.Follow,
+		positions:        loadScrollPositions(common.cfg.PositionsFile),
+		bookmarks:        loadBookmarks(common.cfg.BookmarksFile),
+		recent:           loadRecentDocuments(common.cfg.RecentFile),
 	}
+	m.setKeyBindings()
 	m.initWatcher()
 	return m
 }
 
+// setKeyBindings binds the pager's actions to keys as configured, and sets
+// how far the mouse wheel scrolls. What was wrong with the configuration is
+// kept to be shown.
+func (m *pagerModel) setKeyBindings() {
+	var keyWarnings []string
+	m.keys, keyWarnings = newKeyBindings(m.common.cfg.KeyMode, m.common.cfg.KeyBindings)
+	m.warnings = slices.Clone(m.common.cfg.Warnings)
+	for _, w := range keyWarnings {
+		log.Warn("key binding", "warning", w)
+		m.warnings = append(m.warnings, "Key bindings: "+w)
+	}
+	m.viewport.KeyMap = m.keys.viewportKeyMap()
+	m.viewport.MouseWheelDelta = defaultMouseWheelDelta
+	if step := m.common.cfg.ScrollStep; step > 0 {
+		m.viewport.MouseWheelDelta = int(step) //nolint:gosec
+	}
+}
+
+// warningsCmd shows what was wrong with the configuration, if anything,
+// once.
+func (m *pagerModel) warningsCmd() tea.Cmd {
+	if len(m.warnings) == 0 {
+		return nil
+	}
+	msg := m.warnings[0]
+	if n := len(m.warnings) - 1; n > 0 {
+		msg += fmt.Sprintf(" (and %d more, see the log)", n)
+	}
+	m.warnings = nil
+	return m.showStatusMessage(pagerStatusMessage{msg, true})
+}
+
 func (m *pagerModel) setSize(w, h int) {
-	m.viewport.Width = w
+	m.viewport.Width = max(0, w-2*m.contentMargin())
 	m.viewport.Height = h - statusBarHeight
 
 	if m.showHelp {
-		if pagerHelpHeight == 0 {
-			pagerHelpHeight = strings.Count(m.helpView(), "\n")
-		}
-		m.viewport.Height -= (statusBarHeight + pagerHelpHeight)
+		// The help layout depends on the width, so measure it every time.
+		helpHeight := strings.Count(m.helpView(), "\n")
+		m.viewport.Height -= (statusBarHeight + helpHeight)
+	}
+
+	// The front matter goes above the viewport.
+	m.viewport.YPosition = m.frontMatterHeight()
+	m.viewport.Height -= m.viewport.YPosition
+}
+
+// defaultMouseWheelDelta is how many lines the mouse wheel scrolls, unless
+// it's configured otherwise.
+const defaultMouseWheelDelta = 3
+
+// syncCoalesceDelay is how long jumps to the top or bottom wait for each
+// other before the viewport is synced in high performance mode.
+const syncCoalesceDelay = 30 * time.Millisecond
+
+// scheduleSync arranges for the viewport to be synced in high performance
+// mode. Jumps in quick succession share a single sync so that slow terminals
+// don't redraw for every key press.
+func (m *pagerModel) scheduleSync() tea.Cmd {
+	if m.common == nil || !m.common.cfg.HighPerformancePager || m.syncPending {
+		return nil
+	}
+	m.syncPending = true
+	return tea.Tick(syncCoalesceDelay, func(time.Time) tea.Msg {
+		return viewportSyncMsg{}
+	})
+}
+
+// contentMargin returns the number of blank columns on either side of the
+// viewport. It's reduced on narrow terminals so some text always fits.
+func (m pagerModel) contentMargin() int {
+	margin := int(m.common.cfg.ContentMargin) //nolint:gosec
+	if m.zen != nil {
+		margin = max(margin, m.zenMargin())
+	}
+	return max(0, min(margin, (m.common.width-minContentWidth)/2))
+}
+
+// indentLines prefixes every line of s with n spaces.
+func indentLines(s string, n int) string {
+	if n <= 0 {
+		return s
 	}
+	indent := strings.Repeat(" ", n)
+	return indent + strings.ReplaceAll(s, "\n", "\n"+indent)
 }
 
 func (m *pagerModel) setContent(s string) {
 	m.viewport.SetContent(s)
+	if m.xOffset > 0 {
+		// Lines may not be as wide anymore.
+		m.xOffset = min(m.xOffset, max(0, widestLine(s)-m.viewport.Width))
+		m.viewport.SetXOffset(m.xOffset)
+	}
 }
 
 func (m *pagerModel) applyRenderedContent() {
+	if m.presenting {
+		return
+	}
 	content := m.rendered
+	m.numberedTop = m.viewport.YOffset
+	if m.relativeNumbers {
+		content = relativeLineNumbers(content, m.currentDocument.bodyOffset+1, m.viewport.YOffset)
+	}
+	if len(m.searchMatches) > 0 {
+		content = highlightMatches(content, m.searchMatches, m.searchMatch)
+	}
+	if m.flashLine >= 0 {
+		content = highlightLine(content, m.flashLine)
+	}
+	if m.selection != nil {
+		first, last := m.selection.lines()
+		content = highlightLines(content, first, last)
+	}
+	if m.mouseSelection != nil {
+		first, last := m.mouseSelection.cells()
+		content = highlightRegion(content, first, last)
+	}
+	if m.focusedTerm >= 0 && m.focusedTerm < len(m.definitions) {
+		content = highlightFocusedLink(content, definitionTargets(m.definitions), m.focusedTerm, reverseSpan)
+	}
+	if m.focusedBroken >= 0 && m.focusedBroken < len(m.brokenLinks) {
+		content = highlightFocusedLink(content, brokenLinkTargets(m.brokenLinks), m.focusedBroken, reverseSpan)
+	}
 	if m.focusedLink >= 0 {
-		content = highlightFocusedLink(content, m.links, m.focusedLink)
+		style := colorSpan(m.common.cfg.FocusedLinkForeground, m.common.cfg.FocusedLinkBackground)
+		content = highlightFocusedLink(content, m.links, m.focusedLink, style)
+	}
+	if m.hints != nil {
+		content = drawLinkHints(content, m.links, reverseSpan)
 	}
 	m.setContent(content)
 }
 
 func (m *pagerModel) toggleHelp() {
+	top := m.viewport.YOffset
 	m.showHelp = !m.showHelp
+	m.helpPage = 0
 	m.setSize(m.common.width, m.common.height)
+	if m.common.cfg.PinHelpToggle {
+		// Set the offset directly, as SetYOffset wouldn't let us past the
+		// bottom.
+		m.viewport.YOffset = top
+		return
+	}
 	if m.viewport.PastBottom() {
 		m.viewport.GotoBottom()
 	}
 }
 
+// nextHelp shows the help, or its next page if it doesn't fit at once, and
+// hides it after the last page.
+func (m *pagerModel) nextHelp() {
+	if m.showHelp && m.helpPage+1 < m.helpPages() {
+		m.helpPage++
+		return
+	}
+	m.toggleHelp()
+}
+
+// reloadConfig reads the configuration again and re-renders the document
+// with it, staying at the same place in the document. If the configuration
+// can't be read, we keep the one we have.
+func (m *pagerModel) reloadConfig() tea.Cmd {
+	reload := m.common.cfg.ReloadConfig
+	if reload == nil {
+		return m.showStatusMessage(pagerStatusMessage{"Can't reload the configuration", true})
+	}
+	cfg, err := reload()
+	if err != nil {
+		log.Error("error reloading configuration", "error", err)
+		return m.showStatusMessage(pagerStatusMessage{"Couldn't reload config: " + err.Error(), true})
+	}
+	cfg.ReloadConfig = reload
+	// The package's config is left as it was at startup, since rendering
+	// commands read it while they run.
+	m.common.cfg = cfg
+	m.common.wikiIndex.reset()
+	m.setKeyBindings()
+
+	a := m.currentScrollAnchor()
+	m.pendingAnchor = &a
+	m.setSize(m.common.width, m.common.height)
+
+	return tea.Batch(
+		m.showStatusMessage(pagerStatusMessage{"Reloaded configuration", false}),
+		m.warningsCmd(),
+		renderWithGlamour(*m, m.currentDocument.Body),
+	)
+}
+
 type pagerStatusMessage struct {
 	message string
 	isError bool
@@ -176,9 +567,22 @@ type pagerStatusMessage struct {
 // that the returned command should be sent back the through the pager
 // update function.
 func (m *pagerModel) showStatusMessage(msg pagerStatusMessage) tea.Cmd {
+	// Count identical messages shown back to back, so that repeating an
+	// action visibly does something.
+	repeat := 1
+	if m.common != nil && m.common.cfg.CountRepeatedStatusMessages &&
+		m.state == pagerStateStatusMessage && m.statusMessageBase == msg.message {
+		repeat = m.statusMessageRepeat + 1
+	}
+	m.statusMessageBase = msg.message
+	m.statusMessageRepeat = repeat
+
 	// Show a success message to the user
 	m.state = pagerStateStatusMessage
 	m.statusMessage = msg.message
+	if repeat > 1 {
+		m.statusMessage = fmt.Sprintf("%s (x%d)", msg.message, repeat)
+	}
 	if m.statusMessageTimer != nil {
 		m.statusMessageTimer.Stop()
 	}
@@ -187,8 +591,40 @@ func (m *pagerModel) showStatusMessage(msg pagerStatusMessage) tea.Cmd {
 	return waitForStatusMessageTimeout(pagerContext, m.statusMessageTimer)
 }
 
+// copyContents copies s to the clipboard and reports the outcome in the
+// status bar. If the clipboard is unavailable we optionally fall back to
+// writing the contents to a temp file.
+func (m *pagerModel) copyContents(s, successMsg string) tea.Cmd {
+	// Terminals tend to silently truncate large OSC 52 sequences, so we
+	// don't use it past a certain size.
+	limit := int(m.common.cfg.OSC52MaxBytes) //nolint:gosec
+	oversized := limit > 0 && len(s) > limit
+
+	err := copyToClipboard(s, !oversized)
+	if err == nil {
+		if oversized {
+			successMsg += " (too large for OSC 52, native clipboard only)"
+		}
+		return m.showStatusMessage(pagerStatusMessage{successMsg, false})
+	}
+	log.Debug("error copying to clipboard", "error", err)
+
+	if m.common.cfg.ClipboardFallback == clipboardFallbackFile {
+		path, err := writeClipboardFallback(s)
+		if err == nil {
+			return m.showStatusMessage(pagerStatusMessage{"Clipboard unavailable, wrote " + path, false})
+		}
+		log.Error("error writing clipboard fallback", "error", err)
+	}
+
+	return m.showStatusMessage(pagerStatusMessage{"Clipboard unavailable", true})
+}
+
 func (m *pagerModel) unload() {
 	log.Debug("unload")
+	m.savePositions()
+	m.saveRecent()
+	m.positionPath = ""
 	if m.showHelp {
 		m.toggleHelp()
 	}
@@ -201,8 +637,45 @@ func (m *pagerModel) unload() {
 	m.rendered = ""
 	m.links = nil
 	m.focusedLink = -1
+	m.brokenLinks = nil
+	m.focusedBroken = -1
+	m.definitions = nil
+	m.focusedTerm = -1
 	m.history = nil
+	m.forward = nil
 	m.pendingRestoreYOffset = nil
+	m.pendingAnchor = nil
+	m.pendingSourceLine = 0
+	m.smoothScroll = nil
+	m.syncPending = false
+	m.selection = nil
+	m.mouseSelection = nil
+	m.hints = nil
+	m.headings = nil
+	m.codeBlocks = nil
+	m.tables = nil
+	m.table = nil
+	m.footnotes = nil
+	m.footnoteReturn = footnoteMark{Line: -1}
+	m.words = 0
+	m.gitStatusPath = ""
+	m.pendingFragment = ""
+	m.flashLine = -1
+	m.overlay = nil
+	m.presenting = false
+	m.slides = nil
+	m.slide = 0
+	m.reloading = false
+	m.focusedLinkGone = false
+	m.fileDeleted = false
+	m.watchPaused = false
+	m.xOffset = 0
+	m.viewport.SetXOffset(0)
+	m.ownWrite = ""
+	m.confirmLargeFile = ""
+	m.idle = false
+	m.stopSearch()
+	m.clearSearch()
 	m.stopWatching()
 }
 
@@ -214,29 +687,87 @@ func (m pagerModel) update(msg tea.Msg) (pagerModel, tea.Cmd) {
 
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
-		switch msg.String() {
-		case "q", keyEsc:
-			if m.state != pagerStateBrowse {
-				m.state = pagerStateBrowse
-				return m, nil
+		// A key press ends a smooth scroll where it was going before doing
+		// what it does.
+		m.finishSmoothScroll()
+
+		if m.searching {
+			return m, m.updateSearch(msg)
+		}
+		if m.promptingCommand {
+			return m, m.updateCommandPrompt(msg)
+		}
+		if m.overlay != nil {
+			return m, m.updateOverlay(msg)
+		}
+		if m.table != nil {
+			return m, m.updateTable(msg)
+		}
+		if m.presenting {
+			return m, m.updatePresentation(msg)
+		}
+		if m.definitions != nil {
+			return m, m.updateDefinitions(msg)
+		}
+		if m.selection != nil {
+			return m, m.updateSelection(msg)
+		}
+		if m.hints != nil {
+			return m, m.updateLinkHints(msg)
+		}
+		if m.mouseSelection != nil {
+			if cmd, done := m.updateMouseSelection(msg); done {
+				return m, cmd
+			}
+		}
+
+		// Collect count prefixes. Zero only counts after another digit.
+		if r := msg.Runes; msg.Type == tea.KeyRunes && len(r) == 1 && r[0] >= '0' && r[0] <= '9' &&
+			(r[0] != '0' || m.count > 0) {
+			if m.count < maxCount {
+				m.count = m.count*10 + int(r[0]-'0')
 			}
-		case keyTab:
+			return m, nil
+		}
+		count := m.count
+		m.count = 0
+		pending := m.pendingKey
+		m.pendingKey = ""
+
+		key := msg.String()
+		if (key == "q" || key == keyEsc) && m.state != pagerStateBrowse {
+			m.state = pagerStateBrowse
+			return m, nil
+		}
+
+		action := m.keys.action(key)
+		if m.keys.twice[key] && pending != key {
+			// Keep the count for the second press, like 42gg.
+			m.pendingKey = key
+			m.count = count
+			return m, nil
+		}
+
+		switch action {
+		case actionNextLink:
 			if len(m.links) == 0 {
 				cmds = append(cmds, m.showStatusMessage(pagerStatusMessage{"No followable links", false}))
 				break
 			}
+			m.focusedBroken = -1
 			if m.focusedLink < 0 {
 				m.focusedLink = 0
 			} else {
 				m.focusedLink = (m.focusedLink + 1) % len(m.links)
 			}
 			m.applyRenderedContent()
-			cmds = append(cmds, m.showStatusMessage(pagerStatusMessage{"Open: " + m.links[m.focusedLink].ResolvedNote, false}))
-		case keyShiftTab, "backtab":
+			cmds = append(cmds, m.showStatusMessage(pagerStatusMessage{m.links[m.focusedLink].focusMessage(), false}))
+		case actionPrevLink:
 			if len(m.links) == 0 {
 				cmds = append(cmds, m.showStatusMessage(pagerStatusMessage{"No followable links", false}))
 				break
 			}
+			m.focusedBroken = -1
 			if m.focusedLink < 0 {
 				m.focusedLink = len(m.links) - 1
 			} else {
@@ -246,38 +777,78 @@ func (m pagerModel) update(msg tea.Msg) (pagerModel, tea.Cmd) {
 				}
 			}
 			m.applyRenderedContent()
-			cmds = append(cmds, m.showStatusMessage(pagerStatusMessage{"Open: " + m.links[m.focusedLink].ResolvedNote, false}))
+			cmds = append(cmds, m.showStatusMessage(pagerStatusMessage{m.links[m.focusedLink].focusMessage(), false}))
 
-		case keyEnter:
+		case actionFollowLink:
 			if m.focusedLink >= 0 && m.focusedLink < len(m.links) {
 				cmd := m.followFocusedLink()
 				return m, cmd
 			}
+			if m.footnoteInView() >= 0 {
+				cmd := m.jumpToFootnote()
+				return m, cmd
+			}
 			if len(m.links) > 0 {
 				cmds = append(cmds, m.showStatusMessage(pagerStatusMessage{"Tab to select a link", false}))
 			}
 
-		case keyBackspace:
+		case actionBack:
 			if len(m.history) > 0 {
 				cmd := m.goBack()
 				return m, cmd
 			}
-		case "home", "g":
-			m.viewport.GotoTop()
-			if m.common != nil && m.common.cfg.HighPerformancePager {
-				cmds = append(cmds, viewport.Sync(m.viewport))
+		case actionForward:
+			if len(m.forward) == 0 {
+				return m, m.showStatusMessage(pagerStatusMessage{"Nothing to go forward to", false})
 			}
-		case "end", "G":
-			m.viewport.GotoBottom()
-			if m.common != nil && m.common.cfg.HighPerformancePager {
-				cmds = append(cmds, viewport.Sync(m.viewport))
+			return m, m.goForward()
+		case actionNextHeading:
+			return m, m.jumpToNextHeading(1)
+		case actionPrevHeading:
+			return m, m.jumpToNextHeading(-1)
+
+		case actionTop:
+			if count > 0 {
+				return m, m.goToLine(count)
 			}
-
-		case "e":
-			lineno := int(math.RoundToEven(float64(m.viewport.TotalLineCount()) * m.viewport.ScrollPercent()))
-			if m.viewport.AtTop() {
-				lineno = 0
+			cmds = append(cmds, m.scrollTo(0), m.scheduleSync())
+		case actionBottom:
+			if count > 0 {
+				return m, m.goToLine(count)
+			}
+			cmds = append(cmds, m.scrollTo(m.viewport.TotalLineCount()), m.scheduleSync())
+		case actionHalfPageUp, actionHalfPageDown:
+			// A configured scroll step replaces the viewport's half page.
+			step := int(m.common.cfg.ScrollStep) //nolint:gosec
+			if step == 0 {
+				break
+			}
+			if action == actionHalfPageUp {
+				m.viewport.ScrollUp(step)
+			} else {
+				m.viewport.ScrollDown(step)
 			}
+			return m, m.scheduleSync()
+		case actionCenter:
+			// Bring the top line to the middle, as far as the top of the
+			// document allows.
+			m.viewport.SetYOffset(m.viewport.YOffset - m.viewport.Height/2)
+			cmds = append(cmds, m.scheduleSync())
+
+		case actionEditLink:
+			if m.focusedLink < 0 || m.focusedLink >= len(m.links) {
+				cmds = append(cmds, m.showStatusMessage(pagerStatusMessage{"Tab to select a link", false}))
+				break
+			}
+			l := m.links[m.focusedLink]
+			if l.External {
+				cmds = append(cmds, m.showStatusMessage(pagerStatusMessage{"Not a local file", false}))
+				break
+			}
+			return m, openLinkInEditor(l, m.common.cfg.DuplicateHeadingSlugs)
+
+		case actionEdit:
+			lineno := m.currentLine()
 			log.Info(
 				"opening editor",
 				"file", m.currentDocument.localPath,
@@ -285,23 +856,263 @@ func (m pagerModel) update(msg tea.Msg) (pagerModel, tea.Cmd) {
 			)
 			return m, openEditor(m.currentDocument.localPath, lineno)
 
-		case "c":
-			// Copy using OSC 52
-			termenv.Copy(m.currentDocument.Body)
-			// Copy using native system clipboard
-			_ = clipboard.WriteAll(m.currentDocument.Body)
-			cmds = append(cmds, m.showStatusMessage(pagerStatusMessage{"Copied contents", false}))
+		case actionCopy:
+			body := trimTrailingNewlines(m.currentDocument.Body, m.common.cfg.CopyTrailingNewline)
+			cmds = append(cmds, m.copyContents(body, "Copied contents"))
 
-		case "r":
-			return m, loadLocalMarkdown(&m.currentDocument)
+		case actionCopyView:
+			text := visibleText(m.rendered, m.viewport.YOffset, m.viewport.Height)
+			if m.common.cfg.CopyStripGutter {
+				text = stripGutter(text, m.gutterWidth())
+			}
+			cmds = append(cmds, m.copyContents(text, "Copied view as text"))
 
-		case "?":
-			m.toggleHelp()
+		case actionCopyRendered:
+			// With colors and all, for pasting somewhere that shows them.
+			cmds = append(cmds, m.copyContents(m.rendered, "Copied rendered output"))
+
+		case actionCopyPath:
+			if m.currentDocument.localPath == "" {
+				break
+			}
+			path, err := filepath.Abs(m.currentDocument.localPath)
+			if err != nil {
+				path = m.currentDocument.localPath
+			}
+			cmds = append(cmds, m.copyContents(path, "Copied "+path))
+
+		case actionCopyLink:
+			if m.focusedLink < 0 || m.focusedLink >= len(m.links) {
+				cmds = append(cmds, m.showStatusMessage(pagerStatusMessage{"No link focused", false}))
+				break
+			}
+			path := m.links[m.focusedLink].ResolvedPath
+			if l := m.links[m.focusedLink]; l.External {
+				path = l.Href
+			}
+			cmds = append(cmds, m.copyContents(path, "Copied link path"))
+
+		case actionCopyLinks:
+			if len(m.links) == 0 {
+				cmds = append(cmds, m.showStatusMessage(pagerStatusMessage{"No links to copy", false}))
+				break
+			}
+			list := formatLinkList(m.links, m.common.cfg.LinkListFormat)
+			cmds = append(cmds, m.copyContents(list, fmt.Sprintf("Copied %d links", len(m.links))))
+
+		case actionReload:
+			if fileExists(m.currentDocument.localPath) {
+				m.fileDeleted = false
+			}
+			return m, loadLocalMarkdown(&m.currentDocument, m.common.cfg.Encoding)
+
+		case actionOpen:
+			if m.currentDocument.binary && m.currentDocument.localPath != "" {
+				return m, openExternally(m.currentDocument.localPath)
+			}
+
+		case actionRelated:
+			if m.currentDocument.localPath == "" {
+				break
+			}
+			m.openOverlay(&listOverlay{
+				kind:  overlayRelatedDocs,
+				title: "Related documents",
+				items: relatedDocs(m.common.cwd, m.currentDocument.localPath, m.links),
+			})
+			return m, nil
+
+		case actionRecent:
+			return m, m.openRecent()
+
+		case actionBookmark:
+			return m, m.addBookmark()
+
+		case actionBookmarks:
+			return m, m.openBookmarks()
+
+		case actionSelect:
+			return m, m.startSelection()
+
+		case actionCompact:
+			return m, m.toggleCompact()
+
+		case actionStats:
+			local := 0
+			for _, l := range m.links {
+				if !l.External {
+					local++
+				}
+			}
+			m.openOverlay(&listOverlay{
+				kind:  overlayStats,
+				title: "Statistics: " + m.currentDocument.Note,
+				items: statsOverlayItems(computeStats(m.renderedMarkdown(m.currentDocument.Body), local)),
+			})
+			return m, nil
+
+		case actionTable:
+			return m, m.openTable()
+
+		case actionReloadConfig:
+			return m, m.reloadConfig()
+
+		case actionContents:
+			if len(m.headings) == 0 {
+				return m, m.showStatusMessage(pagerStatusMessage{"No headings", false})
+			}
+			m.openOverlay(&listOverlay{
+				kind:   overlayTOC,
+				title:  "Contents: " + m.currentDocument.Note,
+				items:  tocOverlayItems(m.headings),
+				cursor: headingAt(m.headings, m.viewport.YOffset),
+			})
+			return m, nil
+
+		case actionLinkHints:
+			return m, m.startLinkHints()
+
+		case actionRelativeNumbers:
+			return m, m.toggleRelativeNumbers()
+
+		case actionRaw:
+			return m, m.toggleRaw()
+
+		case actionReloadStyle:
+			return m, m.reloadStyle()
+
+		case actionFrontMatter:
+			return m, m.toggleFrontMatter()
+
+		case actionFootnote:
+			return m, m.jumpToFootnote()
+
+		case actionWatch:
+			return m, m.toggleWatching()
+
+		case actionReadingTime:
+			return m, m.toggleReadingTime()
+
+		case actionLinePosition:
+			return m, m.toggleLinePosition()
+
+		case actionScrollLeft:
+			return m, m.scrollSideways(-horizontalScrollStep)
+
+		case actionScrollRight:
+			return m, m.scrollSideways(horizontalScrollStep)
+
+		case actionLinks:
+			if len(m.links) == 0 {
+				return m, m.showStatusMessage(pagerStatusMessage{"No followable links", false})
+			}
+			m.openOverlay(&listOverlay{
+				kind:   overlayLinks,
+				title:  "Links: " + m.currentDocument.Note,
+				items:  linkOverlayItems(m.links),
+				cursor: max(0, m.focusedLink),
+			})
+			return m, nil
+
+		case actionFollow:
+			m.follow = !m.follow
+			msg := "Follow on"
+			if !m.follow {
+				msg = "Follow off"
+			}
+			cmds = append(cmds, m.showStatusMessage(pagerStatusMessage{msg, false}))
+
+		case actionNextMatch:
+			return m, m.nextMatch(1)
+		case actionPrevMatch:
+			return m, m.nextMatch(-1)
+
+		case actionNextCodeBlock:
+			return m, m.jumpToCodeBlock(1)
+		case actionPrevCodeBlock:
+			return m, m.jumpToCodeBlock(-1)
+
+		case actionLinkDirectory:
+			if m.focusedLink < 0 || m.focusedLink >= len(m.links) {
+				cmds = append(cmds, m.showStatusMessage(pagerStatusMessage{"Tab to select a link", false}))
+				break
+			}
+			if m.links[m.focusedLink].External {
+				cmds = append(cmds, m.showStatusMessage(pagerStatusMessage{"Not a local file", false}))
+				break
+			}
+			target := m.links[m.focusedLink].ResolvedPath
+			items, cursor, err := directoryListing(m.common.cwd, target)
+			if err != nil {
+				log.Debug("error listing link directory", "error", err)
+				cmds = append(cmds, m.showStatusMessage(pagerStatusMessage{"Couldn't list " + filepath.Dir(target), true}))
+				break
+			}
+			m.openOverlay(&listOverlay{
+				kind:   overlayDirectory,
+				title:  stripAbsolutePath(filepath.Dir(target), m.common.cwd) + string(filepath.Separator),
+				items:  items,
+				cursor: max(0, cursor),
+			})
+			return m, nil
+
+		case actionPresent:
+			return m, m.togglePresentation()
+
+		case actionWrap:
+			return m, m.toggleWrap()
+
+		case actionDefinitions:
+			return m, m.startDefinitions()
+
+		case actionLinkGraph:
+			if m.currentDocument.localPath == "" || m.common.cwd == "" {
+				break
+			}
+			return m, buildLinkGraph(m.common.cwd, m.currentDocument.localPath, m.currentDocument.Note,
+				int(m.common.cfg.LinkGraphDepth), m.common.cfg.Encoding) //nolint:gosec
+
+		case actionSearch:
+			return m, m.startSearch()
+
+		case actionCommand:
+			return m, m.startCommandPrompt()
+
+		case actionNextBrokenLink:
+			return m, m.focusNextBrokenLink()
+
+		case actionFirstVisibleLink:
+			return m, m.focusFirstVisibleLink()
+
+		case actionBlame:
+			if !m.common.cfg.GitBlame {
+				return m, m.showStatusMessage(pagerStatusMessage{"Git blame is turned off, see gitBlame in the config", false})
+			}
+			if m.currentDocument.localPath == "" {
+				return m, m.showStatusMessage(pagerStatusMessage{"Only local files can be blamed", false})
+			}
+			m.blameLine = m.currentLine()
+			return m, gitBlame(m.currentDocument.localPath)
+
+		case actionZen:
+			return m, m.toggleZen()
+
+		case actionHelp:
+			m.nextHelp()
 			if m.common != nil && m.common.cfg.HighPerformancePager {
 				cmds = append(cmds, viewport.Sync(m.viewport))
 			}
 		}
 
+	case tea.MouseMsg:
+		// As does the mouse, so the wheel scrolls on from where a smooth
+		// scroll was going.
+		m.finishSmoothScroll()
+
+		if cmd, done := m.updateMouse(msg); done {
+			return m, cmd
+		}
+
 	case errMsg:
 		m.pendingRestoreYOffset = nil
 		cmds = append(cmds, m.showStatusMessage(pagerStatusMessage{msg.Error(), true}))
@@ -310,7 +1121,41 @@ func (m pagerModel) update(msg tea.Msg) (pagerModel, tea.Cmd) {
 	case contentRenderedMsg:
 		log.Info("content rendered", "state", m.state)
 
+		previous := m.rendered
 		m.rendered = string(msg)
+		m.flashLine = -1
+		m.mouseSelection = nil
+		var searchCmd tea.Cmd
+		if m.searchPattern != nil {
+			// Offsets of previous matches are meaningless now.
+			searchCmd = m.refreshSearch(previous)
+		}
+		if utils.IsMarkdownFile(m.currentDocument.Note) {
+			included := m.includedMarkdown(m.currentDocument.Body)
+			source := m.renderedMarkdown(m.currentDocument.Body)
+			m.headings = documentHeadings(source, m.rendered, m.common.cfg.DuplicateHeadingSlugs)
+			if source != included {
+				shortcodeSlugs(m.headings, included, m.common.cfg.DuplicateHeadingSlugs)
+			}
+			m.codeBlocks = extractCodeBlocks(source)
+			locateCodeBlocks(m.rendered, m.codeBlocks)
+			m.tables = extractTables(source)
+			locateTables(m.rendered, m.tables)
+			m.footnotes = extractFootnotes(source)
+			locateFootnotes(m.rendered, m.footnotes)
+			m.countDocumentWords()
+		} else {
+			m.headings = nil
+			m.codeBlocks = nil
+			m.tables = nil
+			m.footnotes = nil
+			m.words = 0
+		}
+		if m.viewport.YPosition != m.frontMatterHeight() {
+			// Make room for the front matter of the document, or give it
+			// back.
+			m.setSize(m.common.width, m.common.height)
+		}
 		m.applyRenderedContent()
 		if m.pendingRestoreYOffset != nil {
 			m.viewport.YOffset = *m.pendingRestoreYOffset
@@ -319,20 +1164,164 @@ func (m pagerModel) update(msg tea.Msg) (pagerModel, tea.Cmd) {
 			}
 			m.pendingRestoreYOffset = nil
 		}
+		if m.pendingAnchor != nil {
+			if line := m.anchorLine(*m.pendingAnchor); line >= 0 {
+				m.viewport.SetYOffset(line)
+			}
+			m.pendingAnchor = nil
+		}
+		if m.pendingSourceLine > 0 {
+			if line := sourceLineOffset(m.renderedSourceLines(), m.pendingSourceLine); line >= 0 {
+				m.viewport.SetYOffset(line)
+			}
+			m.pendingSourceLine = 0
+		}
+		if m.pendingFragment != "" {
+			cmds = append(cmds, m.jumpToFragment(m.pendingFragment))
+			m.pendingFragment = ""
+		}
+		if m.reloading && m.follow {
+			// Like tail -f, show whatever was appended.
+			m.viewport.GotoBottom()
+		}
 		if m.common != nil && m.common.cfg.HighPerformancePager {
 			cmds = append(cmds, viewport.Sync(m.viewport))
 		}
-		cmds = append(cmds, m.startWatching())
+		if m.presenting {
+			cmds = append(cmds, renderSlides(m, splitSlides(m.renderedMarkdown(m.currentDocument.Body), m.common.cfg.SlideSeparator)))
+		}
+		if string(msg) == emptyDocumentNotice {
+			cmds = append(cmds, m.showStatusMessage(pagerStatusMessage{"Document is empty", false}))
+		}
+		wasReloading := m.reloading
+		if m.reloading {
+			m.reloading = false
+			switch {
+			case m.focusedLinkGone:
+				m.focusedLinkGone = false
+				cmds = append(cmds, m.showStatusMessage(pagerStatusMessage{"⟳ reloaded, the focused link is gone", false}))
+			case m.common.cfg.ReloadIndicator:
+				cmds = append(cmds, m.showStatusMessage(pagerStatusMessage{"⟳ reloaded", false}))
+			}
+		}
+		// Look at the git state again when there's a new file or it may have
+		// changed.
+		if m.gitStatusPath != m.currentDocument.localPath || wasReloading || m.ownWrite != "" {
+			cmds = append(cmds, m.checkGitStatus())
+		}
+		cmds = append(cmds, m.warningsCmd())
+		// Opening or reloading a document counts as activity.
+		m.lastActivity = time.Now()
+		cmds = append(cmds, searchCmd, m.startWatching(), m.scheduleIdleCheck(m.common.cfg.WatchIdleTimeout))
+
+	case idleCheckMsg:
+		return m, m.checkIdle()
+
+	case styleFallbackMsg:
+		if msg.style == m.common.cfg.GlamourStylePath {
+			// Keep trying the style file, as it's probably being edited.
+			cmds = append(cmds, m.showStatusMessage(pagerStatusMessage{
+				fmt.Sprintf("Couldn't load style file “%s”, using “%s”", msg.style, m.common.cfg.GlamourStyle), true,
+			}))
+			break
+		}
+		// Stick with the fallback so we don't run into the same problem
+		// every time we render.
+		m.common.cfg.GlamourStyle = fallbackGlamourStyle
+		cmds = append(cmds, m.showStatusMessage(pagerStatusMessage{
+			fmt.Sprintf("Couldn't load style “%s”, using the default", msg.style), true,
+		}))
+
+	case smoothScrollMsg:
+		cmds = append(cmds, m.stepSmoothScroll(msg))
+
+	case viewportSyncMsg:
+		m.syncPending = false
+		cmds = append(cmds, viewport.Sync(m.viewport))
+
+	case gitStatusMsg:
+		m.gitStatus = msg.status
+		m.gitStatusPath = msg.path
+
+	case blameMsg:
+		if msg.err != nil {
+			cmds = append(cmds, m.showStatusMessage(pagerStatusMessage{"Blame unavailable: " + msg.err.Error(), true}))
+			break
+		}
+		m.openOverlay(&listOverlay{
+			kind:   overlayBlame,
+			title:  "Blame: " + m.currentDocument.Note,
+			items:  blameOverlayItems(msg.lines),
+			cursor: max(0, min(len(msg.lines)-1, m.blameLine-1)),
+		})
+
+	case linkGraphMsg:
+		m.openOverlay(&listOverlay{
+			kind:  overlayLinkGraph,
+			title: "Link graph",
+			items: msg,
+		})
+
+	case slidesRenderedMsg:
+		if m.presenting {
+			m.slides = msg
+			cmds = append(cmds, m.showSlide(m.slide))
+		}
 
 	// The file was changed on disk and we're reloading it
 	case reloadMsg:
-		return m, loadLocalMarkdown(&m.currentDocument)
+		if m.isOwnWrite() {
+			return m, m.startWatching()
+		}
+		if m.currentDocument.localPath != "" && !fileExists(m.currentDocument.localPath) {
+			return m, m.fileDeletedCmd()
+		}
+		m.fileDeleted = false
+		m.reloading = true
+		m.common.wikiIndex.reset()
+		return m, loadLocalMarkdown(&m.currentDocument, m.common.cfg.Encoding)
+
+	case includeChangedMsg:
+		if m.fileDeleted {
+			return m, m.startWatching()
+		}
+		m.reloading = true
+		m.common.wikiIndex.reset()
+		return m, loadLocalMarkdown(&m.currentDocument, m.common.cfg.Encoding)
+
+	// Keep watching the directory, so that we see the file being created
+	// again.
+	case fileRemovedMsg:
+		return m, tea.Batch(
+			m.startWatching(),
+			tea.Tick(fileRemovalGrace, func(time.Time) tea.Msg {
+				return fileRemovalCheckMsg{}
+			}),
+		)
+
+	case fileRemovalCheckMsg:
+		// If it's back, it was reloaded when it was created.
+		if !fileExists(m.currentDocument.localPath) && !m.fileDeleted {
+			return m, m.fileDeletedCmd()
+		}
 
 	// We've finished editing the document, potentially making changes. Let's
 	// retrieve the latest version of the document so that we display
 	// up-to-date contents.
 	case editorFinishedMsg:
-		return m, loadLocalMarkdown(&m.currentDocument)
+		return m, loadLocalMarkdown(&m.currentDocument, m.common.cfg.Encoding)
+
+	case externalOpenMsg:
+		if msg.err != nil {
+			cmds = append(cmds, m.showStatusMessage(pagerStatusMessage{"Couldn't open file: " + msg.err.Error(), true}))
+		}
+
+	case browserOpenMsg:
+		if msg.err != nil {
+			cmds = append(cmds, m.showStatusMessage(pagerStatusMessage{"Couldn't open browser: " + msg.err.Error(), true}))
+		} else {
+			cmds = append(cmds, m.showStatusMessage(pagerStatusMessage{"Opened in browser", false}))
+		}
 
 	// We've received terminal dimensions, either for the first time or
 	// after a resize
@@ -341,6 +1330,20 @@ func (m pagerModel) update(msg tea.Msg) (pagerModel, tea.Cmd) {
 
 	case statusMessageTimeoutMsg:
 		m.state = pagerStateBrowse
+		if m.flashLine >= 0 {
+			m.flashLine = -1
+			m.applyRenderedContent()
+		}
+	}
+
+	if m.searching {
+		// Keep the prompt's cursor blinking.
+		m.searchInput, cmd = m.searchInput.Update(msg)
+		cmds = append(cmds, cmd)
+	}
+	if m.promptingCommand {
+		m.commandInput, cmd = m.commandInput.Update(msg)
+		cmds = append(cmds, cmd)
 	}
 
 	m.viewport, cmd = m.viewport.Update(msg)
@@ -349,12 +1352,45 @@ func (m pagerModel) update(msg tea.Msg) (pagerModel, tea.Cmd) {
 	return m, tea.Batch(cmds...)
 }
 
+// currentLine returns the approximate line of the document at the top of the
+// viewport, as used when opening the editor.
+func (m pagerModel) currentLine() int {
+	if m.viewport.AtTop() {
+		return 0
+	}
+	return int(math.RoundToEven(float64(m.viewport.TotalLineCount()) * m.viewport.ScrollPercent()))
+}
+
 func (m pagerModel) View() string {
 	var b strings.Builder
-	fmt.Fprint(&b, m.viewport.View()+"\n")
+	b.WriteString(m.frontMatterView())
+
+	var content string
+	if m.overlay != nil {
+		content = m.overlay.view(m.viewport.Width, m.viewport.Height)
+	} else if m.table != nil {
+		content = m.table.view(m.viewport.Width, m.viewport.Height)
+	} else {
+		content = m.definitionView(m.viewport.View())
+		if m.zen != nil && m.common.cfg.ZenDimming {
+			content = dimUnfocusedLines(content, m.viewport.Height)
+		}
+	}
+	fmt.Fprint(&b, indentLines(content, m.contentMargin())+"\n")
 
 	// Footer
-	m.statusBarView(&b)
+	if m.searching {
+		m.searchBarView(&b)
+	} else if m.promptingCommand {
+		s := m.commandInput.View()
+		fmt.Fprint(&b, s+strings.Repeat(" ", max(0, m.common.width-ansi.PrintableRuneWidth(s))))
+	} else if m.zen != nil && m.state != pagerStateStatusMessage {
+		// Keep the line so the layout doesn't jump when status messages
+		// show up.
+		fmt.Fprint(&b, strings.Repeat(" ", m.common.width))
+	} else {
+		m.statusBarView(&b)
+	}
 
 	if m.showHelp {
 		fmt.Fprint(&b, "\n"+m.helpView())
@@ -363,6 +1399,30 @@ func (m pagerModel) View() string {
 	return b.String()
 }
 
+// breadcrumbSeparator goes between the documents of the breadcrumb.
+const breadcrumbSeparator = " > "
+
+// breadcrumb returns the documents we followed links through to get to the
+// current one, like index.md > api.md > auth.md. Documents are left out from
+// the start to fit the given width, but the current one is always there.
+func (m pagerModel) breadcrumb(width int) string {
+	crumbs := make([]string, 0, len(m.history)+1)
+	for _, e := range m.history {
+		note := stripAbsolutePath(e.Path, m.common.cwd)
+		if note == "" {
+			note = filepath.Base(e.Path)
+		}
+		crumbs = append(crumbs, note)
+	}
+	crumbs = append(crumbs, m.currentDocument.Note)
+
+	s := strings.Join(crumbs, breadcrumbSeparator)
+	for i := 1; i < len(crumbs) && ansi.PrintableRuneWidth(s) > width; i++ {
+		s = strings.Join(append([]string{ellipsis}, crumbs[i:]...), breadcrumbSeparator)
+	}
+	return s
+}
+
 func (m pagerModel) statusBarView(b *strings.Builder) {
 	const (
 		minPercent               float64 = 0.0
@@ -377,7 +1437,51 @@ func (m pagerModel) statusBarView(b *strings.Builder) {
 
 	// Scroll percent
 	percent := math.Max(minPercent, math.Min(maxPercent, m.viewport.ScrollPercent()))
-	scrollPercent := fmt.Sprintf(" %3.f%% ", percent*percentToStringMagnitude)
+	percent = math.Round(percent * percentToStringMagnitude)
+	// Only show 0% and 100% at the very ends, like less, so rounding
+	// doesn't suggest there's nothing left to read.
+	switch {
+	case m.viewport.AtBottom() || m.viewport.PastBottom():
+		percent = percentToStringMagnitude
+	case m.viewport.AtTop():
+		percent = 0
+	default:
+		percent = math.Max(1, math.Min(percentToStringMagnitude-1, percent))
+	}
+	scrollPercent := fmt.Sprintf(" %3.f%% ", percent)
+	if pos := m.linePositionView(); pos != "" {
+		scrollPercent = pos + scrollPercent
+	}
+	if m.xOffset > 0 {
+		scrollPercent = fmt.Sprintf(" col %d", m.xOffset+1) + scrollPercent
+	}
+	if m.noWrap {
+		scrollPercent = " nowrap" + scrollPercent
+	}
+	if m.currentDocument.raw {
+		scrollPercent = " raw" + scrollPercent
+	}
+	if m.fileDeleted {
+		scrollPercent = " deleted" + scrollPercent
+	}
+	if m.watchPaused {
+		scrollPercent = " nowatch" + scrollPercent
+	}
+	gitIndicator := m.gitStatusView(statusBarBg)
+	if showStatusMessage {
+		gitIndicator = m.gitStatusView(darkGreen)
+	}
+
+	// The reading time gives way when it would crowd out the note.
+	if reading := m.readingTimeView(); reading != "" {
+		noteWidth := m.common.width - ansi.PrintableRuneWidth(logo+gitIndicator+reading+scrollPercent+" ? Help ")
+		if noteWidth >= readingTimeMinNoteWidth {
+			scrollPercent = reading + scrollPercent
+		}
+	}
+	if m.presenting {
+		scrollPercent = " " + m.slideIndicator() + " "
+	}
 	if showStatusMessage {
 		scrollPercent = statusBarMessageScrollPosStyle(scrollPercent)
 	} else {
@@ -393,18 +1497,18 @@ func (m pagerModel) statusBarView(b *strings.Builder) {
 	}
 
 	// Note
+	noteWidth := max(0, m.common.width-
+		ansi.PrintableRuneWidth(logo)-
+		ansi.PrintableRuneWidth(gitIndicator)-
+		ansi.PrintableRuneWidth(scrollPercent)-
+		ansi.PrintableRuneWidth(helpNote))
 	var note string
 	if showStatusMessage {
 		note = m.statusMessage
 	} else {
-		note = m.currentDocument.Note
+		note = m.breadcrumb(noteWidth - 2)
 	}
-	note = truncate.StringWithTail(" "+note+" ", uint(max(0, //nolint:gosec
-		m.common.width-
-			ansi.PrintableRuneWidth(logo)-
-			ansi.PrintableRuneWidth(scrollPercent)-
-			ansi.PrintableRuneWidth(helpNote),
-	)), ellipsis)
+	note = truncate.StringWithTail(" "+note+" ", uint(noteWidth), ellipsis) //nolint:gosec
 	if showStatusMessage {
 		note = statusBarMessageStyle(note)
 	} else {
@@ -416,6 +1520,7 @@ func (m pagerModel) statusBarView(b *strings.Builder) {
 		m.common.width-
 			ansi.PrintableRuneWidth(logo)-
 			ansi.PrintableRuneWidth(note)-
+			ansi.PrintableRuneWidth(gitIndicator)-
 			ansi.PrintableRuneWidth(scrollPercent)-
 			ansi.PrintableRuneWidth(helpNote),
 	)
@@ -426,48 +1531,123 @@ func (m pagerModel) statusBarView(b *strings.Builder) {
 		emptySpace = statusBarNoteStyle(emptySpace)
 	}
 
-	fmt.Fprintf(b, "%s%s%s%s%s",
+	fmt.Fprintf(b, "%s%s%s%s%s%s",
 		logo,
 		note,
 		emptySpace,
+		gitIndicator,
 		scrollPercent,
 		helpNote,
 	)
 }
 
-func (m pagerModel) helpView() (s string) {
-	rows := [][2]string{
-		{"k/↑      up", "g/home  go to top"},
-		{"j/↓      down", "G/end   go to bottom"},
-		{"b/pgup   page up", "tab     next link"},
-		{"f/pgdn   page down", "⇧tab    prev link"},
-		{"u        ½ page up", "enter   follow link"},
-		{"d        ½ page down", "⌫       go back"},
-		{"", "c       copy contents"},
-		{"", "e       edit this document"},
-		{"", "r       reload this document"},
-		{"", "esc     back to files"},
-		{"", "q       quit"},
-	}
-
-	s += "\n"
+// helpIndent is how far the help is indented.
+const helpIndent = 2
+
+// helpContentWidth returns how wide the help is. It's capped if configured
+// to be, and centered in the remaining space.
+func (m pagerModel) helpContentWidth() int {
+	width := m.common.width
+	if maxWidth := int(m.common.cfg.HelpMaxWidth); maxWidth > 0 && (width == 0 || width > maxWidth) { //nolint:gosec
+		width = maxWidth
+	}
+	return width
+}
+
+// helpRows returns the lines of the help, listing the key bindings in two
+// columns, or one after the other if there's no room for that.
+func (m pagerModel) helpRows() []string {
+	var hidden []keyAction
+	if !m.common.cfg.GitBlame {
+		hidden = append(hidden, actionBlame)
+	}
+	columns := m.keys.helpColumns(hidden...)
+
+	// Keys line up in each column, however long they are.
+	rows := make([][2]string, max(len(columns[0]), len(columns[1])))
+	for col, entries := range columns {
+		keyWidth := 8 - col
+		for _, e := range entries {
+			keyWidth = max(keyWidth, runewidth.StringWidth(e.key))
+		}
+		for i, e := range entries {
+			pad := strings.Repeat(" ", keyWidth-runewidth.StringWidth(e.key))
+			rows[i][col] = e.key + pad + " " + e.val
+		}
+	}
+
+	const helpColumnWidth = 24
+
+	contentWidth := m.helpContentWidth()
+	leftWidth, rightWidth := helpColumnWidth, 0
 	for _, row := range rows {
-		left := row[0]
-		right := row[1]
-		if left != "" {
-			left = fmt.Sprintf("%-24s", left)
-		} else {
-			left = strings.Repeat(" ", 24)
+		leftWidth = max(leftWidth, runewidth.StringWidth(row[0])+1)
+		rightWidth = max(rightWidth, runewidth.StringWidth(row[1]))
+	}
+	twoColumns := contentWidth == 0 || contentWidth >= helpIndent+leftWidth+rightWidth
+
+	lines := make([]string, 0, 2*len(rows))
+	if twoColumns {
+		for _, row := range rows {
+			left := row[0] + strings.Repeat(" ", leftWidth-runewidth.StringWidth(row[0]))
+			lines = append(lines, left+row[1])
+		}
+	} else {
+		// Not enough room for two columns, so stack them.
+		for col := range 2 {
+			for _, row := range rows {
+				if row[col] != "" {
+					lines = append(lines, row[col])
+				}
+			}
 		}
-		s += left + right + "\n"
 	}
+	return lines
+}
 
-	s = indent(s, 2)
+// helpPageSize returns how many lines of the help fit on a page. The help
+// takes up at most half of the window, so there's room left for the
+// document, or it all fits if the window's size isn't known.
+func (m pagerModel) helpPageSize() int {
+	if m.common.height <= 0 {
+		return 0
+	}
+	// Leave room for the blank lines around the help and the page line.
+	return max(1, m.common.height/2-4)
+}
+
+// helpPages returns how many pages the help is shown in.
+func (m pagerModel) helpPages() int {
+	size, n := m.helpPageSize(), len(m.helpRows())
+	if size == 0 || n <= size {
+		return 1
+	}
+	return (n + size - 1) / size
+}
+
+func (m pagerModel) helpView() (s string) {
+	lines := m.helpRows()
+	if pages := m.helpPages(); pages > 1 {
+		// Every page is as tall as the first, so the layout doesn't jump.
+		size := m.helpPageSize()
+		page := min(m.helpPage, pages-1)
+		lines = lines[page*size : min(len(lines), (page+1)*size)]
+		lines = append(lines, make([]string, size-len(lines))...)
+
+		next := "for more"
+		if page == pages-1 {
+			next = "to close"
+		}
+		lines = append(lines, fmt.Sprintf("page %d/%d, %s %s", page+1, pages, m.keys.help(actionHelp), next))
+	}
+	s = indent("\n"+strings.Join(lines, "\n")+"\n", helpIndent)
 
 	// Fill up empty cells with spaces for background coloring
 	if m.common.width > 0 {
+		margin := strings.Repeat(" ", max(0, m.common.width-m.helpContentWidth())/2)
 		lines := strings.Split(s, "\n")
 		for i := 0; i < len(lines); i++ {
+			lines[i] = margin + lines[i]
 			l := runewidth.StringWidth(lines[i])
 			n := max(m.common.width-l, 0)
 			lines[i] += strings.Repeat(" ", n)
@@ -484,17 +1664,58 @@ func (m pagerModel) helpView() (s string) {
 func renderWithGlamour(m pagerModel, md string) tea.Cmd {
 	return func() tea.Msg {
 		s, err := glamourRender(m, md)
+
+		// A typo in the style shouldn't keep us from showing anything, so
+		// fall back from the style file to the named style, and from that
+		// to the default.
+		var (
+			styleErr  *styleError
+			fallbacks []tea.Cmd
+		)
+		for errors.As(err, &styleErr) && styleErr.style != fallbackGlamourStyle {
+			log.Error("error loading style, falling back", "style", styleErr.style, "error", styleErr.err)
+			common := *m.common
+			if common.cfg.GlamourStylePath != "" {
+				common.cfg.GlamourStylePath = ""
+			} else {
+				common.cfg.GlamourStyle = fallbackGlamourStyle
+			}
+			m.common = &common
+
+			style := styleErr.style
+			fallbacks = append(fallbacks, func() tea.Msg { return styleFallbackMsg{style} })
+			s, err = glamourRender(m, md)
+		}
 		if err != nil {
 			log.Error("error rendering with Glamour", "error", err)
 			return errMsg{err}
 		}
+		if len(fallbacks) > 0 {
+			return tea.BatchMsg(append(fallbacks, func() tea.Msg { return contentRenderedMsg(s) }))
+		}
 		return contentRenderedMsg(s)
 	}
 }
 
+// emptyDocumentNotice is shown in place of documents with nothing but
+// whitespace in them, which would otherwise render as a blank viewport.
+const emptyDocumentNotice = "\n  (empty document)\n"
+
+func isEmptyDocument(markdown string) bool {
+	return strings.TrimSpace(markdown) == ""
+}
+
 // This is where the magic happens.
 func glamourRender(m pagerModel, markdown string) (string, error) {
-	trunc := lipgloss.NewStyle().MaxWidth(m.viewport.Width - lineNumberWidth).Render
+	if m.currentDocument.binary {
+		return binaryNotice(m.keys), nil
+	}
+	if isEmptyDocument(markdown) {
+		return emptyDocumentNotice, nil
+	}
+	if m.currentDocument.raw {
+		return markdown, nil
+	}
 
 	if !config.GlamourEnabled {
 		return markdown, nil
@@ -502,13 +1723,34 @@ func glamourRender(m pagerModel, markdown string) (string, error) {
 
 	isCode := !utils.IsMarkdownFile(m.currentDocument.Note)
 	width := max(0, min(int(m.common.cfg.GlamourMaxWidth), m.viewport.Width)) //nolint:gosec
-	if isCode {
+	if isCode || m.noWrap {
 		width = 0
 	}
 
+	// Heading indicators get a column of their own, after the line numbers
+	// if those are shown, which takes away from the space available to the
+	// document.
+	indicator := m.common.cfg.HeadingGutterIndicator
+	if isCode {
+		indicator = ""
+	}
+	indicatorWidth := 0
+	if indicator != "" {
+		indicatorWidth = headingGutterWidth(indicator)
+		width = max(0, min(width, m.viewport.Width-indicatorWidth))
+	}
+	// Lines that are still too wide, like those of tables and code, can be
+	// scrolled to sideways, so only leave room for line numbers here.
+	if m.common.cfg.ShowLineNumbers && width > 0 {
+		width = max(1, min(width, m.viewport.Width-lineNumberWidth-indicatorWidth))
+	}
+
 	options := []glamour.TermRendererOption{
-		utils.GlamourStyle(m.common.cfg.GlamourStyle, isCode),
+		glamourStyle(m.common.cfg, isCode),
 		glamour.WithWordWrap(width),
+		// Glamour assumes true color otherwise, which comes out wrong on
+		// terminals with fewer colors.
+		glamour.WithColorProfile(colorProfile(m.common.cfg.ColorDepth)),
 	}
 
 	if m.common.cfg.PreserveNewLines {
@@ -516,11 +1758,14 @@ func glamourRender(m pagerModel, markdown string) (string, error) {
 	}
 	r, err := glamour.NewTermRenderer(options...)
 	if err != nil {
-		return "", fmt.Errorf("error creating glamour renderer: %w", err)
+		// Options other than the style can't fail.
+		return "", fmt.Errorf("error creating glamour renderer: %w", &styleError{styleName(m.common.cfg), err})
 	}
 
 	if isCode {
 		markdown = utils.WrapCodeBlock(markdown, filepath.Ext(m.currentDocument.Note))
+	} else {
+		markdown = m.renderedMarkdown(markdown)
 	}
 
 	out, err := r.Render(markdown)
@@ -530,16 +1775,45 @@ func glamourRender(m pagerModel, markdown string) (string, error) {
 
 	if isCode {
 		out = strings.TrimSpace(out)
+	} else if m.compact {
+		out = compactBlankLines(markdown, out)
+	}
+	if !isCode && m.common.cfg.CodeBlockLabels {
+		out = labelCodeBlocks(markdown, out)
+	}
+
+	var headingLines map[int]bool
+	if indicator != "" {
+		headingLines = renderedHeadingLines(markdown, out)
 	}
 
 	// trim lines
 	lines := strings.Split(out, "\n")
+	first := m.currentDocument.bodyOffset + 1
 
 	var content strings.Builder
 	for i, s := range lines {
-		if isCode || m.common.cfg.ShowLineNumbers {
-			content.WriteString(lineNumberStyle(fmt.Sprintf("%"+fmt.Sprint(lineNumberWidth)+"d", i+1)))
-			content.WriteString(trunc(s))
+		if isCode && m.common.cfg.CodeWrapWidth > 0 {
+			// Wrap long lines at a fixed column. Continuation lines get an
+			// empty gutter so line numbers keep matching the source.
+			for j, segment := range wrapCodeLine(s, int(m.common.cfg.CodeWrapWidth)) { //nolint:gosec
+				if j > 0 {
+					content.WriteString("\n" + strings.Repeat(" ", lineNumberWidth))
+				} else {
+					content.WriteString(lineNumberStyle(fmt.Sprintf("%"+fmt.Sprint(lineNumberWidth)+"d", first+i)))
+				}
+				content.WriteString(segment)
+			}
+		} else if isCode || m.common.cfg.ShowLineNumbers {
+			gutter := fmt.Sprintf("%"+fmt.Sprint(lineNumberWidth)+"d", first+i)
+			if indicator != "" {
+				gutter += headingGutter(indicator, headingLines[i])
+			}
+			content.WriteString(lineNumberStyle(gutter))
+			content.WriteString(s)
+		} else if indicator != "" {
+			content.WriteString(lineNumberStyle(headingGutter(indicator, headingLines[i])))
+			content.WriteString(s)
 		} else {
 			content.WriteString(s)
 		}
@@ -553,6 +1827,89 @@ func glamourRender(m pagerModel, markdown string) (string, error) {
 	return content.String(), nil
 }
 
+// includedMarkdown returns a markdown document with the files it includes
+// spliced in, if includes are enabled.
+func (m pagerModel) includedMarkdown(markdown string) string {
+	if m.common.cfg.Includes && m.currentDocument.localPath != "" {
+		return expandIncludes(markdown, m.currentDocument.localPath, m.common.cwd)
+	}
+	return markdown
+}
+
+// renderedMarkdown returns the markdown of a document as it's rendered: with
+// the files it includes spliced in and emoji shortcodes expanded, if those
+// are enabled. What's looked for in the rendered output, like headings, is
+// taken from it too.
+func (m pagerModel) renderedMarkdown(markdown string) string {
+	markdown = m.includedMarkdown(markdown)
+	if m.common.cfg.RenderEmoji {
+		markdown = expandEmoji(markdown)
+	}
+	return markdown
+}
+
+// gutterWidth returns the width of the gutter glamourRender adds to every
+// line, if any.
+func (m pagerModel) gutterWidth() int {
+	if !config.GlamourEnabled || m.currentDocument.binary || m.currentDocument.raw {
+		return 0
+	}
+	if !utils.IsMarkdownFile(m.currentDocument.Note) {
+		return lineNumberWidth
+	}
+	width := 0
+	if m.common.cfg.ShowLineNumbers {
+		width = lineNumberWidth
+	}
+	if indicator := m.common.cfg.HeadingGutterIndicator; indicator != "" {
+		width += headingGutterWidth(indicator)
+	}
+	return width
+}
+
+// visibleText returns the given range of rendered lines as plain text.
+func visibleText(rendered string, from, height int) string {
+	lines := strings.Split(stripANSI(rendered), "\n")
+	from = max(0, min(from, len(lines)))
+	to := max(from, min(from+height, len(lines)))
+
+	out := make([]string, 0, to-from)
+	for _, l := range lines[from:to] {
+		out = append(out, strings.TrimRight(l, " "))
+	}
+	return strings.Join(out, "\n") + "\n"
+}
+
+// stripGutter removes the first width cells of every line of plain text.
+func stripGutter(s string, width int) string {
+	if width <= 0 {
+		return s
+	}
+	lines := strings.Split(s, "\n")
+	for i, l := range lines {
+		lines[i] = string([]rune(l)[min(width, utf8.RuneCountInString(l)):])
+	}
+	return strings.Join(lines, "\n")
+}
+
+// wrapCodeLine hard-wraps a rendered line of code at the given column.
+// Trailing whitespace is dropped so padding doesn't produce empty
+// continuation lines.
+func wrapCodeLine(s string, width int) []string {
+	if ansi.PrintableRuneWidth(s) <= width {
+		return []string{s}
+	}
+	w := wrap.NewWriter(width)
+	w.PreserveSpace = true
+	_, _ = w.Write([]byte(s))
+
+	segments := strings.Split(w.String(), "\n")
+	for len(segments) > 1 && strings.TrimSpace(stripANSI(segments[len(segments)-1])) == "" {
+		segments = segments[:len(segments)-1]
+	}
+	return segments
+}
+
 func (m *pagerModel) initWatcher() {
 	var err error
 	m.watcher, err = fsnotify.NewWatcher()
@@ -562,7 +1919,7 @@ func (m *pagerModel) initWatcher() {
 }
 
 func (m *pagerModel) startWatching() tea.Cmd {
-	if m.watcher == nil || m.currentDocument.localPath == "" {
+	if m.watcher == nil || m.currentDocument.localPath == "" || m.watchPaused {
 		return nil
 	}
 
@@ -576,31 +1933,75 @@ func (m *pagerModel) startWatching() tea.Cmd {
 	m.watchedDir = dir
 	m.watchCancel = make(chan struct{})
 
-	cancel := m.watchCancel
-	return func() tea.Msg { return m.watchFile(cancel) }
+	var includes map[string]bool
+	if m.common.cfg.Includes && m.common.cfg.WatchIncludes {
+		includes = m.watchIncludes()
+	}
+
+	cancel, debounce := m.watchCancel, m.common.cfg.WatchDebounce
+	return func() tea.Msg { return m.watchFile(cancel, debounce, includes) }
 }
 
-func (m *pagerModel) watchFile(cancel <-chan struct{}) tea.Msg {
+// watchFile waits for the file of the document, or one of the files it
+// includes, to change. Changes are reported once the files have been left
+// alone for the debounce time, so that a burst of writes only reloads the
+// document once.
+func (m *pagerModel) watchFile(cancel <-chan struct{}, debounce time.Duration, includes map[string]bool) tea.Msg {
 	log.Info("fsnotify watching dir", "dir", m.watchedDir)
 
+	var (
+		pending tea.Msg
+		timer   *time.Timer
+		fire    <-chan time.Time
+	)
+	defer func() {
+		if timer != nil {
+			timer.Stop()
+		}
+	}()
+
 	for {
 		select {
 		case <-cancel:
 			return nil
+		case <-fire:
+			return pending
 		case event, ok := <-m.watcher.Events:
 			if !ok {
 				return nil
 			}
-			if event.Name != m.currentDocument.localPath {
+			name := filepath.Clean(event.Name)
+			if name != filepath.Clean(m.currentDocument.localPath) && !includes[name] {
 				continue
 			}
 
-			if !event.Has(fsnotify.Write) && !event.Has(fsnotify.Create) {
+			switch {
+			case includes[name]:
+				// Included files that are gone are shown as missing.
+				if event.Op == fsnotify.Chmod {
+					continue
+				}
+				pending = includeChangedMsg{}
+			case event.Has(fsnotify.Write), event.Has(fsnotify.Create):
+				pending = reloadMsg{}
+			case event.Has(fsnotify.Remove), event.Has(fsnotify.Rename):
+				// Editors that save by renaming a new file over the old
+				// one make it look like it's gone for a moment.
+				pending = fileRemovedMsg{}
+			default:
 				continue
 			}
-
 			log.Debug("fsnotify event", "file", event.Name, "event", event.Op)
-			return reloadMsg{}
+
+			if debounce <= 0 {
+				return pending
+			}
+			if timer == nil {
+				timer = time.NewTimer(debounce)
+				fire = timer.C
+			} else {
+				timer.Reset(debounce)
+			}
 		case err, ok := <-m.watcher.Errors:
 			if !ok {
 				return nil
@@ -610,6 +2011,43 @@ func (m *pagerModel) watchFile(cancel <-chan struct{}) tea.Msg {
 	}
 }
 
+// toggleWatching turns reloading the document when it changes off and on
+// again. Changes made in the meantime are picked up when it's turned back
+// on.
+func (m *pagerModel) toggleWatching() tea.Cmd {
+	if m.currentDocument.localPath == "" {
+		return m.showStatusMessage(pagerStatusMessage{"Not a local file", false})
+	}
+	if !m.watchPaused {
+		m.watchPaused = true
+		m.pausedModTime = modTime(m.currentDocument.localPath)
+		m.stopWatching()
+		return m.showStatusMessage(pagerStatusMessage{"Watching off, r to reload", false})
+	}
+
+	m.watchPaused = false
+	status := m.showStatusMessage(pagerStatusMessage{"Watching on", false})
+	if modTime(m.currentDocument.localPath) != m.pausedModTime {
+		return tea.Batch(status, func() tea.Msg { return reloadMsg{} })
+	}
+	return tea.Batch(status, m.startWatching())
+}
+
+// fileDeletedCmd notes that the file of the document is gone, which we keep
+// showing until it's created again.
+func (m *pagerModel) fileDeletedCmd() tea.Cmd {
+	m.fileDeleted = true
+	return tea.Batch(
+		m.showStatusMessage(pagerStatusMessage{"File deleted", true}),
+		m.startWatching(),
+	)
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
 func (m *pagerModel) stopWatching() {
 	if m.watchCancel != nil {
 		close(m.watchCancel)
@@ -620,6 +2058,13 @@ func (m *pagerModel) stopWatching() {
 		return
 	}
 
+	for _, dir := range m.watchedIncludeDirs {
+		if err := m.watcher.Remove(dir); err != nil {
+			log.Debug("fsnotify fail to unwatch include dir", "dir", dir, "error", err)
+		}
+	}
+	m.watchedIncludeDirs = nil
+
 	err := m.watcher.Remove(m.watchedDir)
 	if err == nil {
 		log.Debug("fsnotify dir unwatched", "dir", m.watchedDir)
@@ -633,24 +2078,243 @@ func (m *pagerModel) localDir() string {
 	return filepath.Dir(m.currentDocument.localPath)
 }
 
+// extractLinks finds the followable and broken links of the current
+// document.
+func (m *pagerModel) extractLinks() {
+	m.links, m.brokenLinks = nil, nil
+	m.focusedLink, m.focusedBroken = -1, -1
+
+	doc := m.currentDocument
+	if doc.localPath == "" || m.common.cwd == "" {
+		return
+	}
+
+	links, err := documentLinks(m.common.cwd, doc.localPath, doc.Body, linkOptions{
+		web:    true,
+		editor: m.common.cfg.FollowNonMarkdownLinks,
+		wiki:   &m.common.wikiIndex,
+	})
+	if err != nil {
+		log.Debug("error extracting followable links", "error", err)
+	}
+	m.links = links
+
+	broken, err := brokenLinksForDocument(m.common.cwd, doc.localPath, doc.Body, &m.common.wikiIndex)
+	if err != nil {
+		log.Debug("error extracting broken links", "error", err)
+	}
+	m.brokenLinks = broken
+}
+
+// refocusLink focuses the link that's the same as the one that was focused,
+// at the given index, before the document was reloaded. Links that lead to
+// the same place and have the same label are preferred, then ones that only
+// lead to the same place or only have the same label, closest to where it
+// was. If it's gone, that's said once the document is rendered.
+func (m *pagerModel) refocusLink(prev followableLink, index int) {
+	best, bestScore := -1, 0
+	for i, l := range m.links {
+		score := 0
+		if l.Href == prev.Href && l.ResolvedPath == prev.ResolvedPath {
+			score += 2
+		}
+		if l.Label == prev.Label {
+			score++
+		}
+		if score == 0 || score < bestScore {
+			continue
+		}
+		if score > bestScore || max(i-index, index-i) < max(best-index, index-best) {
+			best, bestScore = i, score
+		}
+	}
+	m.focusedLink = best
+	m.focusedLinkGone = best < 0
+}
+
+// focusFirstVisibleLink focuses the first followable link in the viewport,
+// so that tabbing continues from what's on screen.
+func (m *pagerModel) focusFirstVisibleLink() tea.Cmd {
+	top, bottom := m.viewport.YOffset, m.viewport.YOffset+m.viewport.Height
+	for i, line := range linkLines(m.rendered, m.links) {
+		if line < top || line >= bottom {
+			continue
+		}
+		m.focusedBroken = -1
+		m.focusedLink = i
+		m.applyRenderedContent()
+		return m.showStatusMessage(pagerStatusMessage{m.links[i].focusMessage(), false})
+	}
+	return m.showStatusMessage(pagerStatusMessage{"No links in view", false})
+}
+
+// focusNextBrokenLink focuses the first broken link, or the next one if a
+// broken link is focused already, and scrolls it into view.
+func (m *pagerModel) focusNextBrokenLink() tea.Cmd {
+	if len(m.brokenLinks) == 0 {
+		return m.showStatusMessage(pagerStatusMessage{"No broken links", false})
+	}
+
+	m.focusedLink = -1
+	m.focusedBroken = (m.focusedBroken + 1) % len(m.brokenLinks)
+	m.applyRenderedContent()
+
+	var cmds []tea.Cmd
+	if line := linkLine(m.rendered, brokenLinkTargets(m.brokenLinks), m.focusedBroken); line >= 0 &&
+		(line < m.viewport.YOffset || line >= m.viewport.YOffset+m.viewport.Height) {
+		m.viewport.SetYOffset(line)
+		if m.common.cfg.HighPerformancePager {
+			cmds = append(cmds, viewport.Sync(m.viewport))
+		}
+	}
+
+	l := m.brokenLinks[m.focusedBroken]
+	cmds = append(cmds, m.showStatusMessage(pagerStatusMessage{
+		fmt.Sprintf("Broken link: %s (%s)", l.ResolvedNote, l.Reason), true,
+	}))
+	return tea.Batch(cmds...)
+}
+
+// What to do when following a link to a large file.
+const (
+	largeFileConfirm = "confirm"
+	largeFileRaw     = "raw"
+)
+
 func (m *pagerModel) followFocusedLink() tea.Cmd {
-	l := m.links[m.focusedLink]
+	return m.followLink(m.links[m.focusedLink])
+}
+
+// followLink opens the target of a resolved link, remembering the current
+// document so we can go back to it.
+func (m *pagerModel) followLink(l followableLink) tea.Cmd {
+	if l.External {
+		return openInBrowser(l.Href)
+	}
+	if l.Editor {
+		return openLinkInEditor(l, m.common.cfg.DuplicateHeadingSlugs)
+	}
 	if l.ResolvedPath == "" {
 		return nil
 	}
+	if m.isCurrentDocument(l.ResolvedPath) {
+		return m.followSelfLink(l.Fragment)
+	}
+
+	md := &markdown{localPath: l.ResolvedPath, Note: l.ResolvedNote}
+	var status tea.Cmd
+	if size, large := m.isLargeFile(l.ResolvedPath); large {
+		switch m.common.cfg.LargeFileAction {
+		case largeFileRaw:
+			md.raw = true
+			status = m.showStatusMessage(pagerStatusMessage{
+				fmt.Sprintf("%s is %s, showing it unrendered", l.ResolvedNote, humanize.Bytes(uint64(size))), false, //nolint:gosec
+			})
+		default:
+			if m.confirmLargeFile != l.ResolvedPath {
+				m.confirmLargeFile = l.ResolvedPath
+				return m.showStatusMessage(pagerStatusMessage{
+					fmt.Sprintf("%s is %s, press enter again to open it", l.ResolvedNote, humanize.Bytes(uint64(size))), false, //nolint:gosec
+				})
+			}
+		}
+	}
+	m.confirmLargeFile = ""
+
+	cmd := tea.Batch(m.navigateToDocument(md), status)
+	m.pendingFragment = l.Fragment
+	return cmd
+}
+
+// openLinkInEditor opens the target of a link in the editor, at the line its
+// fragment points at. Finding that line can mean reading the file, so it's
+// done in the command rather than while handling the key.
+func openLinkInEditor(l followableLink, duplicateSlugs string) tea.Cmd {
+	return func() tea.Msg {
+		return openEditor(l.ResolvedPath, linkSourceLine(l, duplicateSlugs))()
+	}
+}
+
+// linkSourceLine returns the line of the link's target file its fragment
+// points at, or 0 if there's none.
+func linkSourceLine(l followableLink, duplicateSlugs string) int {
+	if n, ok := lineAnchor(l.Fragment); ok {
+		return n
+	}
+	if l.Fragment == "" || !utils.IsMarkdownFile(l.ResolvedPath) {
+		return 0
+	}
+	data, err := os.ReadFile(l.ResolvedPath)
+	if err != nil {
+		log.Debug("error reading link target", "path", l.ResolvedPath, "error", err)
+		return 0
+	}
+	return sourceLineForSlug(string(data), l.Fragment, duplicateSlugs)
+}
+
+// isCurrentDocument reports whether path points at the document we're
+// viewing.
+func (m pagerModel) isCurrentDocument(path string) bool {
+	if m.currentDocument.localPath == "" {
+		return false
+	}
+	current, err := filepath.Abs(m.currentDocument.localPath)
+	if err != nil {
+		return false
+	}
+	return evalSymlinksOrSelf(current) == evalSymlinksOrSelf(path)
+}
+
+// followSelfLink handles a link to the document we're already viewing by
+// jumping within it rather than loading it again.
+func (m *pagerModel) followSelfLink(frag string) tea.Cmd {
+	m.focusedLink = -1
+	m.applyRenderedContent()
+	if frag != "" {
+		return m.jumpToFragment(frag)
+	}
+	return m.jumpToLine(0, "Top of document")
+}
+
+// isLargeFile reports the size of the file at path and whether it's over
+// the configured threshold.
+func (m pagerModel) isLargeFile(path string) (int64, bool) {
+	threshold := m.common.cfg.LargeFileThreshold
+	if threshold == 0 {
+		return 0, false
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0, false
+	}
+	return info.Size(), uint64(info.Size()) > uint64(threshold) //nolint:gosec
+}
+
+// navigateTo opens the local document at path, remembering the current
+// document and scroll position so we can go back to it.
+func (m *pagerModel) navigateTo(path, note string) tea.Cmd {
+	return m.navigateToDocument(&markdown{localPath: path, Note: note})
+}
+
+func (m *pagerModel) navigateToDocument(md *markdown) tea.Cmd {
+	m.rememberPosition()
 	if m.currentDocument.localPath != "" {
-		m.history = append(m.history, navEntry{Path: m.currentDocument.localPath, YOffset: m.viewport.YOffset})
+		m.history = append(m.history, m.navEntry())
 	}
+	// Like in a browser, going somewhere new drops the way forward.
+	m.forward = nil
 
 	m.focusedLink = -1
 	m.viewport.GotoTop()
 	m.pendingRestoreYOffset = nil
+	m.pendingFragment = ""
 
-	md := &markdown{
-		localPath: l.ResolvedPath,
-		Note:      l.ResolvedNote,
-	}
-	return loadLocalMarkdown(md)
+	return loadLocalMarkdown(md, m.common.cfg.Encoding)
+}
+
+// navEntry returns the current document and scroll position.
+func (m pagerModel) navEntry() navEntry {
+	return navEntry{Path: m.currentDocument.localPath, YOffset: m.viewport.YOffset}
 }
 
 func (m *pagerModel) goBack() tea.Cmd {
@@ -660,15 +2324,125 @@ func (m *pagerModel) goBack() tea.Cmd {
 
 	last := m.history[len(m.history)-1]
 	m.history = m.history[:len(m.history)-1]
+	if m.currentDocument.localPath != "" {
+		m.forward = append(m.forward, m.navEntry())
+	}
+	return m.restoreNavEntry(last)
+}
+
+// goForward revisits the document we last went back from.
+func (m *pagerModel) goForward() tea.Cmd {
+	if len(m.forward) == 0 {
+		return nil
+	}
+
+	next := m.forward[len(m.forward)-1]
+	m.forward = m.forward[:len(m.forward)-1]
+	if m.currentDocument.localPath != "" {
+		m.history = append(m.history, m.navEntry())
+	}
+	cmd := m.restoreNavEntry(next)
+	return tea.Batch(cmd, m.showStatusMessage(pagerStatusMessage{
+		"Forward: " + stripAbsolutePath(next.Path, m.common.cwd), false,
+	}))
+}
 
+// restoreNavEntry loads a document from the history, scrolled to where it
+// was.
+func (m *pagerModel) restoreNavEntry(e navEntry) tea.Cmd {
+	m.rememberPosition()
 	m.focusedLink = -1
-	y := last.YOffset
+	y := e.YOffset
 	m.pendingRestoreYOffset = &y
 	m.viewport.GotoTop()
 
 	md := &markdown{
-		localPath: last.Path,
-		Note:      stripAbsolutePath(last.Path, m.common.cwd),
+		localPath: e.Path,
+		Note:      stripAbsolutePath(e.Path, m.common.cwd),
+	}
+	return loadLocalMarkdown(md, m.common.cfg.Encoding)
+}
+
+// jumpToFragment scrolls to the target of a link fragment: a heading anchor
+// in markdown documents, or a line anchor like L42 in code files. If there's
+// no such target we go to the top and say so.
+func (m *pagerModel) jumpToFragment(frag string) tea.Cmd {
+	if !utils.IsMarkdownFile(m.currentDocument.Note) {
+		if n, ok := lineAnchor(frag); ok {
+			if line := gutterLine(m.rendered, n); line >= 0 {
+				return m.jumpToLine(line, fmt.Sprintf("Line %d", n))
+			}
+		}
+	} else if i := headingForSlug(m.headings, frag); i >= 0 {
+		return m.jumpToHeading(i)
+	}
+	return m.jumpToLine(0, fmt.Sprintf("Anchor #%s not found", frag))
+}
+
+// goToLine scrolls to the given line, counting from 1. When there's a
+// line-number gutter that's the line it shows, otherwise the rendered line.
+// Lines before the first go to the top and lines past the last to the bottom.
+func (m *pagerModel) goToLine(n int) tea.Cmd {
+	if n <= 0 {
+		return m.jumpToLine(0, "Top of document")
+	}
+
+	line := n - 1
+	if m.gutterWidth() > 0 {
+		if l := gutterLine(m.rendered, n); l >= 0 {
+			line = l
+		}
+	}
+	if line >= m.viewport.TotalLineCount() {
+		m.viewport.GotoBottom()
+		cmds := []tea.Cmd{m.showStatusMessage(pagerStatusMessage{"Bottom of document", false})}
+		if m.common.cfg.HighPerformancePager {
+			cmds = append(cmds, viewport.Sync(m.viewport))
+		}
+		return tea.Batch(cmds...)
+	}
+	return m.jumpToLine(line, fmt.Sprintf("Line %d", n))
+}
+
+// jumpToHeading scrolls the viewport so that the given heading is at the
+// top.
+func (m *pagerModel) jumpToHeading(i int) tea.Cmd {
+	h := m.headings[i]
+	return m.jumpToLine(h.Line, h.Text)
+}
+
+// jumpToLine scrolls the viewport so that the given rendered line is at the
+// top and, if enabled, briefly flashes it so the eye catches the landing
+// spot.
+func (m *pagerModel) jumpToLine(line int, msg string) tea.Cmd {
+	scroll := m.scrollTo(line)
+
+	if m.common.cfg.FlashHeadingJumps {
+		m.flashLine = line
+		m.applyRenderedContent()
+	}
+
+	cmds := []tea.Cmd{scroll, m.showStatusMessage(pagerStatusMessage{msg, false})}
+	if m.common.cfg.HighPerformancePager {
+		cmds = append(cmds, viewport.Sync(m.viewport))
+	}
+	return tea.Batch(cmds...)
+}
+
+// gutterLine returns the rendered line whose line-number gutter shows the
+// given source line, or -1. Wrapped continuation lines have an empty gutter
+// and are skipped.
+func gutterLine(rendered string, n int) int {
+	want := strconv.Itoa(n)
+	width := max(lineNumberWidth, len(want))
+	for i, l := range strings.Split(rendered, "\n") {
+		l = stripANSI(l)
+		if len(l) < width {
+			continue
+		}
+		if strings.TrimSpace(l[:width]) == want {
+			return i
+		}
 	}
-	return loadLocalMarkdown(md)
+	return -1
 }
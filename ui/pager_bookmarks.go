@@ -0,0 +1,138 @@
+package ui
+
+import (
+	"fmt"
+	"slices"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// bookmark is a spot in a local document to jump back to from anywhere, kept
+// between sessions.
+type bookmark struct {
+	Path    string `json:"path"`
+	YOffset int    `json:"yOffset"`
+	Label   string `json:"label"`
+}
+
+// loadBookmarks reads the bookmarks kept in a file.
+func loadBookmarks(path string) []bookmark {
+	var bookmarks []bookmark
+	if !loadStateFile(path, "bookmarks", &bookmarks) {
+		return nil
+	}
+	return bookmarks
+}
+
+// saveBookmarks writes bookmarks to a file.
+func saveBookmarks(path string, bookmarks []bookmark) error {
+	return saveStateFile(path, bookmarks)
+}
+
+// bookmarkOverlayItems lists bookmarks by label, along with the document
+// they're in.
+func bookmarkOverlayItems(cwd string, bookmarks []bookmark) []overlayItem {
+	items := make([]overlayItem, len(bookmarks))
+	for i, b := range bookmarks {
+		note := stripAbsolutePath(b.Path, cwd)
+		if note == "" {
+			// The document is gone.
+			note = b.Path
+		}
+		items[i] = overlayItem{Label: b.Label, Detail: note}
+	}
+	return items
+}
+
+// addBookmark bookmarks where the document is scrolled to, labelled with the
+// section being read.
+func (m *pagerModel) addBookmark() tea.Cmd {
+	path := positionKey(m.currentDocument.localPath)
+	if path == "" {
+		return m.showStatusMessage(pagerStatusMessage{"Only local files can be bookmarked", true})
+	}
+	b := bookmark{Path: path, YOffset: m.viewport.YOffset, Label: m.currentDocument.Note}
+	if len(m.headings) > 0 {
+		b.Label = m.headings[headingAt(m.headings, m.viewport.YOffset)].Text
+	}
+	m.reloadBookmarks()
+	for _, other := range m.bookmarks {
+		if other.Path == b.Path && other.YOffset == b.YOffset {
+			return m.showStatusMessage(pagerStatusMessage{"Already bookmarked", false})
+		}
+	}
+	m.bookmarks = append(m.bookmarks, b)
+	if err := m.saveBookmarks(); err != nil {
+		return m.showStatusMessage(pagerStatusMessage{"Couldn't save bookmark: " + err.Error(), true})
+	}
+	return m.showStatusMessage(pagerStatusMessage{fmt.Sprintf("Bookmarked %q", b.Label), false})
+}
+
+// deleteBookmark deletes the bookmark at index i.
+func (m *pagerModel) deleteBookmark(i int) tea.Cmd {
+	if i < 0 || i >= len(m.bookmarks) {
+		return nil
+	}
+	b := m.bookmarks[i]
+	m.reloadBookmarks()
+	m.bookmarks = slices.DeleteFunc(m.bookmarks, func(other bookmark) bool { return other == b })
+	if err := m.saveBookmarks(); err != nil {
+		return m.showStatusMessage(pagerStatusMessage{"Couldn't save bookmarks: " + err.Error(), true})
+	}
+	return m.showStatusMessage(pagerStatusMessage{fmt.Sprintf("Deleted bookmark %q", b.Label), false})
+}
+
+// reloadBookmarks reads the bookmarks again before changing them, as another
+// glow may have saved some of its own since.
+func (m *pagerModel) reloadBookmarks() {
+	if file := m.common.cfg.BookmarksFile; file != "" {
+		m.bookmarks = loadBookmarks(file)
+	}
+}
+
+// saveBookmarks writes the bookmarks to the bookmarks file, if there's one.
+func (m pagerModel) saveBookmarks() error {
+	if m.common.cfg.BookmarksFile == "" {
+		return nil
+	}
+	return saveBookmarks(m.common.cfg.BookmarksFile, m.bookmarks)
+}
+
+// openBookmarks lists the bookmarks to pick one to jump to.
+func (m *pagerModel) openBookmarks() tea.Cmd {
+	if len(m.bookmarks) == 0 {
+		return m.showStatusMessage(pagerStatusMessage{"No bookmarks", false})
+	}
+	m.openOverlay(&listOverlay{
+		kind:  overlayBookmarks,
+		title: "Bookmarks (d to delete)",
+		items: bookmarkOverlayItems(m.common.cwd, m.bookmarks),
+	})
+	return nil
+}
+
+// deleteSelectedBookmark deletes the bookmark under the cursor of the
+// bookmarks overlay, closing it once there are none left.
+func (m *pagerModel) deleteSelectedBookmark() tea.Cmd {
+	cmd := m.deleteBookmark(m.overlay.cursor)
+	if len(m.bookmarks) == 0 {
+		m.closeOverlay()
+		return cmd
+	}
+	m.overlay.items = bookmarkOverlayItems(m.common.cwd, m.bookmarks)
+	m.overlay.moveCursor(0)
+	return cmd
+}
+
+// openBookmark opens the document of a bookmark scrolled to it, like
+// following a link, so that going back returns here.
+func (m *pagerModel) openBookmark(b bookmark) tea.Cmd {
+	note := stripAbsolutePath(b.Path, m.common.cwd)
+	if note == "" {
+		note = b.Path
+	}
+	cmd := m.navigateTo(b.Path, note)
+	y := b.YOffset
+	m.pendingRestoreYOffset = &y
+	return cmd
+}
@@ -0,0 +1,52 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBookmarks(t *testing.T) {
+	dir := t.TempDir()
+	doc := filepath.Join(dir, "notes.md")
+	body := "# Notes\n\n" + strings.Repeat("text\n\n", 40) + "## Later\n\n" + strings.Repeat("more\n\n", 40)
+	if err := os.WriteFile(doc, []byte(body), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	file := filepath.Join(dir, "bookmarks.json")
+
+	m := newTestPager(t, Config{BookmarksFile: file}, "notes.md", 80)
+	m.currentDocument.localPath = doc
+	m.currentDocument.Body = body
+	out, err := glamourRender(m, body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, _ = m.update(contentRenderedMsg(out))
+	m.viewport.SetYOffset(m.headings[1].Line + 2)
+	m = typeKeys(t, m, "m")
+	m.viewport.SetYOffset(0)
+	m = typeKeys(t, m, "m")
+	if m.statusMessage != `Bookmarked "Notes"` {
+		t.Fatalf("expected the bookmark to be labelled with the heading, got %q", m.statusMessage)
+	}
+
+	m = newTestPager(t, Config{BookmarksFile: file}, "other.md", 80)
+	if len(m.bookmarks) != 2 || m.bookmarks[0].Label != "Later" || m.bookmarks[0].Path != doc {
+		t.Fatalf("expected the bookmarks to be kept, got %+v", m.bookmarks)
+	}
+
+	m = typeKeys(t, m, "'", "d")
+	if len(m.bookmarks) != 1 || len(m.overlay.items) != 1 || m.bookmarks[0].Label != "Notes" {
+		t.Fatalf("expected the first bookmark to be deleted, got %+v", m.bookmarks)
+	}
+	if saved := loadBookmarks(file); len(saved) != 1 {
+		t.Fatalf("expected the deletion to be saved, got %+v", saved)
+	}
+
+	m = typeKeys(t, m, keyEnter)
+	if m.overlay != nil || m.pendingRestoreYOffset == nil || *m.pendingRestoreYOffset != 0 {
+		t.Fatal("expected the bookmark to be opened where it was")
+	}
+}
@@ -0,0 +1,186 @@
+package ui
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/text"
+)
+
+// codeBlock is a fenced code block and its position in the rendered output.
+type codeBlock struct {
+	Language string
+
+	// First line of code that isn't blank, used to find the block in the
+	// rendered output, and how many lines precede it in the block.
+	FirstLine   string
+	FirstOffset int
+
+	// Number of lines of code.
+	Length int
+
+	// Line in the rendered output, or -1 if it couldn't be located.
+	Line int
+}
+
+// extractCodeBlocks returns the fenced code blocks of a markdown document in
+// document order.
+func extractCodeBlocks(markdown string) []codeBlock {
+	source := []byte(markdown)
+	doc := goldmark.New().Parser().Parse(text.NewReader(source))
+
+	var out []codeBlock
+	_ = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		fcb, ok := n.(*ast.FencedCodeBlock)
+		if !ok {
+			return ast.WalkContinue, nil
+		}
+
+		lines := fcb.Lines()
+		block := codeBlock{Language: string(fcb.Language(source)), Line: -1, Length: lines.Len()}
+		for i := 0; i < lines.Len(); i++ {
+			seg := lines.At(i)
+			if l := normalizeSpace(string(seg.Value(source))); l != "" {
+				block.FirstLine = l
+				block.FirstOffset = i
+				break
+			}
+		}
+		out = append(out, block)
+		return ast.WalkSkipChildren, nil
+	})
+	return out
+}
+
+// locateCodeBlocks sets the rendered line of each code block by searching
+// the rendered output top to bottom.
+func locateCodeBlocks(rendered string, blocks []codeBlock) {
+	lines := strings.Split(stripANSI(rendered), "\n")
+
+	from := 0
+	for i := range blocks {
+		blocks[i].Line = -1
+		if blocks[i].FirstLine == "" {
+			continue
+		}
+		for l := from; l < len(lines); l++ {
+			if strings.Contains(normalizeSpace(lines[l]), blocks[i].FirstLine) {
+				blocks[i].Line = l
+				from = l + 1
+				break
+			}
+		}
+	}
+}
+
+// jumpToCodeBlock scrolls to the next code block below the top of the
+// viewport, or the previous one above it if dir is negative. It stays at the
+// first or last block when there are no more.
+func (m *pagerModel) jumpToCodeBlock(dir int) tea.Cmd {
+	var located []int
+	for i, b := range m.codeBlocks {
+		if b.Line >= 0 {
+			located = append(located, i)
+		}
+	}
+	if len(located) == 0 {
+		return m.showStatusMessage(pagerStatusMessage{"No code blocks", false})
+	}
+
+	top := m.viewport.YOffset
+	target := len(located) - 1
+	if dir < 0 {
+		target = 0
+	}
+	for k, i := range located {
+		line := m.codeBlocks[i].Line
+		if dir > 0 && line > top {
+			target = k
+			break
+		}
+		if dir < 0 && line < top {
+			target = k
+		}
+	}
+
+	b := m.codeBlocks[located[target]]
+	lang := b.Language
+	if lang == "" {
+		lang = "no language"
+	}
+	return m.jumpToLine(b.Line, fmt.Sprintf("Code block %d/%d: %s", target+1, len(located), lang))
+}
+
+// labelCodeBlocks puts the language of each fenced code block above it, in
+// line with the code. The label takes the place of the blank line above the
+// block if there's one. Blocks without a language aren't labelled.
+func labelCodeBlocks(markdown, rendered string) string {
+	blocks := extractCodeBlocks(markdown)
+	locateCodeBlocks(rendered, blocks)
+
+	lines := strings.Split(rendered, "\n")
+	// Work from the end so that inserted labels don't move the blocks
+	// still to be labelled.
+	for i := len(blocks) - 1; i >= 0; i-- {
+		b := blocks[i]
+		if b.Line < 0 || b.Language == "" {
+			continue
+		}
+		start := max(0, b.Line-b.FirstOffset)
+		indent := -1
+		for _, l := range lines[start:min(len(lines), start+b.Length)] {
+			l = stripANSI(l)
+			if strings.TrimSpace(l) == "" {
+				continue
+			}
+			if n := len(l) - len(strings.TrimLeft(l, " ")); indent < 0 || n < indent {
+				indent = n
+			}
+		}
+		label := strings.Repeat(" ", max(0, indent)) + grayFg(b.Language)
+		if start > 0 && strings.TrimSpace(stripANSI(lines[start-1])) == "" {
+			lines[start-1] = label
+			continue
+		}
+		lines = slices.Insert(lines, start, label)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// compactBlankLines collapses runs of blank lines in the rendered output of
+// a markdown document into a single one. Blank lines inside of code blocks
+// are left alone.
+func compactBlankLines(markdown, rendered string) string {
+	blocks := extractCodeBlocks(markdown)
+	locateCodeBlocks(rendered, blocks)
+
+	lines := strings.Split(rendered, "\n")
+	code := make([]bool, len(lines))
+	for _, b := range blocks {
+		if b.Line < 0 {
+			continue
+		}
+		for l := max(0, b.Line-b.FirstOffset); l < min(len(lines), b.Line-b.FirstOffset+b.Length); l++ {
+			code[l] = true
+		}
+	}
+
+	out := make([]string, 0, len(lines))
+	blank := false
+	for i, l := range lines {
+		isBlank := !code[i] && strings.TrimSpace(stripANSI(l)) == ""
+		if isBlank && blank {
+			continue
+		}
+		blank = isBlank
+		out = append(out, l)
+	}
+	return strings.Join(out, "\n")
+}
@@ -0,0 +1,126 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestJumpToCodeBlock(t *testing.T) {
+	filler := strings.Repeat("Some prose.\n\n", 30)
+	body := "# Examples\n\n" + filler +
+		"```go\nfmt.Println(\"first\")\n```\n\n" + filler +
+		"```\n\nplain block\n```\n\n" + filler +
+		"~~~sh\necho third\n~~~\n\n" + filler
+
+	m := newTestPager(t, Config{}, "README.md", 80)
+	m.currentDocument.Body = body
+	out, err := glamourRender(m, body)
+	if err != nil {
+		t.Fatalf("glamourRender returned error: %v", err)
+	}
+	m, _ = m.update(contentRenderedMsg(out))
+
+	if len(m.codeBlocks) != 3 {
+		t.Fatalf("expected 3 code blocks, got %+v", m.codeBlocks)
+	}
+	lines := strings.Split(stripANSI(m.rendered), "\n")
+
+	for _, want := range []struct {
+		key, text, status string
+	}{
+		{"}", "fmt.Println", "Code block 1/3: go"},
+		{"}", "plain block", "Code block 2/3: no language"},
+		{"}", "echo third", "Code block 3/3: sh"},
+		{"}", "echo third", "Code block 3/3: sh"},
+		{"{", "plain block", "Code block 2/3: no language"},
+		{"{", "fmt.Println", "Code block 1/3: go"},
+		{"{", "fmt.Println", "Code block 1/3: go"},
+	} {
+		m = typeKeys(t, m, want.key)
+		if m.statusMessage != want.status {
+			t.Fatalf("%s: expected status %q, got %q", want.key, want.status, m.statusMessage)
+		}
+		if top := lines[m.viewport.YOffset]; !strings.Contains(top, want.text) {
+			t.Fatalf("%s: expected %q at the top of the view, got %q", want.key, want.text, top)
+		}
+	}
+}
+
+func TestCompactBlankLines(t *testing.T) {
+	body := "# Title\n\n## Section\n\nParagraph.\n\n```go\nfunc a() {}\n\n\nfunc b() {}\n```\n\nEnd.\n"
+
+	blankRuns := func(s string) int {
+		longest, run := 0, 0
+		for _, l := range strings.Split(stripANSI(s), "\n") {
+			if strings.TrimSpace(l) == "" {
+				run++
+				longest = max(longest, run)
+			} else {
+				run = 0
+			}
+		}
+		return longest
+	}
+
+	m := newTestPager(t, Config{}, "README.md", 80)
+	airy, err := glamourRender(m, body)
+	if err != nil {
+		t.Fatalf("glamourRender returned error: %v", err)
+	}
+	if blankRuns(airy) < 2 {
+		t.Fatal("expected runs of blank lines without compact mode")
+	}
+
+	m.compact = true
+	compact, err := glamourRender(m, body)
+	if err != nil {
+		t.Fatalf("glamourRender returned error: %v", err)
+	}
+	text := stripANSI(compact)
+
+	// The two blank lines inside the code block are kept.
+	start := strings.Index(text, "func a() {}")
+	end := strings.Index(text, "func b() {}")
+	if start < 0 || end < 0 || strings.Count(text[start:end], "\n") != 3 {
+		t.Fatalf("expected blank lines in the code block to be kept:\n%s", text)
+	}
+
+	// Everywhere else there's at most one blank line in a row.
+	outside := text[:start] + text[end:]
+	if strings.Contains(outside, "\n\n\n") || blankRuns(outside) > 1 {
+		t.Fatalf("expected runs of blank lines to collapse:\n%s", text)
+	}
+	if strings.Count(compact, "\n") >= strings.Count(airy, "\n") {
+		t.Fatal("expected the compact rendering to be shorter")
+	}
+}
+
+func TestLabelCodeBlocks(t *testing.T) {
+	body := "# Code\n\nSome text.\n\n```go\nfunc main() {\n}\n```\n\n```\nplain\n```\n\n- item\n\n  ```sh\n  ls\n  ```\n"
+	m := newTestPager(t, Config{CodeBlockLabels: true}, "notes.md", 80)
+	out, err := glamourRender(m, body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	plain := newTestPager(t, Config{}, "notes.md", 80)
+	unlabelled, err := glamourRender(plain, body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(stripANSI(out), "\n")
+	for i, l := range lines {
+		lines[i] = strings.TrimRight(l, " ")
+	}
+	text := strings.Join(lines, "\n")
+	for _, want := range []string{"  Some text.\n    go\n    func main() {", "  • item\n    sh\n    ls"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("expected %q in\n%s", want, text)
+		}
+	}
+	// The label of the first block takes the blank line above it, and the
+	// block in the list gets a line of its own.
+	if got, want := strings.Count(out, "\n"), strings.Count(unlabelled, "\n")+1; got != want {
+		t.Errorf("expected %d lines, got %d", want, got)
+	}
+}
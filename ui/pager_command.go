@@ -0,0 +1,116 @@
+package ui
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/log"
+)
+
+func newCommandInput() textinput.Model {
+	ci := textinput.New()
+	ci.Prompt = ":"
+	ci.PromptStyle = lipgloss.NewStyle().Foreground(fuchsia)
+	ci.Cursor.Style = lipgloss.NewStyle().Foreground(fuchsia)
+	return ci
+}
+
+func (m *pagerModel) startCommandPrompt() tea.Cmd {
+	m.promptingCommand = true
+	m.commandInput.Reset()
+	m.commandInput.Width = max(0, m.common.width-2)
+	m.commandInput.Focus()
+	return textinput.Blink
+}
+
+func (m *pagerModel) stopCommandPrompt() {
+	m.promptingCommand = false
+	m.commandInput.Blur()
+}
+
+// updateCommandPrompt handles key presses while the command prompt is open.
+func (m *pagerModel) updateCommandPrompt(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case keyEsc:
+		m.stopCommandPrompt()
+		return nil
+	case keyEnter:
+		value := strings.TrimSpace(m.commandInput.Value())
+		m.stopCommandPrompt()
+		return m.runCommand(value)
+	}
+
+	var cmd tea.Cmd
+	m.commandInput, cmd = m.commandInput.Update(msg)
+	return cmd
+}
+
+// runCommand runs what was typed in the command prompt: a line number to
+// jump to, or open followed by the path of a document.
+func (m *pagerModel) runCommand(value string) tea.Cmd {
+	if value == "" {
+		return nil
+	}
+	if name, arg, _ := strings.Cut(value, " "); name == "open" {
+		return m.openPath(strings.TrimSpace(arg))
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return m.showStatusMessage(pagerStatusMessage{"Unknown command: " + value, true})
+	}
+	return m.goToLine(n)
+}
+
+// openPath opens the document at a typed or pasted path as if it had been
+// linked from the current document, so the same rules apply: it has to be
+// within the root directory and a document we'd follow links to. Relative
+// paths are tried relative to the current document first, then to the
+// root directory.
+func (m *pagerModel) openPath(path string) tea.Cmd {
+	if path == "" {
+		return m.showStatusMessage(pagerStatusMessage{"Usage: open <path>", true})
+	}
+
+	root := m.common.cwd
+	// resolveLocalLink resolves relative to the directory of a file, so we
+	// make one up for the root.
+	rootFile := filepath.Join(root, "_")
+	bases := []string{rootFile}
+	if m.currentDocument.localPath != "" {
+		bases = []string{m.currentDocument.localPath, rootFile}
+	}
+
+	href := path
+	if filepath.IsAbs(path) {
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return m.showStatusMessage(pagerStatusMessage{fmt.Sprintf("Can't open %s: %s", path, brokenLinkOutsideRoot), true})
+		}
+		href = filepath.ToSlash(rel)
+		bases = []string{rootFile}
+	}
+
+	var problem string
+	for _, base := range bases {
+		link, candidate, p, err := resolveLocalLink(root, base, href)
+		if err != nil {
+			log.Debug("error resolving path", "path", path, "error", err)
+			return m.showStatusMessage(pagerStatusMessage{"Can't open " + path, true})
+		}
+		if !candidate {
+			return m.showStatusMessage(pagerStatusMessage{fmt.Sprintf("Can't open %s: not a markdown document", path), true})
+		}
+		if p == "" {
+			return m.followLink(link)
+		}
+		if problem == "" {
+			problem = p
+		}
+	}
+	return m.showStatusMessage(pagerStatusMessage{fmt.Sprintf("Can't open %s: %s", path, problem), true})
+}
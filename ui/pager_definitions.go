@@ -0,0 +1,172 @@
+package ui
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	east "github.com/yuin/goldmark/extension/ast"
+	"github.com/yuin/goldmark/text"
+)
+
+var definitionBoxStyle = lipgloss.NewStyle().
+	Border(lipgloss.RoundedBorder()).
+	BorderForeground(fuchsia).
+	Padding(0, 1)
+
+// definition is a term from a definition list or an abbreviation, and what
+// it means.
+type definition struct {
+	Term       string
+	Definition string
+}
+
+// Markdown Extra style abbreviations: *[HTML]: Hyper Text Markup Language
+var abbreviationPattern = regexp.MustCompile(`(?m)^\*\[([^\]]+)\]:[ \t]*(.+)$`)
+
+// extractDefinitions returns the terms of a document's definition lists
+// followed by its abbreviations.
+func extractDefinitions(markdown string) []definition {
+	source := []byte(markdown)
+	doc := goldmark.New(goldmark.WithExtensions(extension.DefinitionList)).
+		Parser().Parse(text.NewReader(source))
+
+	var (
+		out  []definition
+		term *definition
+	)
+	_ = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		switch n.(type) {
+		case *east.DefinitionTerm:
+			t := strings.TrimSpace(nodeText(n, source))
+			if t == "" {
+				term = nil
+				return ast.WalkSkipChildren, nil
+			}
+			out = append(out, definition{Term: t})
+			term = &out[len(out)-1]
+			return ast.WalkSkipChildren, nil
+		case *east.DefinitionDescription:
+			if term == nil {
+				return ast.WalkSkipChildren, nil
+			}
+			var parts []string
+			for c := n.FirstChild(); c != nil; c = c.NextSibling() {
+				if t := strings.TrimSpace(nodeText(c, source)); t != "" {
+					parts = append(parts, t)
+				}
+			}
+			d := strings.Join(parts, " ")
+			if term.Definition != "" {
+				d = term.Definition + "; " + d
+			}
+			term.Definition = d
+			return ast.WalkSkipChildren, nil
+		}
+		return ast.WalkContinue, nil
+	})
+
+	for _, m := range abbreviationPattern.FindAllStringSubmatch(markdown, -1) {
+		out = append(out, definition{
+			Term:       strings.TrimSpace(m[1]),
+			Definition: strings.TrimSpace(m[2]),
+		})
+	}
+	return out
+}
+
+// definitionTargets returns the terms as links, for locating and
+// highlighting them.
+func definitionTargets(defs []definition) []followableLink {
+	out := make([]followableLink, len(defs))
+	for i, d := range defs {
+		out[i] = followableLink{Label: d.Term}
+	}
+	return out
+}
+
+func (m *pagerModel) startDefinitions() tea.Cmd {
+	if !m.common.cfg.DefinitionTooltips {
+		return nil
+	}
+	m.definitions = extractDefinitions(m.renderedMarkdown(m.currentDocument.Body))
+	if len(m.definitions) == 0 {
+		return m.showStatusMessage(pagerStatusMessage{"No definitions", false})
+	}
+	m.focusedLink = -1
+	m.focusedBroken = -1
+	m.focusedTerm = -1
+	return m.focusTerm(0)
+}
+
+func (m *pagerModel) stopDefinitions() {
+	m.definitions = nil
+	m.focusedTerm = -1
+	m.applyRenderedContent()
+}
+
+func (m *pagerModel) focusTerm(i int) tea.Cmd {
+	n := len(m.definitions)
+	m.focusedTerm = ((i % n) + n) % n
+	m.applyRenderedContent()
+
+	line := linkLine(m.rendered, definitionTargets(m.definitions), m.focusedTerm)
+	if line < 0 || (line >= m.viewport.YOffset && line < m.viewport.YOffset+m.viewport.Height) {
+		return nil
+	}
+	m.viewport.SetYOffset(line)
+	if m.common.cfg.HighPerformancePager {
+		return viewport.Sync(m.viewport)
+	}
+	return nil
+}
+
+// updateDefinitions handles key presses while browsing definitions. Keys
+// that don't move between terms scroll the document.
+func (m *pagerModel) updateDefinitions(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case keyTab, "n":
+		return m.focusTerm(m.focusedTerm + 1)
+	case keyShiftTab, "backtab", "p":
+		return m.focusTerm(m.focusedTerm - 1)
+	case keyEsc, "q", "D":
+		m.stopDefinitions()
+		return nil
+	}
+
+	var cmd tea.Cmd
+	m.viewport, cmd = m.viewport.Update(msg)
+	return cmd
+}
+
+// definitionView draws the focused term's definition in a box over the
+// bottom of the viewport.
+func (m pagerModel) definitionView(view string) string {
+	if m.focusedTerm < 0 || m.focusedTerm >= len(m.definitions) {
+		return view
+	}
+	d := m.definitions[m.focusedTerm]
+
+	width := max(0, min(m.viewport.Width, 80)-definitionBoxStyle.GetHorizontalFrameSize())
+	box := definitionBoxStyle.Width(width).Render(
+		lipgloss.NewStyle().Bold(true).Render(d.Term) + "\n" + d.Definition,
+	)
+
+	lines := strings.Split(view, "\n")
+	boxLines := strings.Split(box, "\n")
+	start := max(0, len(lines)-len(boxLines))
+	for i, l := range boxLines {
+		if start+i < len(lines) {
+			lines[start+i] = l
+		}
+	}
+	return strings.Join(lines, "\n")
+}
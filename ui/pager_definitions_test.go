@@ -0,0 +1,66 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+)
+
+const definitionsDoc = `# Glossary
+
+Term one
+: The first term.
+
+Term two
+: The second term.
+: Also this.
+
+The HTML spec.
+
+*[HTML]: Hyper Text Markup Language
+`
+
+func TestExtractDefinitions(t *testing.T) {
+	got := extractDefinitions(definitionsDoc)
+	want := []definition{
+		{Term: "Term one", Definition: "The first term."},
+		{Term: "Term two", Definition: "The second term.; Also this."},
+		{Term: "HTML", Definition: "Hyper Text Markup Language"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("expected %d definitions, got %d: %+v", len(want), len(got), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("definition %d: expected %+v, got %+v", i, want[i], got[i])
+		}
+	}
+}
+
+func TestDefinitionTooltips(t *testing.T) {
+	m := newTestPager(t, Config{}, "glossary.md", 80)
+	m.currentDocument.Body = definitionsDoc
+	out, err := glamourRender(m, definitionsDoc)
+	if err != nil {
+		t.Fatalf("glamourRender returned error: %v", err)
+	}
+	m, _ = m.update(contentRenderedMsg(out))
+
+	m = typeKeys(t, m, "D")
+	if m.definitions != nil {
+		t.Fatal("expected definitions to be disabled by default")
+	}
+
+	m.common.cfg.DefinitionTooltips = true
+	m = typeKeys(t, m, "D", keyTab)
+	if m.focusedTerm != 1 {
+		t.Fatalf("expected tab to focus the second term, got %d", m.focusedTerm)
+	}
+	if view := m.View(); !strings.Contains(view, "The second term.; Also this.") {
+		t.Fatalf("expected the definition to be shown, got:\n%s", view)
+	}
+
+	m = typeKeys(t, m, keyEsc)
+	if m.focusedTerm != -1 || m.definitions != nil || m.capturesKeys() {
+		t.Fatalf("expected esc to leave definitions, got term %d", m.focusedTerm)
+	}
+}
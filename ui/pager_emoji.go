@@ -0,0 +1,113 @@
+package ui
+
+import (
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/yuin/goldmark"
+	emoji "github.com/yuin/goldmark-emoji/definition"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/text"
+)
+
+// emojiShortcodePattern matches GitHub style emoji shortcodes like :rocket:.
+var emojiShortcodePattern = regexp.MustCompile(`:[a-z0-9_+\-]+:`)
+
+// githubEmoji is the set of emoji GitHub knows shortcodes for. It's big, so
+// it's only built when needed.
+var githubEmoji = sync.OnceValue(func() emoji.Emojis {
+	return emoji.Github()
+})
+
+// expandEmoji replaces emoji shortcodes in markdown with the emoji, leaving
+// code spans and blocks alone. Unknown shortcodes are kept as they are.
+func expandEmoji(markdown string) string {
+	if !strings.Contains(markdown, ":") {
+		return markdown
+	}
+
+	source := []byte(markdown)
+	doc := goldmark.New().Parser().Parse(text.NewReader(source))
+
+	// Byte ranges of code, which shortcodes are left alone in.
+	var code [][2]int
+	_ = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		switch n.(type) {
+		case *ast.CodeSpan:
+			for c := n.FirstChild(); c != nil; c = c.NextSibling() {
+				if t, ok := c.(*ast.Text); ok {
+					code = append(code, [2]int{t.Segment.Start, t.Segment.Stop})
+				}
+			}
+			return ast.WalkSkipChildren, nil
+		case *ast.FencedCodeBlock, *ast.CodeBlock, *ast.HTMLBlock:
+			lines := n.Lines()
+			for i := 0; i < lines.Len(); i++ {
+				seg := lines.At(i)
+				code = append(code, [2]int{seg.Start, seg.Stop})
+			}
+			return ast.WalkSkipChildren, nil
+		}
+		return ast.WalkContinue, nil
+	})
+
+	return replaceShortcodes(markdown, func(start, stop int) bool {
+		for _, r := range code {
+			if start < r[1] && stop > r[0] {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// expandEmojiText replaces the emoji shortcodes in plain text, like the
+// text of a heading, with the emoji.
+func expandEmojiText(s string) string {
+	if !strings.Contains(s, ":") {
+		return s
+	}
+	return replaceShortcodes(s, func(int, int) bool { return false })
+}
+
+// replaceShortcodes replaces the known shortcodes in s that skip doesn't
+// reject with their emoji. The closing colon of something that isn't a
+// shortcode, like the 30 in 10:30:tada:, can still open one.
+func replaceShortcodes(s string, skip func(start, stop int) bool) string {
+	var b strings.Builder
+	last, from := 0, 0
+	for {
+		loc := emojiShortcodePattern.FindStringIndex(s[from:])
+		if loc == nil {
+			break
+		}
+		start, stop := from+loc[0], from+loc[1]
+		e, ok := emojiFor(s[start:stop])
+		if !ok || skip(start, stop) {
+			from = stop - 1
+			continue
+		}
+		b.WriteString(s[last:start])
+		b.WriteString(e)
+		last, from = stop, stop
+	}
+	if last == 0 {
+		return s
+	}
+	b.WriteString(s[last:])
+	return b.String()
+}
+
+// emojiFor returns the emoji for a shortcode with its colons, if there's
+// one.
+func emojiFor(shortcode string) (string, bool) {
+	e, ok := githubEmoji().Get(strings.Trim(shortcode, ":"))
+	if !ok || !e.IsUnicode() {
+		return "", false
+	}
+	return string(e.Unicode), true
+}
@@ -0,0 +1,80 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestExpandEmoji(t *testing.T) {
+	for _, tc := range []struct {
+		name, in, want string
+	}{
+		{"shortcodes", "Launch :rocket: and :tada:!", "Launch 🚀 and 🎉!"},
+		{"unknown", "Not :an_emoji_at_all: here", "Not :an_emoji_at_all: here"},
+		{"after a colon", "At 10:30:tada:", "At 10:30🎉"},
+		{"code span", "Type `:rocket:` for :rocket:", "Type `:rocket:` for 🚀"},
+		{"fenced code", "```yaml\nkey: :rocket:\n```\n\n:+1:", "```yaml\nkey: :rocket:\n```\n\n👍"},
+		{"indented code", "Text\n\n    :tada:\n", "Text\n\n    :tada:\n"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := expandEmoji(tc.in); got != tc.want {
+				t.Fatalf("expected %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestRenderEmoji(t *testing.T) {
+	const body = "# :rocket: Launch\n\nShip it :tada:\n\n```\n:tada:\n```\n"
+
+	m := newTestPager(t, Config{}, "notes.md", 80)
+	m.currentDocument.Body = body
+	out, err := glamourRender(m, body)
+	if err != nil {
+		t.Fatalf("glamourRender returned error: %v", err)
+	}
+	if strings.Contains(out, "🎉") {
+		t.Fatal("expected shortcodes to be left alone unless enabled")
+	}
+
+	m = newTestPager(t, Config{RenderEmoji: true}, "notes.md", 80)
+	m.currentDocument.Body = body
+	out, err = glamourRender(m, body)
+	if err != nil {
+		t.Fatalf("glamourRender returned error: %v", err)
+	}
+	plain := stripANSI(out)
+	if !strings.Contains(plain, "Ship it 🎉") || !strings.Contains(plain, ":tada:") {
+		t.Fatalf("expected shortcodes outside of code to be expanded, got:\n%s", plain)
+	}
+
+	m, _ = m.update(contentRenderedMsg(out))
+	if len(m.headings) != 1 || m.headings[0].Line < 0 {
+		t.Fatalf("expected the heading with an emoji to be located, got %+v", m.headings)
+	}
+}
+
+func TestEmojiHeadings(t *testing.T) {
+	const body = "# Intro\n\n## :rocket: Launch\n\n| a |\n|---|\n| :rocket: |\n"
+
+	m := newTestPager(t, Config{RenderEmoji: true}, "notes.md", 80)
+	m.currentDocument.Body = body
+	out, err := glamourRender(m, body)
+	if err != nil {
+		t.Fatalf("glamourRender returned error: %v", err)
+	}
+	m, _ = m.update(contentRenderedMsg(out))
+	if len(m.headings) != 2 {
+		t.Fatalf("expected 2 headings, got %+v", m.headings)
+	}
+	h := m.headings[1]
+	if !strings.Contains(h.Text, "🚀") || h.Line < 0 {
+		t.Fatalf("expected the heading with an emoji expanded and located, got %+v", h)
+	}
+	if h.Slug != "rocket-launch" {
+		t.Fatalf("expected the slug to keep the shortcode, got %q", h.Slug)
+	}
+	if len(m.tables) != 1 {
+		t.Fatalf("expected the table to be found, got %d", len(m.tables))
+	}
+}
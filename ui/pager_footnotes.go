@@ -0,0 +1,220 @@
+package ui
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	east "github.com/yuin/goldmark/extension/ast"
+	"github.com/yuin/goldmark/text"
+)
+
+// footnoteRefPattern matches a footnote reference like [^1] or [^note].
+var footnoteRefPattern = regexp.MustCompile(`\[\^([^\]\s]+)\]`)
+
+// footnoteMark is a footnote reference or definition and its position in the
+// rendered output. Glamour doesn't know about footnotes, so both show up as
+// they're written.
+type footnoteMark struct {
+	Label      string
+	Definition bool
+
+	// Line in the rendered output, or -1 if it couldn't be located.
+	Line int
+}
+
+// text is how the mark shows up in the rendered output.
+func (f footnoteMark) text() string {
+	if f.Definition {
+		return "[^" + f.Label + "]:"
+	}
+	return "[^" + f.Label + "]"
+}
+
+// extractFootnotes returns the footnote references of a markdown document in
+// document order, followed by its footnote definitions. References to
+// footnotes that aren't defined are included, so that we can tell.
+func extractFootnotes(markdown string) []footnoteMark {
+	source := []byte(markdown)
+	doc := goldmark.New(goldmark.WithExtensions(extension.Footnote)).
+		Parser().Parse(text.NewReader(source))
+
+	var refs, defs []footnoteMark
+	_ = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		switch n := n.(type) {
+		case *east.Footnote:
+			defs = append(defs, footnoteMark{Label: string(n.Ref), Definition: true, Line: -1})
+		case *ast.Paragraph, *ast.Heading, *ast.TextBlock:
+			// References to undefined footnotes are plain text to goldmark,
+			// so they're found in the source text.
+			lines := n.Lines()
+			for i := 0; i < lines.Len(); i++ {
+				seg := lines.At(i)
+				line := seg.Value(source)
+				masked := codeSpanPattern.ReplaceAllFunc(line, func(s []byte) []byte {
+					return []byte(strings.Repeat(" ", len(s)))
+				})
+				for _, m := range footnoteRefPattern.FindAllSubmatchIndex(masked, -1) {
+					refs = append(refs, footnoteMark{Label: string(line[m[2]:m[3]]), Line: -1})
+				}
+			}
+			return ast.WalkSkipChildren, nil
+		}
+		return ast.WalkContinue, nil
+	})
+	return append(refs, defs...)
+}
+
+// locateFootnotes sets the rendered line of each footnote reference and
+// definition by searching the rendered output top to bottom. Definitions are
+// lines starting with [^label]:, which references aren't looked for in.
+func locateFootnotes(rendered string, marks []footnoteMark) {
+	lines := strings.Split(stripANSI(rendered), "\n")
+
+	fromRef, fromDef := 0, 0
+	for i := range marks {
+		f := &marks[i]
+		f.Line = -1
+		from := &fromRef
+		if f.Definition {
+			from = &fromDef
+		}
+		for l := *from; l < len(lines); l++ {
+			found := false
+			if f.Definition {
+				found = footnoteDefinitionLine(lines[l]) == f.Label
+			} else {
+				found = footnoteDefinitionLine(lines[l]) == "" && strings.Contains(lines[l], f.text())
+			}
+			if found {
+				f.Line = l
+				// A line can have several references.
+				*from = l
+				if f.Definition {
+					*from = l + 1
+				}
+				break
+			}
+		}
+	}
+
+	// Glamour takes a definition that's only a link, like [^1]: https://…,
+	// for a link reference definition and drops it, showing its references
+	// as links with ^1 for text.
+	hidden := map[string]bool{}
+	for _, f := range marks {
+		if f.Definition && f.Line < 0 {
+			hidden[f.Label] = true
+		}
+	}
+	from := 0
+	for i := range marks {
+		f := &marks[i]
+		if f.Definition || f.Line >= 0 || !hidden[f.Label] {
+			continue
+		}
+		for l := from; l < len(lines); l++ {
+			if strings.Contains(lines[l], "^"+f.Label) {
+				f.Line, from = l, l
+				break
+			}
+		}
+	}
+}
+
+// footnoteDefinitionLine returns the label of the footnote defined on a line
+// of the rendered output, or "".
+func footnoteDefinitionLine(line string) string {
+	line = strings.TrimSpace(line)
+	if !strings.HasPrefix(line, "[^") {
+		return ""
+	}
+	end := strings.Index(line, "]:")
+	if end < 0 || strings.ContainsAny(line[2:end], " ]") {
+		return ""
+	}
+	return line[2:end]
+}
+
+// footnoteInView returns the first located footnote reference or definition
+// in the viewport, or -1.
+func (m pagerModel) footnoteInView() int {
+	best := -1
+	for i, f := range m.footnotes {
+		if !m.isLineInView(f.Line) {
+			continue
+		}
+		if best < 0 || f.Line < m.footnotes[best].Line {
+			best = i
+		}
+	}
+	return best
+}
+
+// footnoteDefinition returns the located definition of the footnote with
+// the given label, or -1.
+func (m pagerModel) footnoteDefinition(label string) int {
+	for i, f := range m.footnotes {
+		if f.Definition && f.Label == label && f.Line >= 0 {
+			return i
+		}
+	}
+	return -1
+}
+
+// jumpToFootnote jumps from the first footnote reference in view to its
+// definition, or from a definition back to the reference we came from.
+func (m *pagerModel) jumpToFootnote() tea.Cmd {
+	// Definitions near the end of the document can't be scrolled to the
+	// top, so other footnotes may come before the one we jumped to.
+	if back := m.footnoteReturn; back.Line >= 0 {
+		if d := m.footnoteDefinition(back.Label); d >= 0 && m.isLineInView(m.footnotes[d].Line) {
+			m.footnoteReturn = footnoteMark{Line: -1}
+			return m.jumpToLine(back.Line, "Reference to "+back.text())
+		}
+	}
+
+	i := m.footnoteInView()
+	if i < 0 {
+		return m.showStatusMessage(pagerStatusMessage{"No footnotes in view", false})
+	}
+	f := m.footnotes[i]
+
+	if !f.Definition {
+		d := m.footnoteDefinition(f.Label)
+		if d < 0 {
+			// Glamour drops some definitions, like those that are only a
+			// link, which look like link reference definitions to it.
+			defined := slices.ContainsFunc(m.footnotes, func(o footnoteMark) bool {
+				return o.Definition && o.Label == f.Label
+			})
+			if defined {
+				return m.showStatusMessage(pagerStatusMessage{fmt.Sprintf("Footnote %s is defined, but its definition isn't shown", f.text()), false})
+			}
+			return m.showStatusMessage(pagerStatusMessage{fmt.Sprintf("Footnote %s isn't defined", f.text()), true})
+		}
+		m.footnoteReturn = f
+		return m.jumpToLine(m.footnotes[d].Line, fmt.Sprintf("Footnote %s, ^ to go back", f.text()))
+	}
+
+	for _, r := range m.footnotes {
+		if !r.Definition && r.Label == f.Label && r.Line >= 0 {
+			return m.jumpToLine(r.Line, "Reference to "+r.text())
+		}
+	}
+	return m.showStatusMessage(pagerStatusMessage{fmt.Sprintf("Footnote [^%s] isn't referenced", f.Label), false})
+}
+
+// isLineInView reports whether a line of the rendered output is in the
+// viewport.
+func (m pagerModel) isLineInView(line int) bool {
+	return line >= m.viewport.YOffset && line < m.viewport.YOffset+m.viewport.Height
+}
@@ -0,0 +1,103 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestExtractFootnotes(t *testing.T) {
+	md := "Text with a note[^1] and `[^code]` and another[^long][^1].\n\n" +
+		"Missing[^nope].\n\n```\n[^fenced]\n```\n\n[^1]: The first note.\n[^long]: Second one\n    continues.\n"
+	got := extractFootnotes(md)
+
+	var labels []string
+	for _, f := range got {
+		l := f.text()
+		labels = append(labels, l)
+	}
+	want := "[^1] [^long] [^1] [^nope] [^1]: [^long]:"
+	if strings.Join(labels, " ") != want {
+		t.Fatalf("expected %q, got %q", want, strings.Join(labels, " "))
+	}
+}
+
+func TestJumpToFootnote(t *testing.T) {
+	var b strings.Builder
+	b.WriteString("Intro with a note[^a] and a missing one[^x].\n\n")
+	for i := range 60 {
+		fmt.Fprintf(&b, "Filler paragraph %d.\n\n", i)
+	}
+	b.WriteString("Later reference[^b].\n\n")
+	b.WriteString("[^a]: The first note.\n\n[^b]: The second note.\n")
+	body := b.String()
+
+	m := newTestPager(t, Config{}, "paper.md", 80)
+	m.currentDocument.Body = body
+	out, err := glamourRender(m, body)
+	if err != nil {
+		t.Fatalf("glamourRender returned error: %v", err)
+	}
+	m, _ = m.update(contentRenderedMsg(out))
+	lines := strings.Split(stripANSI(m.rendered), "\n")
+	lineOf := func(text string) int {
+		for i, l := range lines {
+			if strings.Contains(l, text) {
+				return i
+			}
+		}
+		t.Fatalf("%q not found in the rendered output", text)
+		return -1
+	}
+	inView := func(line int) bool {
+		return line >= m.viewport.YOffset && line < m.viewport.YOffset+m.viewport.Height
+	}
+
+	m = typeKeys(t, m, "^")
+	if m.statusMessage != "Footnote [^a], ^ to go back" || !inView(lineOf("The first note")) {
+		t.Fatalf("expected to jump to the definition, got status %q at line %d", m.statusMessage, m.viewport.YOffset)
+	}
+
+	m = typeKeys(t, m, "^")
+	if m.statusMessage != "Reference to [^a]" || m.viewport.YOffset != lineOf("Intro with") {
+		t.Fatalf("expected to jump back to the reference, got status %q at line %d", m.statusMessage, m.viewport.YOffset)
+	}
+
+	// Enter does the same when no link is focused.
+	m = typeKeys(t, m, "enter")
+	if !inView(lineOf("The first note")) {
+		t.Fatalf("expected enter to jump to the definition, got line %d", m.viewport.YOffset)
+	}
+
+	// Only the missing reference is left in view.
+	m.viewport.SetYOffset(0)
+	m.footnotes[0].Line = -1
+	m = typeKeys(t, m, "^")
+	if m.statusMessage != "Footnote [^x] isn't defined" {
+		t.Fatalf("expected the definition to be missing, got status %q", m.statusMessage)
+	}
+
+	m.viewport.SetYOffset(lineOf("Filler paragraph 30."))
+	m = typeKeys(t, m, "^")
+	if m.statusMessage != "No footnotes in view" {
+		t.Fatalf("unexpected status %q", m.statusMessage)
+	}
+}
+
+func TestJumpToFootnote_DefinitionNotShown(t *testing.T) {
+	// Glamour takes a definition that's only a link for a link reference
+	// definition, and leaves it out.
+	body := "A claim[^1].\n\n[^1]: https://example.com\n"
+	m := newTestPager(t, Config{}, "paper.md", 80)
+	m.currentDocument.Body = body
+	out, err := glamourRender(m, body)
+	if err != nil {
+		t.Fatalf("glamourRender returned error: %v", err)
+	}
+	m, _ = m.update(contentRenderedMsg(out))
+
+	m = typeKeys(t, m, "^")
+	if m.statusMessage != "Footnote [^1] is defined, but its definition isn't shown" {
+		t.Fatalf("expected the definition not to be shown, got status %q", m.statusMessage)
+	}
+}
@@ -0,0 +1,103 @@
+package ui
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
+	"github.com/muesli/reflow/truncate"
+)
+
+var (
+	frontMatterKeyStyle = lipgloss.NewStyle().
+				Foreground(fuchsia).
+				Bold(true).
+				Render
+
+	frontMatterValueStyle = lipgloss.NewStyle().
+				Foreground(lineNumberFg).
+				Render
+)
+
+// frontMatterKeyPattern matches a top level key of YAML front matter and its
+// value, if it's on the same line.
+var frontMatterKeyPattern = regexp.MustCompile(`^([^\s#:-][^:]*):(?:\s+(.*))?$`)
+
+// frontMatterLines formats front matter as a table of keys and values. Lines
+// other than top level keys, like list items, are shown below the key they
+// belong to.
+func frontMatterLines(front string) []string {
+	lines := strings.Split(strings.TrimRight(front, "\r\n"), "\n")
+
+	keyWidth := 0
+	for _, l := range lines {
+		if m := frontMatterKeyPattern.FindStringSubmatch(strings.TrimRight(l, "\r")); m != nil {
+			keyWidth = max(keyWidth, runewidth.StringWidth(m[1]))
+		}
+	}
+
+	out := make([]string, 0, len(lines))
+	for _, l := range lines {
+		l = strings.TrimRight(l, "\r")
+		if strings.TrimSpace(l) == "" {
+			continue
+		}
+		if m := frontMatterKeyPattern.FindStringSubmatch(l); m != nil {
+			pad := strings.Repeat(" ", keyWidth-runewidth.StringWidth(m[1]))
+			out = append(out, frontMatterKeyStyle(m[1])+pad+"  "+frontMatterValueStyle(m[2]))
+			continue
+		}
+		out = append(out, strings.Repeat(" ", keyWidth+2)+frontMatterValueStyle(strings.TrimSpace(l)))
+	}
+	return out
+}
+
+// frontMatterView renders the front matter of the document above it, or ""
+// when it's hidden. It takes up at most a third of the screen.
+func (m pagerModel) frontMatterView() string {
+	if !m.showFrontMatter || m.currentDocument.frontMatter == "" {
+		return ""
+	}
+
+	lines := frontMatterLines(m.currentDocument.frontMatter)
+	if limit := max(1, m.common.height/3); len(lines) > limit {
+		more := len(lines) - limit + 1
+		lines = append(lines[:limit-1], frontMatterValueStyle(fmt.Sprintf("… %d more lines", more)))
+	}
+
+	margin := strings.Repeat(" ", m.contentMargin()+2)
+	for i, l := range lines {
+		lines[i] = truncate.StringWithTail(margin+l, uint(max(0, m.common.width)), ellipsis) //nolint:gosec
+	}
+	// Leave a blank line between the front matter and the document.
+	return strings.Join(lines, "\n") + "\n\n"
+}
+
+// frontMatterHeight returns the number of lines the front matter takes up,
+// including the blank line below it.
+func (m pagerModel) frontMatterHeight() int {
+	return strings.Count(m.frontMatterView(), "\n")
+}
+
+// toggleFrontMatter shows or hides the front matter of the document.
+func (m *pagerModel) toggleFrontMatter() tea.Cmd {
+	if m.currentDocument.frontMatter == "" {
+		return m.showStatusMessage(pagerStatusMessage{"No front matter", false})
+	}
+	m.showFrontMatter = !m.showFrontMatter
+	m.setSize(m.common.width, m.common.height)
+
+	msg := "Front matter shown"
+	if !m.showFrontMatter {
+		msg = "Front matter hidden"
+	}
+	cmds := []tea.Cmd{m.showStatusMessage(pagerStatusMessage{msg, false})}
+	if m.common.cfg.HighPerformancePager {
+		cmds = append(cmds, viewport.Sync(m.viewport))
+	}
+	return tea.Batch(cmds...)
+}
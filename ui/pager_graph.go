@@ -0,0 +1,99 @@
+package ui
+
+import (
+	"os"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/glow/v2/utils"
+	"github.com/charmbracelet/log"
+)
+
+const defaultLinkGraphDepth = 3
+
+type linkGraphMsg []overlayItem
+
+// linkGraph returns the documents reachable from the one at path by
+// following links, as an indented tree. Documents are only expanded the first
+// time they show up, and no deeper than depth levels. Documents are decoded
+// from the given encoding.
+func linkGraph(rootDir, path, note string, depth int, encoding string) []overlayItem {
+	if depth <= 0 {
+		depth = defaultLinkGraphDepth
+	}
+
+	items := []overlayItem{{Label: note}}
+	visited := map[string]bool{evalSymlinksOrSelf(path): true}
+
+	// The documents all share the same root, so it's only indexed once.
+	var wiki wikiIndexCache
+
+	var walk func(path, prefix string, level int)
+	walk = func(path, prefix string, level int) {
+		links := documentLinkTargets(rootDir, path, encoding, &wiki)
+		for i, l := range links {
+			connector, indent := "├─ ", "│  "
+			if i == len(links)-1 {
+				connector, indent = "└─ ", "   "
+			}
+
+			item := overlayItem{
+				Label: prefix + connector + l.ResolvedNote,
+				Path:  l.ResolvedPath,
+				Note:  l.ResolvedNote,
+			}
+			if visited[l.ResolvedPath] {
+				item.Detail = "seen"
+				items = append(items, item)
+				continue
+			}
+			visited[l.ResolvedPath] = true
+			items = append(items, item)
+
+			if level+1 < depth && utils.IsMarkdownFile(l.ResolvedPath) {
+				walk(l.ResolvedPath, prefix+indent, level+1)
+			}
+		}
+	}
+	walk(path, "", 0)
+
+	return items
+}
+
+// documentLinkTargets returns the followable links of the document at path,
+// one per target and leaving out links to the document itself.
+func documentLinkTargets(rootDir, path, encoding string, wiki *wikiIndexCache) []followableLink {
+	data, err := os.ReadFile(path)
+	if err == nil {
+		data, err = decodeDocument(data, encoding)
+	}
+	if err != nil {
+		log.Debug("error reading document for link graph", "path", path, "error", err)
+		return nil
+	}
+
+	links, err := documentLinks(rootDir, path, string(utils.RemoveFrontmatter(data)), linkOptions{wiki: wiki})
+	if err != nil {
+		log.Debug("error extracting links for link graph", "path", path, "error", err)
+	}
+
+	var (
+		out  []followableLink
+		seen = map[string]bool{evalSymlinksOrSelf(path): true}
+	)
+	for _, l := range links {
+		if seen[l.ResolvedPath] {
+			continue
+		}
+		seen[l.ResolvedPath] = true
+		out = append(out, l)
+	}
+	return out
+}
+
+// COMMANDS
+
+func buildLinkGraph(rootDir, path, note string, depth int, encoding string) tea.Cmd {
+	return func() tea.Msg {
+		return linkGraphMsg(linkGraph(rootDir, path, note, depth, encoding))
+	}
+}
@@ -0,0 +1,53 @@
+package ui
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLinkGraph(t *testing.T) {
+	root := absEvalSymlinks(t, t.TempDir())
+	mustWriteFile(t, filepath.Join(root, "index.md"), "[a](a.md) [b](b.md) [self](index.md)\n")
+	mustWriteFile(t, filepath.Join(root, "a.md"), "[b](b.md) [c](c.md) [back](index.md)\n")
+	mustWriteFile(t, filepath.Join(root, "b.md"), "no links\n")
+	mustWriteFile(t, filepath.Join(root, "c.md"), "[d](d.md)\n")
+	mustWriteFile(t, filepath.Join(root, "d.md"), "[e](e.md)\n")
+	mustWriteFile(t, filepath.Join(root, "e.md"), "\n")
+
+	var got []string
+	for _, item := range linkGraph(root, filepath.Join(root, "index.md"), "index.md", 3, encodingAuto) {
+		line := item.Label
+		if item.Detail != "" {
+			line += " (" + item.Detail + ")"
+		}
+		got = append(got, line)
+	}
+
+	want := []string{
+		"index.md",
+		"├─ a.md",
+		"│  ├─ b.md",
+		"│  ├─ c.md",
+		"│  │  └─ d.md",
+		"│  └─ index.md (seen)",
+		"└─ b.md (seen)",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("unexpected link graph:\n%s\n\nexpected:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestLinkGraph_Encoding(t *testing.T) {
+	root := absEvalSymlinks(t, t.TempDir())
+	mustWriteFile(t, filepath.Join(root, "index.md"), "[caf\xe9](caf\xe9.md)\n")
+	mustWriteFile(t, filepath.Join(root, "café.md"), "\n")
+
+	var got []string
+	for _, item := range linkGraph(root, filepath.Join(root, "index.md"), "index.md", 3, "latin1") {
+		got = append(got, item.Label)
+	}
+	if want := []string{"index.md", "└─ café.md"}; strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("expected the documents to be read as latin1, got:\n%s", strings.Join(got, "\n"))
+	}
+}
@@ -0,0 +1,306 @@
+package ui
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"unicode"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/muesli/reflow/ansi"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/text"
+)
+
+// heading is a markdown heading and its position in the rendered output.
+type heading struct {
+	Level int
+	Text  string
+	Slug  string
+
+	// Line in the rendered output, or -1 if it couldn't be located.
+	Line int
+
+	// Line in the markdown source, starting at 1.
+	SourceLine int
+}
+
+// extractHeadings returns the headings of a markdown document in document
+// order.
+func extractHeadings(markdown string) []heading {
+	source := []byte(markdown)
+	doc := goldmark.New().Parser().Parse(text.NewReader(source))
+
+	var out []heading
+	_ = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		h, ok := n.(*ast.Heading)
+		if !ok {
+			return ast.WalkContinue, nil
+		}
+
+		t := strings.TrimSpace(nodeText(h, source))
+		if t == "" {
+			return ast.WalkSkipChildren, nil
+		}
+		sourceLine := 0
+		if lines := h.Lines(); lines.Len() > 0 {
+			sourceLine = bytes.Count(source[:lines.At(0).Start], []byte("\n")) + 1
+		}
+		out = append(out, heading{
+			Level:      h.Level,
+			Text:       t,
+			Slug:       headingSlug(t),
+			Line:       -1,
+			SourceLine: sourceLine,
+		})
+		return ast.WalkSkipChildren, nil
+	})
+
+	return out
+}
+
+// nodeText returns the concatenated text of all text nodes below n.
+func nodeText(n ast.Node, source []byte) string {
+	var b strings.Builder
+	_ = ast.Walk(n, func(child ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		switch t := child.(type) {
+		case *ast.Text:
+			b.Write(t.Segment.Value(source))
+			if t.SoftLineBreak() {
+				b.WriteByte(' ')
+			}
+		case *ast.String:
+			b.Write(t.Value)
+		}
+		return ast.WalkContinue, nil
+	})
+	return b.String()
+}
+
+// headingSlug returns the GitHub-style anchor for a heading: lowercased,
+// spaces turned into hyphens and punctuation removed.
+func headingSlug(s string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(strings.TrimSpace(s)) {
+		switch {
+		case unicode.IsLetter(r), unicode.IsDigit(r), r == '-', r == '_':
+			b.WriteRune(r)
+		case r == ' ':
+			b.WriteRune('-')
+		}
+	}
+	return b.String()
+}
+
+// locateHeadings sets the rendered line of each heading by searching the
+// rendered output top to bottom.
+func locateHeadings(rendered string, headings []heading) {
+	lines := strings.Split(stripANSI(rendered), "\n")
+
+	from := 0
+	for i := range headings {
+		headings[i].Line = -1
+		// Shortcodes may have been rendered as emoji.
+		text, emoji := headings[i].Text, expandEmojiText(headings[i].Text)
+		for l := from; l < len(lines); l++ {
+			if lineContainsHeading(lines[l], text) || (emoji != text && lineContainsHeading(lines[l], emoji)) {
+				headings[i].Line = l
+				from = l + 1
+				break
+			}
+		}
+	}
+}
+
+func lineContainsHeading(line, text string) bool {
+	if strings.Contains(line, text) {
+		return true
+	}
+
+	// Long headings may have been wrapped, in which case we only see the
+	// start of it on this line.
+	t := strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(line), "#"))
+	return len(t) > 1 && strings.HasPrefix(text, t)
+}
+
+// How to treat headings that produce the same anchor.
+const (
+	duplicateSlugsSuffix = "suffix" // GitHub-style: title, title-1, title-2
+	duplicateSlugsFirst  = "first"  // every anchor resolves to the first one
+)
+
+// disambiguateSlugs appends -1, -2, etc. to repeated anchors the same way
+// GitHub does, so #title-1 refers to the second heading titled "Title".
+func disambiguateSlugs(headings []heading) {
+	seen := map[string]int{}
+	for i := range headings {
+		slug := headings[i].Slug
+		n, ok := seen[slug]
+		if !ok {
+			seen[slug] = 0
+			continue
+		}
+		for {
+			n++
+			candidate := fmt.Sprintf("%s-%d", slug, n)
+			if _, taken := seen[candidate]; !taken {
+				seen[slug] = n
+				seen[candidate] = 0
+				headings[i].Slug = candidate
+				break
+			}
+		}
+	}
+}
+
+// documentHeadings extracts the headings of a markdown document and locates
+// them in its rendered output.
+func documentHeadings(markdown, rendered, duplicateSlugs string) []heading {
+	headings := extractHeadings(markdown)
+	if duplicateSlugs != duplicateSlugsFirst {
+		disambiguateSlugs(headings)
+	}
+	locateHeadings(rendered, headings)
+	return headings
+}
+
+// shortcodeSlugs gives headings the anchors they'd have before emoji
+// shortcodes were expanded, so that "## :rocket: Launch" is #rocket-launch
+// like on GitHub. The markdown is the document before expanding them.
+func shortcodeSlugs(headings []heading, markdown, duplicateSlugs string) {
+	source := extractHeadings(markdown)
+	if len(source) != len(headings) {
+		return
+	}
+	if duplicateSlugs != duplicateSlugsFirst {
+		disambiguateSlugs(source)
+	}
+	for i := range headings {
+		headings[i].Slug = source[i].Slug
+	}
+}
+
+// renderedHeadingLines returns the set of rendered lines that hold the
+// headings of a markdown document.
+func renderedHeadingLines(markdown, rendered string) map[int]bool {
+	headings := extractHeadings(markdown)
+	locateHeadings(rendered, headings)
+
+	lines := make(map[int]bool, len(headings))
+	for _, h := range headings {
+		if h.Line >= 0 {
+			lines[h.Line] = true
+		}
+	}
+	return lines
+}
+
+// headingGutterWidth is the width of the column of the gutter used for
+// heading indicators: the indicator and a space. It comes after the line
+// numbers, if those are shown, so they can still be read off the gutter.
+func headingGutterWidth(indicator string) int {
+	return ansi.PrintableRuneWidth(indicator) + 1
+}
+
+// headingGutter returns the heading indicator column of the gutter of a line,
+// blank unless the line holds a heading.
+func headingGutter(indicator string, heading bool) string {
+	if !heading {
+		return strings.Repeat(" ", headingGutterWidth(indicator))
+	}
+	return indicator + " "
+}
+
+// sourceLineForSlug returns the source line of the heading with the given
+// anchor in a markdown document, or 0 if there's none.
+func sourceLineForSlug(markdown, slug, duplicateSlugs string) int {
+	headings := extractHeadings(markdown)
+	if duplicateSlugs != duplicateSlugsFirst {
+		disambiguateSlugs(headings)
+	}
+	slug = strings.ToLower(strings.TrimPrefix(slug, "#"))
+	for _, h := range headings {
+		if h.Slug == slug {
+			return h.SourceLine
+		}
+	}
+	return 0
+}
+
+// headingForSlug returns the index of the heading with the given anchor, or
+// -1 if there's none.
+func headingForSlug(headings []heading, slug string) int {
+	slug = strings.ToLower(strings.TrimPrefix(slug, "#"))
+	for i, h := range headings {
+		if h.Slug == slug && h.Line >= 0 {
+			return i
+		}
+	}
+	return -1
+}
+
+// tocOverlayItems lists headings as a table of contents, indented by their
+// depth relative to the topmost level in the document.
+func tocOverlayItems(headings []heading) []overlayItem {
+	top := 0
+	for _, h := range headings {
+		if top == 0 || h.Level < top {
+			top = h.Level
+		}
+	}
+	items := make([]overlayItem, len(headings))
+	for i, h := range headings {
+		items[i] = overlayItem{Label: strings.Repeat("  ", h.Level-top) + h.Text}
+	}
+	return items
+}
+
+// headingAt returns the last heading at or above the given rendered line,
+// which is the section being read, or 0 if there's none.
+func headingAt(headings []heading, line int) int {
+	current := 0
+	for i, h := range headings {
+		if h.Line >= 0 && h.Line <= line {
+			current = i
+		}
+	}
+	return current
+}
+
+// jumpToNextHeading scrolls to the next heading below the top of the
+// viewport, or the previous one above it if dir is negative.
+func (m *pagerModel) jumpToNextHeading(dir int) tea.Cmd {
+	if len(m.headings) == 0 {
+		return m.showStatusMessage(pagerStatusMessage{"No headings", false})
+	}
+
+	top := m.viewport.YOffset
+	target := -1
+	for i, h := range m.headings {
+		if h.Line < 0 {
+			continue
+		}
+		if dir > 0 && h.Line > top {
+			target = i
+			break
+		}
+		if dir < 0 && h.Line < top {
+			target = i
+		}
+	}
+	if target < 0 {
+		msg := "No more headings below"
+		if dir < 0 {
+			msg = "No more headings above"
+		}
+		return m.showStatusMessage(pagerStatusMessage{msg, false})
+	}
+	return m.jumpToHeading(target)
+}
@@ -0,0 +1,168 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHeadingSlug(t *testing.T) {
+	cases := map[string]string{
+		"Title":                 "title",
+		"Getting Started":       "getting-started",
+		"What's new in v2.0?":   "whats-new-in-v20",
+		"snake_case and-dashes": "snake_case-and-dashes",
+	}
+	for in, want := range cases {
+		if got := headingSlug(in); got != want {
+			t.Errorf("headingSlug(%q): expected %q, got %q", in, want, got)
+		}
+	}
+}
+
+func TestDuplicateHeadingSlugs(t *testing.T) {
+	md := "# Title\n\none\n\n# Title\n\ntwo\n\n# Title\n\nthree\n"
+	rendered := "Title\none\nTitle\ntwo\nTitle\nthree"
+
+	t.Run("suffix", func(t *testing.T) {
+		headings := documentHeadings(md, rendered, duplicateSlugsSuffix)
+		for slug, wantLine := range map[string]int{
+			"title":    0,
+			"title-1":  2,
+			"#title-2": 4,
+		} {
+			i := headingForSlug(headings, slug)
+			if i < 0 {
+				t.Fatalf("expected %q to resolve to a heading", slug)
+			}
+			if headings[i].Line != wantLine {
+				t.Fatalf("expected %q to resolve to line %d, got %d", slug, wantLine, headings[i].Line)
+			}
+		}
+		if i := headingForSlug(headings, "title-3"); i >= 0 {
+			t.Fatalf("expected title-3 not to resolve, got heading %d", i)
+		}
+	})
+
+	t.Run("first", func(t *testing.T) {
+		headings := documentHeadings(md, rendered, duplicateSlugsFirst)
+		i := headingForSlug(headings, "title")
+		if i < 0 || headings[i].Line != 0 {
+			t.Fatalf("expected title to resolve to the first heading, got %d", i)
+		}
+		if i := headingForSlug(headings, "title-1"); i >= 0 {
+			t.Fatalf("expected title-1 not to resolve, got heading %d", i)
+		}
+	})
+
+	t.Run("literal_suffix_is_not_reused", func(t *testing.T) {
+		headings := extractHeadings("# Title\n\n# Title-1\n\n# Title\n")
+		disambiguateSlugs(headings)
+		want := []string{"title", "title-1", "title-2"}
+		for i, h := range headings {
+			if h.Slug != want[i] {
+				t.Fatalf("heading %d: expected slug %q, got %q", i, want[i], h.Slug)
+			}
+		}
+	})
+}
+
+func TestSourceLineForSlug(t *testing.T) {
+	md := "# Intro\n\ntext\n\n## Usage\n\nmore\n\nUsage\n-----\n"
+
+	cases := []struct {
+		slug           string
+		duplicateSlugs string
+		want           int
+	}{
+		{"intro", duplicateSlugsSuffix, 1},
+		{"#usage", duplicateSlugsSuffix, 5},
+		{"usage-1", duplicateSlugsSuffix, 9},
+		{"usage-1", duplicateSlugsFirst, 0},
+		{"missing", duplicateSlugsSuffix, 0},
+	}
+	for _, tc := range cases {
+		if got := sourceLineForSlug(md, tc.slug, tc.duplicateSlugs); got != tc.want {
+			t.Errorf("sourceLineForSlug(%q, %s): expected %d, got %d", tc.slug, tc.duplicateSlugs, tc.want, got)
+		}
+	}
+}
+
+func TestTableOfContents(t *testing.T) {
+	filler := strings.Repeat("Some text.\n\n", 30)
+	body := "## Intro\n\n" + filler + "### Details\n\n" + filler + "## Usage\n\n" + filler
+
+	items := tocOverlayItems(extractHeadings(body))
+	var labels []string
+	for _, it := range items {
+		labels = append(labels, it.Label)
+	}
+	if want := []string{"Intro", "  Details", "Usage"}; strings.Join(labels, "|") != strings.Join(want, "|") {
+		t.Fatalf("expected items %q, got %q", want, labels)
+	}
+
+	m := newTestPager(t, Config{}, "README.md", 80)
+	m.currentDocument.Body = body
+	rendered, err := glamourRender(m, body)
+	if err != nil {
+		t.Fatalf("glamourRender returned error: %v", err)
+	}
+	m.rendered = rendered
+	m.applyRenderedContent()
+	m.headings = documentHeadings(body, rendered, duplicateSlugsSuffix)
+
+	m = typeKeys(t, m, "T")
+	if m.overlay == nil || m.overlay.kind != overlayTOC || m.overlay.cursor != 0 {
+		t.Fatal("expected the table of contents to open on the first heading")
+	}
+	m = typeKeys(t, m, "j", keyEnter)
+	if m.overlay != nil {
+		t.Fatal("expected enter to close the table of contents")
+	}
+	if m.viewport.YOffset != m.headings[1].Line {
+		t.Fatalf("expected to jump to line %d, got offset %d", m.headings[1].Line, m.viewport.YOffset)
+	}
+
+	// It opens on the section being read.
+	m = typeKeys(t, m, "j", "T")
+	if m.overlay.cursor != 1 {
+		t.Fatalf("expected the cursor on the current section, got %d", m.overlay.cursor)
+	}
+}
+
+func TestJumpToNextHeading(t *testing.T) {
+	filler := strings.Repeat("Some text.\n\n", 30)
+	body := "# Title\n\n" + filler + "## One\n\n" + filler + "## Two\n\n" + filler
+
+	m := newTestPager(t, Config{}, "README.md", 80)
+	m.currentDocument.Body = body
+	rendered, err := glamourRender(m, body)
+	if err != nil {
+		t.Fatalf("glamourRender returned error: %v", err)
+	}
+	m, _ = m.update(contentRenderedMsg(rendered))
+	if len(m.headings) != 3 {
+		t.Fatalf("expected the headings to be located on render, got %+v", m.headings)
+	}
+
+	for i := range m.headings {
+		m = typeKeys(t, m, "]", "]")
+		if m.viewport.YOffset != m.headings[i].Line {
+			t.Fatalf("expected ]] to jump to line %d, got offset %d", m.headings[i].Line, m.viewport.YOffset)
+		}
+	}
+	m = typeKeys(t, m, "[", "k", "[")
+	if m.viewport.YOffset != m.headings[2].Line-1 {
+		t.Fatalf("expected [ followed by another key not to jump, got offset %d", m.viewport.YOffset)
+	}
+	m = typeKeys(t, m, "j")
+
+	m = typeKeys(t, m, "]", "]")
+	if m.viewport.YOffset != m.headings[2].Line || m.statusMessage != "No more headings below" {
+		t.Fatalf("expected a message past the last heading, got offset %d and %q", m.viewport.YOffset, m.statusMessage)
+	}
+
+	m = typeKeys(t, m, "[", "[")
+	if m.viewport.YOffset != m.headings[1].Line {
+		t.Fatalf("expected [[ to jump to line %d, got offset %d", m.headings[1].Line, m.viewport.YOffset)
+	}
+}
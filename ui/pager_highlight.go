@@ -2,43 +2,58 @@ package ui
 
 import (
 	"strings"
+	"unicode"
 	"unicode/utf8"
+
+	"github.com/charmbracelet/lipgloss"
 )
 
-func highlightFocusedLink(rendered string, links []followableLink, focused int) string {
-	if focused < 0 || focused >= len(links) {
-		return rendered
-	}
+// linkSpan is where a link's label sits in the rendered output.
+type linkSpan struct {
+	start int
+	end   int
+	ok    bool
+}
+
+// linkSpans locates the labels of the links in the rendered output. Labels
+// are searched for in order, so each link maps to the occurrence after the
+// previous link's. Occurrences of labels that belong to other links, which
+// aren't in the list because they can't be followed, are skipped.
+func linkSpans(rendered string, links []followableLink) []linkSpan {
+	spans := make([]linkSpan, len(links))
 
 	printable, offsets := printableRunesAndOffsets(rendered)
 	if len(printable) == 0 {
-		return rendered
+		return spans
 	}
 	printableStr := string(printable)
 
-	type span struct {
-		start int
-		end   int
-		ok    bool
-	}
-
-	spans := make([]span, len(links))
 	searchFrom := 0
+	used := map[string]int{}
 	for i, l := range links {
 		label := strings.TrimSpace(l.Label)
 		if label == "" || searchFrom >= len(printableStr) {
 			continue
 		}
 
-		relIdx := strings.Index(printableStr[searchFrom:], label)
-		if relIdx < 0 {
+		byteIdx, from, length := -1, searchFrom, 0
+		for skip := max(0, l.Occurrence-used[label]); skip >= 0; skip-- {
+			relIdx, n := indexLabel(printableStr[from:], label)
+			if relIdx < 0 {
+				byteIdx = -1
+				break
+			}
+			byteIdx, length = from+relIdx, n
+			from = byteIdx + n
+		}
+		if byteIdx < 0 {
 			continue
 		}
-		byteIdx := searchFrom + relIdx
-		searchFrom = byteIdx + len(label)
+		searchFrom = from
+		used[label] = l.Occurrence + 1
 
 		startRune := utf8.RuneCountInString(printableStr[:byteIdx])
-		endRune := startRune + utf8.RuneCountInString(label)
+		endRune := startRune + utf8.RuneCountInString(printableStr[byteIdx:byteIdx+length])
 		if startRune < 0 || endRune > len(offsets)-1 {
 			continue
 		}
@@ -49,29 +64,206 @@ func highlightFocusedLink(rendered string, links []followableLink, focused int)
 			continue
 		}
 
-		spans[i] = span{start: startByte, end: endByte, ok: true}
+		spans[i] = linkSpan{start: startByte, end: endByte, ok: true}
+	}
+	return spans
+}
+
+// indexLabel returns the byte offset and length of the first occurrence of a
+// link label in s, or -1. The words of the label may be separated by any
+// amount of whitespace, as formatting like code spans adds padding and long
+// labels can wrap.
+func indexLabel(s, label string) (int, int) {
+	words := strings.Fields(label)
+	if len(words) < 2 {
+		return strings.Index(s, label), len(label)
+	}
+	for from := 0; ; {
+		i := strings.Index(s[from:], words[0])
+		if i < 0 {
+			return -1, 0
+		}
+		i += from
+		if end, ok := wordsFollow(s, i+len(words[0]), words[1:]); ok {
+			return i, end - i
+		}
+		from = i + 1
 	}
+}
 
-	s := spans[focused]
+// wordsFollow reports whether s has the given words from offset at on, each
+// after some whitespace, and returns where the last one ends.
+func wordsFollow(s string, at int, words []string) (int, bool) {
+	for _, w := range words {
+		rest := strings.TrimLeftFunc(s[at:], unicode.IsSpace)
+		if len(rest) == len(s)-at || !strings.HasPrefix(rest, w) {
+			return 0, false
+		}
+		at = len(s) - len(rest) + len(w)
+	}
+	return at, true
+}
+
+func highlightFocusedLink(rendered string, links []followableLink, focused int, style spanStyle) string {
+	if focused < 0 || focused >= len(links) {
+		return rendered
+	}
+
+	s := linkSpans(rendered, links)[focused]
 	if !s.ok {
 		return rendered
 	}
 
-	const (
-		reverseOn  = "\x1b[7m"
-		reverseOff = "\x1b[27m"
-	)
+	return styleSpan(rendered, s.start, s.end, style)
+}
+
+// linkLines returns the rendered line each link starts on, or -1 for links
+// that couldn't be located.
+func linkLines(rendered string, links []followableLink) []int {
+	lines := make([]int, len(links))
+	line, from := 0, 0
+	for i, s := range linkSpans(rendered, links) {
+		if !s.ok {
+			lines[i] = -1
+			continue
+		}
+		// Spans are in document order, so keep counting from the last one.
+		line += strings.Count(rendered[from:s.start], "\n")
+		from = s.start
+		lines[i] = line
+	}
+	return lines
+}
+
+// linkLine returns the rendered line the given link starts on, or -1.
+func linkLine(rendered string, links []followableLink, i int) int {
+	if i < 0 || i >= len(links) {
+		return -1
+	}
+	return linkLines(rendered, links)[i]
+}
+
+const (
+	reverseOn  = "\x1b[7m"
+	reverseOff = "\x1b[27m"
+)
+
+// spanStyle is a pair of escape sequences that turn a highlight on and off.
+type spanStyle struct {
+	on  string
+	off string
+}
+
+var reverseSpan = spanStyle{on: reverseOn, off: reverseOff}
+
+// colorSpan returns a highlight with explicit foreground and background
+// colors, degraded to what the terminal supports. Without any usable color
+// it falls back to reverse video.
+func colorSpan(fg, bg string) spanStyle {
+	profile := lipgloss.ColorProfile()
+
+	var params []string
+	if fg != "" {
+		if seq := profile.Color(fg).Sequence(false); seq != "" {
+			params = append(params, seq)
+		}
+	}
+	if bg != "" {
+		if seq := profile.Color(bg).Sequence(true); seq != "" {
+			params = append(params, seq)
+		}
+	}
+	if len(params) == 0 {
+		return reverseSpan
+	}
+	return spanStyle{
+		on:  "\x1b[" + strings.Join(params, ";") + "m",
+		off: "\x1b[39;49m",
+	}
+}
+
+// highlightSpan renders the bytes between start and end in reverse video.
+func highlightSpan(rendered string, start, end int) string {
+	return styleSpan(rendered, start, end, reverseSpan)
+}
 
+// styleSpan applies style to the bytes between start and end. The style is
+// re-enabled after every escape sequence inside the span so that resets
+// emitted by glamour don't cut the highlight short.
+func styleSpan(rendered string, start, end int, style spanStyle) string {
 	var b strings.Builder
-	b.Grow(len(rendered) + len(reverseOn) + len(reverseOff))
-	b.WriteString(rendered[:s.start])
-	b.WriteString(reverseOn)
-	b.WriteString(rendered[s.start:s.end])
-	b.WriteString(reverseOff)
-	b.WriteString(rendered[s.end:])
+	b.Grow(len(rendered) + len(style.on) + len(style.off))
+	b.WriteString(rendered[:start])
+	b.WriteString(style.on)
+
+	span := rendered[start:end]
+	for i := 0; i < len(span); {
+		if span[i] == 0x1b && i+1 < len(span) && span[i+1] == '[' {
+			j := i + 2
+			for j < len(span) {
+				c := span[j]
+				j++
+				if c >= 0x40 && c <= 0x7E {
+					break
+				}
+			}
+			b.WriteString(span[i:j])
+			b.WriteString(style.on)
+			i = j
+			continue
+		}
+		b.WriteByte(span[i])
+		i++
+	}
+
+	b.WriteString(style.off)
+	b.WriteString(rendered[end:])
 	return b.String()
 }
 
+// highlightLine renders the printable contents of the given line in reverse
+// video, leaving leading and trailing whitespace alone.
+func highlightLine(rendered string, line int) string {
+	if line < 0 {
+		return rendered
+	}
+
+	start := 0
+	for i := 0; i < line; i++ {
+		n := strings.IndexByte(rendered[start:], '\n')
+		if n < 0 {
+			return rendered
+		}
+		start += n + 1
+	}
+	end := len(rendered)
+	if n := strings.IndexByte(rendered[start:], '\n'); n >= 0 {
+		end = start + n
+	}
+
+	printable, offsets := printableRunesAndOffsets(rendered[start:end])
+	first, last := -1, -1
+	for i, r := range printable {
+		if r != ' ' {
+			if first < 0 {
+				first = i
+			}
+			last = i
+		}
+	}
+	if first < 0 {
+		return rendered
+	}
+
+	return highlightSpan(rendered, start+offsets[first], start+offsets[last+1])
+}
+
+// stripANSI removes ANSI escape sequences from s.
+func stripANSI(s string) string {
+	printable, _ := printableRunesAndOffsets(s)
+	return string(printable)
+}
+
 func printableRunesAndOffsets(s string) ([]rune, []int) {
 	var (
 		runes   []rune
@@ -0,0 +1,82 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+func TestHighlightLine(t *testing.T) {
+	rendered := "first\n  \x1b[1m## Heading\x1b[0m  \nlast"
+
+	got := highlightLine(rendered, 1)
+	lines := strings.Split(got, "\n")
+	if len(lines) != 3 {
+		t.Fatalf("expected 3 lines, got %d", len(lines))
+	}
+	if lines[0] != "first" || lines[2] != "last" {
+		t.Fatalf("expected other lines to be untouched, got %q", got)
+	}
+	if !strings.HasPrefix(lines[1], "  \x1b[1m"+reverseOn+"## Heading") {
+		t.Fatalf("expected highlight to start at the first printable rune, got %q", lines[1])
+	}
+	if !strings.Contains(lines[1], "\x1b[0m"+reverseOn) {
+		t.Fatalf("expected highlight to survive resets inside the span, got %q", lines[1])
+	}
+	if !strings.HasSuffix(lines[1], reverseOff+"  ") {
+		t.Fatalf("expected trailing whitespace to be left alone, got %q", lines[1])
+	}
+	if stripANSI(got) != stripANSI(rendered) {
+		t.Fatalf("expected printable text to be unchanged, got %q", stripANSI(got))
+	}
+
+	if got := highlightLine(rendered, 5); got != rendered {
+		t.Fatalf("expected out of range line to be a no-op, got %q", got)
+	}
+}
+
+func TestColorSpan(t *testing.T) {
+	orig := lipgloss.ColorProfile()
+	lipgloss.SetColorProfile(termenv.ANSI256)
+	t.Cleanup(func() { lipgloss.SetColorProfile(orig) })
+
+	if got := colorSpan("", ""); got != reverseSpan {
+		t.Fatalf("expected reverse video without colors, got %q", got)
+	}
+
+	style := colorSpan("231", "#000000")
+	if style.on != "\x1b[38;5;231;48;5;16m" {
+		t.Fatalf("unexpected highlight sequence %q", style.on)
+	}
+
+	rendered := "a \x1b[4mlink\x1b[0m here"
+	start := strings.Index(rendered, "\x1b[4m")
+	end := strings.Index(rendered, " here")
+	got := styleSpan(rendered, start, end, style)
+	if !strings.Contains(got, "\x1b[0m"+style.on) {
+		t.Fatalf("expected colors to survive resets inside the span, got %q", got)
+	}
+	if !strings.HasSuffix(got, style.off+" here") {
+		t.Fatalf("expected colors to be turned off after the span, got %q", got)
+	}
+}
+
+func TestIndexLabel(t *testing.T) {
+	for _, tc := range []struct {
+		s, label string
+		i, n     int
+	}{
+		{"see docs here", "docs", 4, 4},
+		{"the  user\n  guide  ", "user guide", 5, 12},
+		{"a user, a user guide", "user guide", 10, 10},
+		{"userguide", "user guide", -1, 0},
+		{"a.b c", "a.b c", 0, 5},
+		{"axb c", "a.b c", -1, 0},
+	} {
+		if i, n := indexLabel(tc.s, tc.label); i != tc.i || n != tc.n {
+			t.Errorf("indexLabel(%q, %q) = %d, %d, want %d, %d", tc.s, tc.label, i, n, tc.i, tc.n)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package ui
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mattn/go-runewidth"
+)
+
+// linkHints is the number typed so far while link hints are shown.
+type linkHints struct {
+	input string
+}
+
+// drawLinkHints writes the number of each link, starting at 1, over the start
+// of its label in the rendered output. Hints replace text rather than being
+// inserted so that lines keep their width.
+func drawLinkHints(rendered string, links []followableLink, style spanStyle) string {
+	spans := linkSpans(rendered, links)
+
+	// Work from the end so the offsets of earlier spans stay valid.
+	for i := len(spans) - 1; i >= 0; i-- {
+		if !spans[i].ok {
+			continue
+		}
+		rendered = overwriteText(rendered, spans[i].start, strconv.Itoa(i+1), style)
+	}
+	return rendered
+}
+
+// overwriteText replaces the printable characters at the given byte offset
+// with s in the given style, leaving escape sequences in place. It doesn't go
+// past the end of the line.
+func overwriteText(rendered string, start int, s string, style spanStyle) string {
+	var b strings.Builder
+	b.WriteString(rendered[:start])
+	b.WriteString(style.on)
+
+	i, cells := start, runewidth.StringWidth(s)
+	for cells > 0 && i < len(rendered) && rendered[i] != '\n' {
+		if rendered[i] == 0x1b && i+1 < len(rendered) && rendered[i+1] == '[' {
+			j := i + 2
+			for j < len(rendered) {
+				c := rendered[j]
+				j++
+				if c >= 0x40 && c <= 0x7E {
+					break
+				}
+			}
+			b.WriteString(rendered[i:j])
+			b.WriteString(style.on)
+			i = j
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(rendered[i:])
+		cells -= max(1, runewidth.RuneWidth(r))
+		i += size
+	}
+	// Wide characters can leave a cell to fill.
+	b.WriteString(s + strings.Repeat(" ", max(0, -cells)))
+
+	b.WriteString(style.off)
+	b.WriteString(rendered[i:])
+	return b.String()
+}
+
+func (m *pagerModel) startLinkHints() tea.Cmd {
+	if len(m.links) == 0 {
+		return m.showStatusMessage(pagerStatusMessage{"No followable links", false})
+	}
+	m.hints = &linkHints{}
+	m.applyRenderedContent()
+	return m.showStatusMessage(pagerStatusMessage{"Type a link number", false})
+}
+
+func (m *pagerModel) stopLinkHints() {
+	m.hints = nil
+	m.applyRenderedContent()
+}
+
+// updateLinkHints handles key presses while link hints are shown. A link is
+// followed as soon as the number typed can't be the start of another one.
+func (m *pagerModel) updateLinkHints(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case keyEsc, "q":
+		m.stopLinkHints()
+		return nil
+	case keyBackspace:
+		m.hints.input = m.hints.input[:max(0, len(m.hints.input)-1)]
+		return nil
+	case keyEnter:
+		return m.followHint()
+	}
+
+	r := msg.Runes
+	if msg.Type != tea.KeyRunes || len(r) != 1 || r[0] < '0' || r[0] > '9' {
+		return nil
+	}
+	m.hints.input += string(r)
+
+	n, _ := strconv.Atoi(m.hints.input)
+	if n < 1 || n > len(m.links) {
+		input := m.hints.input
+		m.stopLinkHints()
+		return m.showStatusMessage(pagerStatusMessage{"No link " + input, false})
+	}
+	if n*10 > len(m.links) {
+		return m.followHint()
+	}
+	return m.showStatusMessage(pagerStatusMessage{fmt.Sprintf("Link %d…", n), false})
+}
+
+// followHint follows the link with the number typed so far.
+func (m *pagerModel) followHint() tea.Cmd {
+	n, err := strconv.Atoi(m.hints.input)
+	m.stopLinkHints()
+	if err != nil || n < 1 || n > len(m.links) {
+		return nil
+	}
+	m.focusedLink = n - 1
+	return m.followFocusedLink()
+}
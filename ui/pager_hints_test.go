@@ -0,0 +1,79 @@
+package ui
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDrawLinkHints(t *testing.T) {
+	links := []followableLink{{Label: "alpha"}, {Label: "b"}, {Label: "missing"}}
+	rendered := "See \x1b[1malpha\x1b[0m and b.\nMore text."
+
+	got := drawLinkHints(rendered, links, spanStyle{on: "<", off: ">"})
+	if want := "See \x1b[1m<1>lpha\x1b[0m and <2>.\nMore text."; got != want {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+
+	// Hints don't run past the end of the line.
+	got = overwriteText("ab\ncd", 1, "12", spanStyle{})
+	if want := "a12\ncd"; got != want {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+}
+
+func TestLinkHints(t *testing.T) {
+	root := absEvalSymlinks(t, t.TempDir())
+	current := filepath.Join(root, "index.md")
+
+	var body strings.Builder
+	for i := 1; i <= 12; i++ {
+		fmt.Fprintf(&body, "- [Note %d](note%d.md#n%d)\n", i, i, i)
+		mustWriteFile(t, filepath.Join(root, fmt.Sprintf("note%d.md", i)), "")
+	}
+	mustWriteFile(t, current, body.String())
+
+	load := func() pagerModel {
+		m := newTestPager(t, Config{}, "index.md", 80)
+		m.common.cwd = root
+		m.currentDocument = markdown{localPath: current, Note: "index.md", Body: body.String()}
+		m.extractLinks()
+		return m
+	}
+
+	m := typeKeys(t, load(), "H")
+	if m.hints == nil {
+		t.Fatal("expected link hints to be shown")
+	}
+	m = typeKeys(t, m, keyEsc)
+	if m.hints != nil || len(m.history) != 0 || m.focusedLink != -1 {
+		t.Fatal("expected esc to dismiss the hints without following a link")
+	}
+
+	// Numbers that can't start another one are followed right away.
+	m = typeKeys(t, load(), "H", "3")
+	if m.hints != nil || len(m.history) != 1 || m.pendingFragment != "n3" {
+		t.Fatalf("expected link 3 to be followed, got fragment %q", m.pendingFragment)
+	}
+
+	// 1 could be the start of 10, 11 or 12.
+	m = typeKeys(t, load(), "H", "1")
+	if m.hints == nil {
+		t.Fatal("expected to wait for a second digit")
+	}
+	m = typeKeys(t, m, "2")
+	if m.hints != nil || m.pendingFragment != "n12" {
+		t.Fatalf("expected link 12 to be followed, got fragment %q", m.pendingFragment)
+	}
+
+	m = typeKeys(t, load(), "H", "1", keyEnter)
+	if m.hints != nil || m.pendingFragment != "n1" {
+		t.Fatalf("expected enter to follow link 1, got fragment %q", m.pendingFragment)
+	}
+
+	m = typeKeys(t, load(), "H", "0")
+	if m.hints != nil || m.statusMessage != "No link 0" {
+		t.Fatalf("unexpected status %q", m.statusMessage)
+	}
+}
@@ -0,0 +1,74 @@
+package ui
+
+import (
+	"os"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// idleCheckMsg is sent when it's time to check whether the pager has been
+// idle long enough to stop watching the document.
+type idleCheckMsg struct{}
+
+// scheduleIdleCheck arranges for an idle check after the given delay, unless
+// one is already on its way.
+func (m *pagerModel) scheduleIdleCheck(after time.Duration) tea.Cmd {
+	if m.common.cfg.WatchIdleTimeout <= 0 || m.idleCheckPending {
+		return nil
+	}
+	m.idleCheckPending = true
+	return tea.Tick(after, func(time.Time) tea.Msg {
+		return idleCheckMsg{}
+	})
+}
+
+// noteActivity records that the user did something. If we stopped watching
+// the document in the meantime, we start again, reloading it first if it
+// changed while we weren't looking.
+func (m *pagerModel) noteActivity() tea.Cmd {
+	timeout := m.common.cfg.WatchIdleTimeout
+	if timeout <= 0 {
+		return nil
+	}
+	m.lastActivity = time.Now()
+	if !m.idle {
+		return m.scheduleIdleCheck(timeout)
+	}
+
+	m.idle = false
+	cmd := m.scheduleIdleCheck(timeout)
+	if m.watchPaused {
+		return cmd
+	}
+	if modTime(m.currentDocument.localPath) != m.idleModTime {
+		return tea.Batch(cmd, func() tea.Msg { return reloadMsg{} })
+	}
+	return tea.Batch(cmd, m.startWatching())
+}
+
+// checkIdle stops watching the document once there's been no activity for
+// the configured time.
+func (m *pagerModel) checkIdle() tea.Cmd {
+	m.idleCheckPending = false
+	timeout := m.common.cfg.WatchIdleTimeout
+	if timeout <= 0 || m.idle || m.watchedDir == "" {
+		return nil
+	}
+	if since := time.Since(m.lastActivity); since < timeout {
+		return m.scheduleIdleCheck(timeout - since)
+	}
+
+	m.idleModTime = modTime(m.currentDocument.localPath)
+	m.stopWatching()
+	m.idle = true
+	return nil
+}
+
+func modTime(path string) time.Time {
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
@@ -0,0 +1,148 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/log"
+)
+
+// How deep includes can be nested, as a backstop for cycles we fail to
+// detect, like ones through symlinks to directories.
+const maxIncludeDepth = 8
+
+// includePattern matches a line that includes another markdown file, like
+// <!-- include: docs/intro.md -->. Being a comment, it's invisible on code
+// hosting sites.
+var includePattern = regexp.MustCompile(`^\s*<!--\s*include:\s*(\S+?)\s*-->\s*$`)
+
+// expandIncludes replaces include lines in the markdown of the file at path
+// with the contents of the files they refer to, recursively. Files outside
+// of root aren't included. Problems, like cycles, are rendered as a marker
+// in place of the include.
+func expandIncludes(markdown, path, root string) string {
+	rootAbs, err := filepath.Abs(root)
+	if err != nil {
+		log.Debug("error resolving include root", "error", err)
+		return markdown
+	}
+	pathAbs, err := filepath.Abs(path)
+	if err != nil {
+		log.Debug("error resolving document path", "error", err)
+		return markdown
+	}
+	return spliceIncludes(markdown, evalSymlinksOrSelf(pathAbs), evalSymlinksOrSelf(rootAbs), nil, nil)
+}
+
+// includedFiles returns the files the markdown of the file at path includes,
+// recursively, including ones that are missing, so that we can tell when
+// any of them change.
+func includedFiles(markdown, path, root string) []string {
+	rootAbs, err := filepath.Abs(root)
+	if err != nil {
+		return nil
+	}
+	pathAbs, err := filepath.Abs(path)
+	if err != nil {
+		return nil
+	}
+	deps := map[string]bool{}
+	spliceIncludes(markdown, evalSymlinksOrSelf(pathAbs), evalSymlinksOrSelf(rootAbs), nil, deps)
+
+	out := make([]string, 0, len(deps))
+	for d := range deps {
+		out = append(out, d)
+	}
+	slices.Sort(out)
+	return out
+}
+
+// spliceIncludes expands the includes of a file given the stack of files
+// that are currently being included. If deps isn't nil, the files included
+// are added to it.
+func spliceIncludes(markdown, path, root string, stack []string, deps map[string]bool) string {
+	stack = append(stack, path)
+
+	var (
+		out     []string
+		inFence bool
+	)
+	for _, line := range strings.Split(markdown, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			inFence = !inFence
+		}
+		m := includePattern.FindStringSubmatch(line)
+		if inFence || m == nil {
+			out = append(out, line)
+			continue
+		}
+		out = append(out, includeFile(m[1], path, root, stack, deps))
+	}
+	return strings.Join(out, "\n")
+}
+
+func includeFile(name, from, root string, stack []string, deps map[string]bool) string {
+	target := evalSymlinksOrSelf(filepath.Join(filepath.Dir(from), name))
+
+	switch {
+	case !withinRoot(root, target):
+		return includeMarker("include outside root", name)
+	case slices.Contains(stack, target):
+		return includeMarker("circular include", name)
+	case len(stack) > maxIncludeDepth:
+		return includeMarker("include too deep", name)
+	}
+	if deps != nil {
+		deps[target] = true
+	}
+
+	data, err := os.ReadFile(target)
+	if err != nil {
+		log.Debug("error reading include", "path", target, "error", err)
+		return includeMarker("missing include", name)
+	}
+	return strings.TrimRight(spliceIncludes(string(data), target, root, stack, deps), "\n")
+}
+
+func includeMarker(problem, name string) string {
+	return fmt.Sprintf("\\[%s: %s\\]", problem, name)
+}
+
+// watchIncludes adds the directories of the files the document includes to
+// the watcher, and returns the set of those files.
+func (m *pagerModel) watchIncludes() map[string]bool {
+	doc := m.currentDocument
+	files := includedFiles(doc.Body, doc.localPath, m.common.cwd)
+	if len(files) == 0 {
+		return nil
+	}
+
+	own := m.watchedDir
+	if abs, err := filepath.Abs(own); err == nil {
+		own = evalSymlinksOrSelf(abs)
+	}
+	includes := make(map[string]bool, len(files))
+	for _, f := range files {
+		includes[f] = true
+		dir := filepath.Dir(f)
+		if dir == own {
+			// Events name files by the directory we watch.
+			includes[filepath.Join(m.watchedDir, filepath.Base(f))] = true
+			continue
+		}
+		if slices.Contains(m.watchedIncludeDirs, dir) {
+			continue
+		}
+		if err := m.watcher.Add(dir); err != nil {
+			log.Debug("error adding include dir to fsnotify watcher", "dir", dir, "error", err)
+			continue
+		}
+		m.watchedIncludeDirs = append(m.watchedIncludeDirs, dir)
+	}
+	return includes
+}
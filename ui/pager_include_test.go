@@ -0,0 +1,151 @@
+package ui
+
+import (
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestExpandIncludes(t *testing.T) {
+	base := absEvalSymlinks(t, t.TempDir())
+	root := filepath.Join(base, "root")
+	a := filepath.Join(root, "a.md")
+	mustWriteFile(t, a, "# A\n\n<!-- include: parts/b.md -->\n\nend of a\n")
+	mustWriteFile(t, filepath.Join(root, "parts", "b.md"), "b before\n<!-- include: ../a.md -->\nb after\n"+
+		"<!-- include: missing.md -->\n<!-- include: ../../secret.md -->\n")
+	mustWriteFile(t, filepath.Join(base, "secret.md"), "secret\n")
+
+	data := "# A\n\n<!-- include: parts/b.md -->\n\n```\n<!-- include: parts/b.md -->\n```\n"
+	got := expandIncludes(data, a, root)
+
+	for _, want := range []string{
+		"b before\n\\[circular include: ../a.md\\]\nb after",
+		"\\[missing include: missing.md\\]",
+		"\\[include outside root: ../../secret.md\\]",
+		"```\n<!-- include: parts/b.md -->\n```",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected %q in:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "secret\n") {
+		t.Errorf("expected files outside the root not to be included:\n%s", got)
+	}
+}
+
+func TestGlamourRender_CircularInclude(t *testing.T) {
+	root := absEvalSymlinks(t, t.TempDir())
+	a := filepath.Join(root, "a.md")
+	mustWriteFile(t, a, "Start of a.\n\n<!-- include: b.md -->\n")
+	mustWriteFile(t, filepath.Join(root, "b.md"), "Inside b.\n\n<!-- include: a.md -->\n")
+
+	m := newTestPager(t, Config{Includes: true}, "a.md", 80)
+	m.common.cwd = root
+	m.currentDocument.localPath = a
+
+	out, err := glamourRender(m, "Start of a.\n\n<!-- include: b.md -->\n")
+	if err != nil {
+		t.Fatalf("glamourRender returned error: %v", err)
+	}
+	out = stripANSI(out)
+	for _, want := range []string{"Start of a.", "Inside b.", "[circular include: a.md]"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in the rendered output:\n%s", want, out)
+		}
+	}
+}
+
+func TestWatchIncludes(t *testing.T) {
+	root := absEvalSymlinks(t, t.TempDir())
+	a := filepath.Join(root, "a.md")
+	b := filepath.Join(root, "parts", "b.md")
+	body := "# A\n\n<!-- include: parts/b.md -->\n<!-- include: c.md -->\n"
+	mustWriteFile(t, a, body)
+	mustWriteFile(t, b, "b before\n<!-- include: ../a.md -->\n<!-- include: missing.md -->\n")
+	mustWriteFile(t, filepath.Join(root, "c.md"), "c\n")
+
+	got := includedFiles(body, a, root)
+	want := []string{filepath.Join(root, "c.md"), b, filepath.Join(root, "parts", "missing.md")}
+	if !slices.Equal(got, want) {
+		t.Fatalf("expected included files %q, got %q", want, got)
+	}
+
+	m := newTestPager(t, Config{Includes: true}, "a.md", 80)
+	if m.watcher == nil {
+		t.Skip("no fsnotify watcher available")
+	}
+	t.Cleanup(m.stopWatching)
+	m.common.cwd = root
+	m.currentDocument.localPath = a
+	m.currentDocument.Body = body
+
+	// Included files aren't watched unless asked to.
+	_ = m.startWatching()
+	if len(m.watchedIncludeDirs) != 0 {
+		t.Fatalf("expected only the document to be watched, got %q", m.watchedIncludeDirs)
+	}
+
+	m.common.cfg.WatchIncludes = true
+	for _, change := range []struct {
+		name string
+		do   func()
+	}{
+		{"included file in another directory", func() { mustWriteFile(t, b, "b changed\n") }},
+		{"included file next to the document", func() { mustWriteFile(t, filepath.Join(root, "c.md"), "c changed\n") }},
+	} {
+		cmd := m.startWatching()
+		if !slices.Equal(m.watchedIncludeDirs, []string{filepath.Join(root, "parts")}) {
+			t.Fatalf("expected the directory of included files to be watched, got %q", m.watchedIncludeDirs)
+		}
+		msgs := make(chan tea.Msg, 1)
+		go func() { msgs <- cmd() }()
+		change.do()
+		select {
+		case msg := <-msgs:
+			if _, ok := msg.(includeChangedMsg); !ok {
+				t.Fatalf("%s: expected the change to be noticed, got %T", change.name, msg)
+			}
+			m, _ = m.update(msg)
+			if !m.reloading {
+				t.Fatalf("%s: expected the document to be reloaded", change.name)
+			}
+			m.reloading = false
+		case <-time.After(5 * time.Second):
+			t.Fatalf("%s: timed out waiting for the watcher", change.name)
+		}
+	}
+
+	m.stopWatching()
+	if len(m.watchedIncludeDirs) != 0 {
+		t.Fatal("expected included files to be unwatched")
+	}
+}
+
+func TestIncludedHeadings(t *testing.T) {
+	root := absEvalSymlinks(t, t.TempDir())
+	a := filepath.Join(root, "a.md")
+	body := "# A\n\n<!-- include: b.md -->\n"
+	mustWriteFile(t, a, body)
+	mustWriteFile(t, filepath.Join(root, "b.md"), "## Included\n\n```go\nfunc b() {}\n```\n")
+
+	m := newTestPager(t, Config{Includes: true}, "a.md", 80)
+	m.common.cwd = root
+	m.currentDocument.localPath = a
+	m.currentDocument.Body = body
+	out, err := glamourRender(m, body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, _ = m.update(contentRenderedMsg(out))
+
+	if len(m.headings) != 2 || m.headings[1].Slug != "included" || m.headings[1].Line < 0 {
+		t.Fatalf("expected the included heading to be found, got %+v", m.headings)
+	}
+	if len(m.codeBlocks) != 1 || m.codeBlocks[0].Line < 0 {
+		t.Fatalf("expected the included code block to be found, got %+v", m.codeBlocks)
+	}
+}
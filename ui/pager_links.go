@@ -2,9 +2,13 @@ package ui
 
 import (
 	"fmt"
+	"html"
 	"net/url"
 	"os"
 	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
 	"strings"
 
 	"github.com/yuin/goldmark"
@@ -20,22 +24,99 @@ type followableLink struct {
 
 	ResolvedPath string
 	ResolvedNote string
+
+	// Number of links with the same label before this one, including ones
+	// that can't be followed, used to tell them apart in the rendered
+	// output.
+	Occurrence int
+
+	// Links to web pages are opened in the browser rather than the pager.
+	External bool
+
+	// Links to local files we don't show, like source code without a line
+	// anchor, are opened in the editor.
+	Editor bool
+}
+
+// focusMessage is the status message shown when the link is focused.
+func (l followableLink) focusMessage() string {
+	if l.Editor {
+		return "Edit: " + l.target()
+	}
+	return "Open: " + l.target()
+}
+
+// target returns where the link leads, for showing to the user.
+func (l followableLink) target() string {
+	if l.External {
+		return l.Href
+	}
+	return l.ResolvedNote
 }
 
 type rawLink struct {
 	href  string
 	label string
+
+	// Target of a wiki link, like Note Name#Heading, which
+	// resolveWikiLinks turns into an href.
+	wiki string
+
+	// Position in the markdown source, to keep links in document order.
+	offset int
 }
 
 func followableLinksForDocument(rootDir, currentFilePath, markdown string) ([]followableLink, error) {
+	return documentLinks(rootDir, currentFilePath, markdown, linkOptions{})
+}
+
+// linkOptions are the kinds of links besides ones to documents that
+// documentLinks returns.
+type linkOptions struct {
+	// Links to web pages.
+	web bool
+
+	// Links to other local files, to open in the editor.
+	editor bool
+
+	// Index to resolve wiki links with. Built for the call if nil.
+	wiki *wikiIndexCache
+}
+
+// documentLinks returns the links of a document we can follow, along with
+// the other kinds of links asked for.
+func documentLinks(rootDir, currentFilePath, markdown string, opts linkOptions) ([]followableLink, error) {
 	raw := extractRawLinks(markdown)
+	resolveWikiLinks(rootDir, currentFilePath, raw, opts.wiki)
 
 	out := make([]followableLink, 0, len(raw))
+	seen := map[string]int{}
 	for _, l := range raw {
-		link, ok, err := resolveFollowableLink(rootDir, currentFilePath, l.href)
-		if err != nil {
+		occurrence := seen[l.label]
+		seen[l.label]++
+
+		var (
+			link followableLink
+			ok   bool
+			err  error
+		)
+		if opts.web && isWebHref(l.href) {
+			link, ok = followableLink{Href: strings.Trim(strings.TrimSpace(l.href), "<>"), External: true}, true
+		} else if link, ok, err = resolveFollowableLink(rootDir, currentFilePath, l.href); err != nil {
 			return nil, err
 		}
+		if !ok && opts.editor && isLocalFileHref(l.href) {
+			var (
+				candidate bool
+				problem   string
+			)
+			link, candidate, problem, err = resolveLocalTarget(rootDir, currentFilePath, strings.Trim(strings.TrimSpace(l.href), "<>"))
+			if err != nil {
+				return nil, err
+			}
+			ok = candidate && problem == ""
+			link.Editor = true
+		}
 		if !ok {
 			continue
 		}
@@ -43,11 +124,89 @@ func followableLinksForDocument(rootDir, currentFilePath, markdown string) ([]fo
 			continue
 		}
 		link.Label = l.label
+		link.Occurrence = occurrence
 		out = append(out, link)
 	}
 	return out, nil
 }
 
+// Formats for a copied list of links.
+const (
+	linkListMarkdown = "markdown"
+	linkListPlain    = "plain"
+)
+
+// formatLinkList returns the links as a list, one per line, either as a
+// markdown list or as tab separated label and target.
+func formatLinkList(links []followableLink, format string) string {
+	var b strings.Builder
+	for _, l := range links {
+		target := l.target()
+		if l.Fragment != "" {
+			target += "#" + l.Fragment
+		}
+		label := strings.TrimSpace(l.Label)
+		if format == linkListPlain {
+			fmt.Fprintf(&b, "%s\t%s\n", label, target)
+			continue
+		}
+		fmt.Fprintf(&b, "- [%s](%s)\n", label, target)
+	}
+	return b.String()
+}
+
+// linkOverlayItems lists links with where they lead, for picking one to
+// follow.
+func linkOverlayItems(links []followableLink) []overlayItem {
+	items := make([]overlayItem, len(links))
+	for i, l := range links {
+		target := l.target()
+		if l.Fragment != "" {
+			target += "#" + l.Fragment
+		}
+		items[i] = overlayItem{Label: normalizeSpace(l.Label), Detail: target}
+	}
+	return items
+}
+
+// brokenLink is a link that looks followable but can't be followed.
+type brokenLink struct {
+	followableLink
+	Reason string
+}
+
+// brokenLinksForDocument returns the links that look like they point at a
+// local document but can't be followed, in document order. Wiki links are
+// resolved with the given index, if any.
+func brokenLinksForDocument(rootDir, currentFilePath, markdown string, wiki *wikiIndexCache) ([]brokenLink, error) {
+	raw := extractRawLinks(markdown)
+	resolveWikiLinks(rootDir, currentFilePath, raw, wiki)
+
+	var out []brokenLink
+	for _, l := range raw {
+		link, candidate, problem, err := resolveLocalLink(rootDir, currentFilePath, l.href)
+		if err != nil {
+			return nil, err
+		}
+		if !candidate || problem == "" || strings.TrimSpace(l.label) == "" {
+			continue
+		}
+		link.Label = l.label
+		out = append(out, brokenLink{followableLink: link, Reason: problem})
+	}
+	return out, nil
+}
+
+// brokenLinkTargets returns the broken links as plain links, for locating
+// and highlighting them.
+func brokenLinkTargets(broken []brokenLink) []followableLink {
+	out := make([]followableLink, len(broken))
+	for i, l := range broken {
+		out[i] = l.followableLink
+	}
+	return out
+}
+
 func splitFragment(href string) (path, frag string) {
 	path, frag, ok := strings.Cut(href, "#")
 	if ok {
@@ -81,13 +240,97 @@ func isFollowableHref(href string) bool {
 		return false
 	}
 
-	path, _ := splitFragment(href)
+	path, frag := splitFragment(href)
+	if path == "" {
+		// A fragment on its own points into the current document.
+		return frag != ""
+	}
 	if isAbsoluteOrUNCPath(path) {
 		return false
 	}
 	pathLower := strings.ToLower(path)
 
-	return strings.HasSuffix(pathLower, ".md") || strings.HasSuffix(pathLower, ".markdown")
+	if strings.HasSuffix(pathLower, ".md") || strings.HasSuffix(pathLower, ".markdown") {
+		return true
+	}
+
+	// Links to a line in a code file, like main.go#L42, are shown in the
+	// code view.
+	_, ok := lineAnchor(frag)
+	return (ok || isExtensionless(path)) && path != ""
+}
+
+// isWebHref reports whether href links to a web page.
+func isWebHref(href string) bool {
+	href = strings.ToLower(strings.Trim(strings.TrimSpace(href), "<>"))
+	return strings.HasPrefix(href, "http://") || strings.HasPrefix(href, "https://")
+}
+
+// isLocalFileHref reports whether href is a relative link to a file with an
+// extension, whatever kind of file that is.
+func isLocalFileHref(href string) bool {
+	href = strings.Trim(strings.TrimSpace(href), "<>")
+	if strings.Contains(href, "://") || strings.HasPrefix(strings.ToLower(href), "mailto:") {
+		return false
+	}
+	path, _ := splitFragment(href)
+	return path != "" && !isAbsoluteOrUNCPath(path) && filepath.Ext(strings.TrimRight(path, "/")) != ""
+}
+
+// isExtensionless reports whether the last element of a link path has no
+// extension, like docs or docs/guide.
+func isExtensionless(path string) bool {
+	path = strings.TrimRight(path, "/")
+	return path != "" && path != "." && path != ".." && filepath.Ext(path) == ""
+}
+
+// directoryIndexNames are the documents a link to a directory opens, in order
+// of preference.
+var directoryIndexNames = []string{"README.md", "index.md"}
+
+// extensionlessTarget finds the file an extensionless link refers to. In
+// order, it tries the exact path, the path with a .md extension and the index
+// document of a directory. It returns "" if none of them exist.
+func extensionlessTarget(path string) string {
+	if info, err := os.Stat(path); err == nil && info.Mode().IsRegular() {
+		return path
+	}
+	if info, err := os.Stat(path + ".md"); err == nil && info.Mode().IsRegular() {
+		return path + ".md"
+	}
+	for _, name := range directoryIndexNames {
+		p := filepath.Join(path, name)
+		if info, err := os.Stat(p); err == nil && info.Mode().IsRegular() {
+			return p
+		}
+	}
+	return ""
+}
+
+// withinRoot reports whether path is inside of root. Both need to be
+// absolute.
+func withinRoot(root, path string) bool {
+	rel, err := filepath.Rel(root, path)
+	if err != nil {
+		return false
+	}
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(os.PathSeparator))
+}
+
+var lineAnchorPattern = regexp.MustCompile(`^L(\d+)(?:-L?\d+)?$`)
+
+// lineAnchor parses code hosting style line anchors like L42 or L42-L50 and
+// returns the (first) line number.
+func lineAnchor(frag string) (int, bool) {
+	m := lineAnchorPattern.FindStringSubmatch(frag)
+	if m == nil {
+		return 0, false
+	}
+	n, err := strconv.Atoi(m[1])
+	if err != nil || n < 1 {
+		return 0, false
+	}
+	return n, true
 }
 
 func extractRawLinks(markdown string) []rawLink {
@@ -101,6 +344,25 @@ func extractRawLinks(markdown string) []rawLink {
 			return ast.WalkContinue, nil
 		}
 
+		switch n := n.(type) {
+		case *ast.Paragraph, *ast.Heading, *ast.TextBlock:
+			out = append(out, wikiLinks(n, source)...)
+			return ast.WalkContinue, nil
+		case *ast.RawHTML:
+			if l, ok := inlineHTMLAnchor(n, source); ok {
+				l.offset = n.Segments.At(0).Start
+				out = append(out, l)
+			}
+			return ast.WalkContinue, nil
+		case *ast.HTMLBlock:
+			anchors := htmlAnchors(htmlBlockText(n, source))
+			for i := range anchors {
+				anchors[i].offset = blockOffset(n)
+			}
+			out = append(out, anchors...)
+			return ast.WalkContinue, nil
+		}
+
 		link, ok := n.(*ast.Link)
 		if !ok {
 			return ast.WalkContinue, nil
@@ -111,40 +373,176 @@ func extractRawLinks(markdown string) []rawLink {
 			return ast.WalkContinue, nil
 		}
 
-		var b strings.Builder
-		_ = ast.Walk(link, func(child ast.Node, entering bool) (ast.WalkStatus, error) {
-			if !entering {
-				return ast.WalkContinue, nil
-			}
-			if t, ok := child.(*ast.Text); ok {
-				b.Write(t.Segment.Value(source))
-			}
-			return ast.WalkContinue, nil
-		})
-
+		// The label is the visible text, without emphasis markers and the
+		// like, with line breaks turned into spaces.
 		out = append(out, rawLink{
-			href:  href,
-			label: strings.TrimSpace(b.String()),
+			href:   href,
+			label:  strings.TrimSpace(nodeText(link, source)),
+			offset: inlineOffset(link),
 		})
 
 		return ast.WalkContinue, nil
 	})
 
+	// Wiki links are found a block at a time, so put them in between the
+	// other links of their block.
+	slices.SortStableFunc(out, func(a, b rawLink) int { return a.offset - b.offset })
+	return out
+}
+
+// blockOffset returns where a block starts in the source.
+func blockOffset(n ast.Node) int {
+	if n.Lines().Len() == 0 {
+		return 0
+	}
+	return n.Lines().At(0).Start
+}
+
+// inlineOffset returns where the text of an inline node starts in the
+// source, or else where its block does.
+func inlineOffset(n ast.Node) int {
+	if t := firstText(n); t != nil {
+		return t.Segment.Start
+	}
+	for p := n.Parent(); p != nil; p = p.Parent() {
+		if p.Type() == ast.TypeBlock {
+			return blockOffset(p)
+		}
+	}
+	return 0
+}
+
+func firstText(n ast.Node) *ast.Text {
+	for c := n.FirstChild(); c != nil; c = c.NextSibling() {
+		if t, ok := c.(*ast.Text); ok {
+			return t
+		}
+		if t := firstText(c); t != nil {
+			return t
+		}
+	}
+	return nil
+}
+
+var (
+	htmlHrefAttr      = `\bhref\s*=\s*(?:"([^"]*)"|'([^']*)'|([^\s"'>]+))`
+	htmlAnchorPattern = regexp.MustCompile(`(?is)<a\s[^>]*?` + htmlHrefAttr + `[^>]*>(.*?)</a\s*>`)
+	htmlOpeningAnchor = regexp.MustCompile(`(?is)^<a\s[^>]*?` + htmlHrefAttr + `[^>]*>$`)
+	htmlClosingAnchor = regexp.MustCompile(`(?i)^</a\s*>$`)
+	htmlTagPattern    = regexp.MustCompile(`<[^>]*>`)
+	whitespacePattern = regexp.MustCompile(`\s+`)
+)
+
+// hrefFromMatch returns the href attribute value of a match of one of the
+// anchor patterns, whichever way it was quoted.
+func hrefFromMatch(m []string) string {
+	for _, v := range m[1:4] {
+		if v != "" {
+			return html.UnescapeString(strings.TrimSpace(v))
+		}
+	}
+	return ""
+}
+
+// htmlText turns a fragment of HTML into plain text for use as a label.
+func htmlText(s string) string {
+	s = htmlTagPattern.ReplaceAllString(s, "")
+	s = whitespacePattern.ReplaceAllString(s, " ")
+	return strings.TrimSpace(html.UnescapeString(s))
+}
+
+// htmlAnchors returns the links of all <a href> elements in a piece of HTML.
+func htmlAnchors(s string) []rawLink {
+	var out []rawLink
+	for _, m := range htmlAnchorPattern.FindAllStringSubmatch(s, -1) {
+		if href := hrefFromMatch(m); href != "" {
+			out = append(out, rawLink{href: href, label: htmlText(m[4])})
+		}
+	}
 	return out
 }
 
+func htmlBlockText(n *ast.HTMLBlock, source []byte) string {
+	var b strings.Builder
+	lines := n.Lines()
+	for i := 0; i < lines.Len(); i++ {
+		seg := lines.At(i)
+		b.Write(seg.Value(source))
+	}
+	if n.HasClosure() {
+		b.Write(n.ClosureLine.Value(source))
+	}
+	return b.String()
+}
+
+// inlineHTMLAnchor returns the link of an <a href> element in a paragraph.
+// goldmark parses its opening and closing tags as separate raw HTML nodes
+// with the label in between.
+func inlineHTMLAnchor(n *ast.RawHTML, source []byte) (rawLink, bool) {
+	m := htmlOpeningAnchor.FindStringSubmatch(string(n.Segments.Value(source)))
+	if m == nil {
+		return rawLink{}, false
+	}
+	href := hrefFromMatch(m)
+	if href == "" {
+		return rawLink{}, false
+	}
+
+	var label strings.Builder
+	for s := n.NextSibling(); s != nil; s = s.NextSibling() {
+		if raw, ok := s.(*ast.RawHTML); ok {
+			if htmlClosingAnchor.Match(raw.Segments.Value(source)) {
+				break
+			}
+			continue
+		}
+		label.WriteString(nodeText(s, source))
+	}
+	return rawLink{href: href, label: strings.TrimSpace(html.UnescapeString(label.String()))}, true
+}
+
 func resolveFollowableLink(rootDir, currentFilePath, href string) (followableLink, bool, error) {
+	link, candidate, problem, err := resolveLocalLink(rootDir, currentFilePath, href)
+	if err != nil {
+		return followableLink{}, false, err
+	}
+	if !candidate || problem != "" {
+		return followableLink{}, false, nil
+	}
+	return link, true, nil
+}
+
+// Reasons why a link that looks followable can't be followed.
+const (
+	brokenLinkOutsideRoot = "outside the root directory"
+	brokenLinkNotFound    = "file not found"
+	brokenLinkNotAFile    = "not a regular file"
+)
+
+// resolveLocalLink resolves a link relative to the current file. candidate
+// reports whether the link looks followable at all; if it does but can't be
+// followed, problem says why.
+func resolveLocalLink(rootDir, currentFilePath, href string) (link followableLink, candidate bool, problem string, err error) {
 	href = strings.TrimSpace(href)
 	href = strings.Trim(href, "<>")
 
 	if !isFollowableHref(href) {
-		return followableLink{}, false, nil
+		return followableLink{}, false, "", nil
 	}
+	return resolveLocalTarget(rootDir, currentFilePath, href)
+}
 
+// resolveLocalTarget resolves a link to any kind of local file relative to
+// the current file, making sure it's a regular file within the root
+// directory.
+func resolveLocalTarget(rootDir, currentFilePath, href string) (link followableLink, candidate bool, problem string, err error) {
 	path, frag := splitFragment(href)
 	path = strings.TrimSpace(path)
 	if path == "" {
-		return followableLink{}, false, nil
+		if frag == "" || currentFilePath == "" {
+			return followableLink{}, false, "", nil
+		}
+		path = filepath.Base(currentFilePath)
 	}
 
 	if strings.Contains(path, "%") {
@@ -158,11 +556,11 @@ func resolveFollowableLink(rootDir, currentFilePath, href string) (followableLin
 
 	rootAbs, err := filepath.Abs(rootDir)
 	if err != nil {
-		return followableLink{}, false, fmt.Errorf("abs root dir: %w", err)
+		return followableLink{}, false, "", fmt.Errorf("abs root dir: %w", err)
 	}
 	resAbs, err := filepath.Abs(resolved)
 	if err != nil {
-		return followableLink{}, false, fmt.Errorf("abs resolved path: %w", err)
+		return followableLink{}, false, "", fmt.Errorf("abs resolved path: %w", err)
 	}
 
 	if rootEval, err := filepath.EvalSymlinks(rootAbs); err == nil {
@@ -172,27 +570,43 @@ func resolveFollowableLink(rootDir, currentFilePath, href string) (followableLin
 		resAbs = resEval
 	}
 
-	rel, err := filepath.Rel(rootAbs, resAbs)
-	if err != nil {
-		return followableLink{}, false, nil
+	link = followableLink{
+		Href:         href,
+		Path:         path,
+		Fragment:     frag,
+		ResolvedNote: path,
 	}
-	if rel == ".." || strings.HasPrefix(rel, ".."+string(os.PathSeparator)) {
-		return followableLink{}, false, nil
+
+	if !withinRoot(rootAbs, resAbs) {
+		return link, true, brokenLinkOutsideRoot, nil
+	}
+	rel, _ := filepath.Rel(rootAbs, resAbs)
+	link.ResolvedNote = rel
+
+	// Extensionless links are only followed when they lead somewhere,
+	// otherwise they're more likely to be something else entirely than a
+	// broken link.
+	if _, lineLink := lineAnchor(frag); isExtensionless(path) && !lineLink {
+		target := extensionlessTarget(resAbs)
+		if target == "" {
+			return followableLink{}, false, "", nil
+		}
+		// The index document might be a symlink to somewhere else.
+		resAbs = evalSymlinksOrSelf(target)
+		if !withinRoot(rootAbs, resAbs) {
+			return link, true, brokenLinkOutsideRoot, nil
+		}
 	}
 
 	info, statErr := os.Stat(resAbs)
 	if statErr != nil {
-		return followableLink{}, false, nil
+		return link, true, brokenLinkNotFound, nil
 	}
 	if !info.Mode().IsRegular() {
-		return followableLink{}, false, nil
+		return link, true, brokenLinkNotAFile, nil
 	}
 
-	return followableLink{
-		Href:         href,
-		Path:         path,
-		Fragment:     frag,
-		ResolvedPath: resAbs,
-		ResolvedNote: stripAbsolutePath(resAbs, rootAbs),
-	}, true, nil
+	link.ResolvedPath = resAbs
+	link.ResolvedNote = stripAbsolutePath(resAbs, rootAbs)
+	return link, true, "", nil
 }
@@ -1,9 +1,15 @@
 package ui
 
 import (
+	"errors"
+	"fmt"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"strings"
 	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
 )
 
 func TestFollowableLinksForDocument_CommonFormatsAndSafety(t *testing.T) {
@@ -23,6 +29,8 @@ func TestFollowableLinksForDocument_CommonFormatsAndSafety(t *testing.T) {
 	mustWriteFile(t, targetMD, "# Target\n")
 	mustWriteFile(t, targetMarkdown, "# Target Markdown\n")
 	mustWriteFile(t, spaceNameMD, "# Space Name\n")
+	codeFile := filepath.Join(root, "docs", "main.go")
+	mustWriteFile(t, codeFile, "package main\n")
 
 	outsideMD := filepath.Join(outside, "outside.md")
 	mustWriteFile(t, outsideMD, "# Outside\n")
@@ -43,6 +51,7 @@ func TestFollowableLinksForDocument_CommonFormatsAndSafety(t *testing.T) {
 	targetAbs := absEvalSymlinks(t, targetMD)
 	targetMarkdownAbs := absEvalSymlinks(t, targetMarkdown)
 	spaceNameAbs := absEvalSymlinks(t, spaceNameMD)
+	codeFileAbs := absEvalSymlinks(t, codeFile)
 
 	cases := []struct {
 		name  string
@@ -114,6 +123,36 @@ func TestFollowableLinksForDocument_CommonFormatsAndSafety(t *testing.T) {
 				ResolvedNote: stripAbsolutePath(targetMarkdownAbs, rootAbs),
 			}},
 		},
+		{
+			name: "code_file_with_line_anchor",
+			md:   "See [main](docs/main.go#L42).\n",
+			want: []wantLink{{
+				Label:        "main",
+				ResolvedPath: codeFileAbs,
+				ResolvedNote: stripAbsolutePath(codeFileAbs, rootAbs),
+				Fragment:     "L42",
+			}},
+		},
+		{
+			name: "fragment_only_points_at_current_document",
+			md:   "See [Section](#section).\n",
+			want: []wantLink{{
+				Label:        "Section",
+				ResolvedPath: absEvalSymlinks(t, currentFilePath),
+				ResolvedNote: "current.md",
+				Fragment:     "section",
+			}},
+		},
+		{
+			name: "empty_fragment_is_ignored",
+			md:   "See [Nothing](#).\n",
+			want: nil,
+		},
+		{
+			name: "code_file_without_line_anchor_is_ignored",
+			md:   "See [main](docs/main.go#section).\n",
+			want: nil,
+		},
 		{
 			name: "empty_label_is_ignored",
 			md:   "See [](docs/target.md).\n",
@@ -252,3 +291,840 @@ func mustWriteFile(t *testing.T, path, contents string) {
 		t.Fatalf("writefile %q: %v", path, err)
 	}
 }
+
+func TestLineAnchor(t *testing.T) {
+	cases := map[string]int{
+		"L42":     42,
+		"L7-L12":  7,
+		"L7-12":   7,
+		"L0":      0,
+		"42":      0,
+		"Lfoo":    0,
+		"section": 0,
+	}
+	for frag, want := range cases {
+		got, ok := lineAnchor(frag)
+		if ok != (want > 0) || got != want {
+			t.Errorf("lineAnchor(%q): expected %d, got %d (ok=%v)", frag, want, got, ok)
+		}
+	}
+}
+
+func TestFormatLinkList(t *testing.T) {
+	links := []followableLink{
+		{Label: "Guide", ResolvedNote: "docs/guide.md"},
+		{Label: " Install ", ResolvedNote: "docs/install.md", Fragment: "linux"},
+	}
+
+	if got, want := formatLinkList(links, linkListMarkdown),
+		"- [Guide](docs/guide.md)\n- [Install](docs/install.md#linux)\n"; got != want {
+		t.Errorf("markdown: expected %q, got %q", want, got)
+	}
+	if got, want := formatLinkList(links, linkListPlain),
+		"Guide\tdocs/guide.md\nInstall\tdocs/install.md#linux\n"; got != want {
+		t.Errorf("plain: expected %q, got %q", want, got)
+	}
+}
+
+func TestFollowFocusedLink_SelfLink(t *testing.T) {
+	root := absEvalSymlinks(t, t.TempDir())
+	path := filepath.Join(root, "current.md")
+	filler := strings.Repeat("filler\n\n", 60)
+	body := "# Top\n\n" + filler + "## Target\n\n" + filler
+	mustWriteFile(t, path, body)
+
+	for _, tc := range []struct {
+		name     string
+		fragment string
+		wantLine func(m pagerModel) int
+		status   string
+	}{
+		{name: "without_fragment", wantLine: func(pagerModel) int { return 0 }, status: "Top of document"},
+		{name: "with_fragment", fragment: "target", wantLine: func(m pagerModel) int {
+			return m.headings[headingForSlug(m.headings, "target")].Line
+		}, status: "Target"},
+		{name: "unknown_fragment", fragment: "nope", wantLine: func(pagerModel) int { return 0 }, status: "Anchor #nope not found"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			m := newTestPager(t, Config{}, "current.md", 80)
+			m.common.cwd = root
+			m.currentDocument = markdown{localPath: path, Note: "current.md", Body: body}
+
+			rendered, err := glamourRender(m, body)
+			if err != nil {
+				t.Fatalf("glamourRender returned error: %v", err)
+			}
+			m.rendered = rendered
+			m.headings = documentHeadings(body, rendered, duplicateSlugsSuffix)
+			m.applyRenderedContent()
+			m.viewport.SetYOffset(20)
+
+			m.links = []followableLink{{Label: "self", Fragment: tc.fragment, ResolvedPath: path, ResolvedNote: "current.md"}}
+			m.focusedLink = 0
+			_ = m.followFocusedLink()
+
+			if len(m.history) != 0 {
+				t.Fatalf("expected no history entry for a self link, got %v", m.history)
+			}
+			if m.pendingFragment != "" {
+				t.Fatalf("expected no pending fragment, got %q", m.pendingFragment)
+			}
+			if want := tc.wantLine(m); m.viewport.YOffset != want {
+				t.Fatalf("expected to scroll to line %d, got %d", want, m.viewport.YOffset)
+			}
+			if m.statusMessage != tc.status {
+				t.Fatalf("expected status %q, got %q", tc.status, m.statusMessage)
+			}
+		})
+	}
+}
+
+func TestFollowFocusedLink_Fragment(t *testing.T) {
+	root := absEvalSymlinks(t, t.TempDir())
+	current := filepath.Join(root, "current.md")
+	target := filepath.Join(root, "target.md")
+	filler := strings.Repeat("filler\n\n", 60)
+	targetBody := "# Top\n\n" + filler + "## Set Up: Linux!\n\n" + filler
+	mustWriteFile(t, current, "[go](target.md#set-up-linux)\n")
+	mustWriteFile(t, target, targetBody)
+
+	for _, tc := range []struct {
+		fragment string
+		status   string
+	}{
+		{fragment: "set-up-linux", status: "Set Up: Linux!"},
+		{fragment: "nope", status: "Anchor #nope not found"},
+	} {
+		t.Run(tc.fragment, func(t *testing.T) {
+			m := newTestPager(t, Config{}, "current.md", 80)
+			m.common.cwd = root
+			m.currentDocument = markdown{localPath: current, Note: "current.md"}
+			m.rendered = strings.Repeat("line\n", 100)
+			m.applyRenderedContent()
+			m.viewport.SetYOffset(10)
+
+			m.links = []followableLink{{Label: "go", Fragment: tc.fragment, ResolvedPath: target, ResolvedNote: "target.md"}}
+			m.focusedLink = 0
+			_ = m.followFocusedLink()
+			if len(m.history) != 1 || m.history[0].YOffset != 10 {
+				t.Fatalf("expected the original offset in the history, got %v", m.history)
+			}
+
+			// Load and render the target, as the program would.
+			m.currentDocument = markdown{localPath: target, Note: "target.md", Body: targetBody}
+			rendered, err := glamourRender(m, targetBody)
+			if err != nil {
+				t.Fatalf("glamourRender returned error: %v", err)
+			}
+			m, _ = m.update(contentRenderedMsg(rendered))
+
+			want := 0
+			if i := headingForSlug(m.headings, tc.fragment); i >= 0 {
+				want = m.headings[i].Line
+			}
+			if m.viewport.YOffset != want || m.statusMessage != tc.status {
+				t.Fatalf("expected offset %d and status %q, got %d and %q", want, tc.status, m.viewport.YOffset, m.statusMessage)
+			}
+		})
+	}
+}
+
+func TestBrokenLinksForDocument(t *testing.T) {
+	root := absEvalSymlinks(t, t.TempDir())
+	current := filepath.Join(root, "docs", "index.md")
+	mustWriteFile(t, current, "")
+	mustWriteFile(t, filepath.Join(root, "docs", "ok.md"), "")
+	mustMkdirAll(t, filepath.Join(root, "docs", "dir.md"))
+
+	md := "[ok](ok.md) [missing](missing.md#intro) [escape](../../outside.md) " +
+		"[dir](dir.md) [web](https://example.com/x.md) [code](main.go)\n"
+
+	broken, err := brokenLinksForDocument(root, current, md, nil)
+	if err != nil {
+		t.Fatalf("brokenLinksForDocument returned error: %v", err)
+	}
+
+	want := []struct{ label, note, reason string }{
+		{"missing", filepath.Join("docs", "missing.md"), brokenLinkNotFound},
+		{"escape", "../../outside.md", brokenLinkOutsideRoot},
+		{"dir", filepath.Join("docs", "dir.md"), brokenLinkNotAFile},
+	}
+	if len(broken) != len(want) {
+		t.Fatalf("expected %d broken links, got %d: %+v", len(want), len(broken), broken)
+	}
+	for i, w := range want {
+		b := broken[i]
+		if b.Label != w.label || b.ResolvedNote != w.note || b.Reason != w.reason {
+			t.Errorf("broken link %d: expected %s %s (%s), got %s %s (%s)",
+				i, w.label, w.note, w.reason, b.Label, b.ResolvedNote, b.Reason)
+		}
+	}
+	if broken[0].Fragment != "intro" {
+		t.Errorf("expected fragment to be kept, got %q", broken[0].Fragment)
+	}
+}
+
+func TestFocusNextBrokenLink(t *testing.T) {
+	m := newTestPager(t, Config{}, "index.md", 80)
+	m.rendered = "see missing and gone"
+	m.brokenLinks = []brokenLink{
+		{followableLink: followableLink{Label: "missing", ResolvedNote: "missing.md"}, Reason: brokenLinkNotFound},
+		{followableLink: followableLink{Label: "gone", ResolvedNote: "gone.md"}, Reason: brokenLinkNotFound},
+	}
+	m.focusedLink = 0
+	m.links = []followableLink{{Label: "see"}}
+
+	_ = m.focusNextBrokenLink()
+	if m.focusedBroken != 0 || m.focusedLink != -1 {
+		t.Fatalf("expected first broken link to take focus, got broken=%d link=%d", m.focusedBroken, m.focusedLink)
+	}
+	if want := "Broken link: missing.md (file not found)"; m.statusMessage != want {
+		t.Fatalf("expected status message %q, got %q", want, m.statusMessage)
+	}
+
+	_ = m.focusNextBrokenLink()
+	_ = m.focusNextBrokenLink()
+	if m.focusedBroken != 0 {
+		t.Fatalf("expected focus to wrap around, got %d", m.focusedBroken)
+	}
+}
+
+func TestFocusFirstVisibleLink(t *testing.T) {
+	m := newTestPager(t, Config{}, "index.md", 80)
+
+	var lines []string
+	for i := range 100 {
+		lines = append(lines, fmt.Sprintf("line %d", i))
+	}
+	lines[5] = "see first"
+	lines[50] = "see second and third"
+	m.rendered = strings.Join(lines, "\n")
+	m.applyRenderedContent()
+	m.links = []followableLink{
+		{Label: "first", ResolvedNote: "first.md"},
+		{Label: "second", ResolvedNote: "second.md"},
+		{Label: "third", ResolvedNote: "third.md"},
+	}
+	m.focusedLink = 0
+
+	m.viewport.SetYOffset(40)
+	_ = m.focusFirstVisibleLink()
+	if m.focusedLink != 1 {
+		t.Fatalf("expected the first link in view to be focused, got %d", m.focusedLink)
+	}
+	if want := "Open: second.md"; m.statusMessage != want {
+		t.Fatalf("expected status message %q, got %q", want, m.statusMessage)
+	}
+
+	m.viewport.SetYOffset(55)
+	_ = m.focusFirstVisibleLink()
+	if m.focusedLink != 1 || m.statusMessage != "No links in view" {
+		t.Fatalf("expected focus to stay put without links in view, got %d (%q)", m.focusedLink, m.statusMessage)
+	}
+}
+
+func TestFollowFocusedLink_LargeFile(t *testing.T) {
+	root := absEvalSymlinks(t, t.TempDir())
+	current := filepath.Join(root, "index.md")
+	big := filepath.Join(root, "big.md")
+	mustWriteFile(t, current, "[big](big.md)\n")
+	mustWriteFile(t, big, strings.Repeat("# big\n", 100))
+
+	newPager := func(action string) pagerModel {
+		m := newTestPager(t, Config{LargeFileThreshold: 100, LargeFileAction: action}, "index.md", 80)
+		m.common.cwd = root
+		m.currentDocument = markdown{localPath: current, Note: "index.md"}
+		m.links = []followableLink{{Label: "big", ResolvedPath: big, ResolvedNote: "big.md"}}
+		m.focusedLink = 0
+		return m
+	}
+
+	t.Run(largeFileConfirm, func(t *testing.T) {
+		m := newPager(largeFileConfirm)
+
+		_ = m.followFocusedLink()
+		if len(m.history) != 0 {
+			t.Fatalf("expected to stay on the current document, got history %v", m.history)
+		}
+		if want := "big.md is 600 B, press enter again to open it"; m.statusMessage != want {
+			t.Fatalf("expected status %q, got %q", want, m.statusMessage)
+		}
+
+		_ = m.followFocusedLink()
+		if len(m.history) != 1 {
+			t.Fatalf("expected confirming to open the file, got history %v", m.history)
+		}
+	})
+
+	t.Run(largeFileRaw, func(t *testing.T) {
+		m := newPager(largeFileRaw)
+
+		cmd := m.followFocusedLink()
+		if len(m.history) != 1 {
+			t.Fatalf("expected the file to be opened right away, got history %v", m.history)
+		}
+
+		// The first command loads the document, the others are about the
+		// status message.
+		loaded, ok := cmd().(tea.BatchMsg)[0]().(fetchedMarkdownMsg)
+		if !ok || !loaded.raw {
+			t.Fatalf("expected the file to be loaded unrendered, got %+v", loaded)
+		}
+	})
+}
+
+func TestResolveLocalLink_Extensionless(t *testing.T) {
+	base := absEvalSymlinks(t, t.TempDir())
+	root := filepath.Join(base, "root")
+	current := filepath.Join(root, "current.md")
+	mustWriteFile(t, current, "")
+
+	mustWriteFile(t, filepath.Join(root, "CHANGES"), "")
+	mustWriteFile(t, filepath.Join(root, "setup.md"), "")
+	mustWriteFile(t, filepath.Join(root, "docs", "README.md"), "")
+	mustWriteFile(t, filepath.Join(root, "docs", "index.md"), "")
+	mustWriteFile(t, filepath.Join(root, "site", "index.md"), "")
+	mustWriteFile(t, filepath.Join(root, "api.md"), "")
+	mustWriteFile(t, filepath.Join(root, "api", "README.md"), "")
+	mustMkdirAll(t, filepath.Join(root, "empty"))
+
+	mustWriteFile(t, filepath.Join(base, "outside", "README.md"), "")
+	mustMkdirAll(t, filepath.Join(root, "escape"))
+	if err := os.Symlink(filepath.Join(base, "outside", "README.md"), filepath.Join(root, "escape", "README.md")); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+
+	cases := []struct {
+		href      string
+		want      string
+		candidate bool
+		problem   string
+	}{
+		{href: "CHANGES", want: "CHANGES", candidate: true},
+		{href: "setup", want: "setup.md", candidate: true},
+		{href: "docs", want: filepath.Join("docs", "README.md"), candidate: true},
+		{href: "docs/", want: filepath.Join("docs", "README.md"), candidate: true},
+		{href: "site#intro", want: filepath.Join("site", "index.md"), candidate: true},
+		{href: "api", want: "api.md", candidate: true},
+		{href: "empty"},
+		{href: "missing"},
+		{href: "escape", candidate: true, problem: brokenLinkOutsideRoot},
+		{href: "../outside", candidate: true, problem: brokenLinkOutsideRoot},
+	}
+	for _, tc := range cases {
+		t.Run(tc.href, func(t *testing.T) {
+			link, candidate, problem, err := resolveLocalLink(root, current, tc.href)
+			if err != nil {
+				t.Fatalf("resolveLocalLink returned error: %v", err)
+			}
+			if candidate != tc.candidate || problem != tc.problem {
+				t.Fatalf("expected candidate %v with problem %q, got %v with %q", tc.candidate, tc.problem, candidate, problem)
+			}
+			if tc.want == "" {
+				return
+			}
+			if want := filepath.Join(root, tc.want); link.ResolvedPath != want {
+				t.Fatalf("expected %s, got %s", want, link.ResolvedPath)
+			}
+		})
+	}
+}
+
+func TestFollowableLinksForDocument_HTMLAnchors(t *testing.T) {
+	base := absEvalSymlinks(t, t.TempDir())
+	root := filepath.Join(base, "root")
+	current := filepath.Join(root, "current.md")
+	mustWriteFile(t, current, "")
+	mustWriteFile(t, filepath.Join(root, "docs", "target.md"), "")
+	mustWriteFile(t, filepath.Join(root, "docs", "other.md"), "")
+	mustWriteFile(t, filepath.Join(base, "outside.md"), "")
+
+	md := "See <a href=\"docs/target.md#usage\">the <b>target</b></a> for details.\n\n" +
+		"<p align=\"center\">\n  <a href='docs/other.md'>Other &amp; more</a>\n" +
+		"  <a href=\"https://example.com/x.md\">Web</a>\n" +
+		"  <a href=\"../outside.md\">Outside</a>\n</p>\n"
+
+	links, err := followableLinksForDocument(root, current, md)
+	if err != nil {
+		t.Fatalf("followableLinksForDocument returned error: %v", err)
+	}
+
+	want := []struct{ label, path, frag string }{
+		{"the target", filepath.Join(root, "docs", "target.md"), "usage"},
+		{"Other & more", filepath.Join(root, "docs", "other.md"), ""},
+	}
+	if len(links) != len(want) {
+		t.Fatalf("expected %d links, got %d: %+v", len(want), len(links), links)
+	}
+	for i, w := range want {
+		l := links[i]
+		if l.Label != w.label || l.ResolvedPath != w.path || l.Fragment != w.frag {
+			t.Errorf("link %d: expected %q -> %s#%s, got %q -> %s#%s",
+				i, w.label, w.path, w.frag, l.Label, l.ResolvedPath, l.Fragment)
+		}
+	}
+}
+
+func TestLinkDirectoryListing(t *testing.T) {
+	root := absEvalSymlinks(t, t.TempDir())
+	current := filepath.Join(root, "index.md")
+	target := filepath.Join(root, "docs", "guide.md")
+	mustWriteFile(t, current, "")
+	mustWriteFile(t, target, "")
+	mustWriteFile(t, filepath.Join(root, "docs", "api.md"), "")
+	mustWriteFile(t, filepath.Join(root, "docs", "zebra.md"), "")
+	mustWriteFile(t, filepath.Join(root, "docs", "main.go"), "")
+
+	m := newTestPager(t, Config{}, "index.md", 80)
+	m.common.cwd = root
+	m.currentDocument.localPath = current
+	m.links = []followableLink{{Label: "Guide", ResolvedPath: target, ResolvedNote: filepath.Join("docs", "guide.md")}}
+	m.focusedLink = 0
+
+	m = typeKeys(t, m, "O")
+	if m.overlay == nil || m.overlay.kind != overlayDirectory {
+		t.Fatal("expected the directory listing to open")
+	}
+	var labels []string
+	for _, item := range m.overlay.items {
+		labels = append(labels, item.Label)
+	}
+	if got := strings.Join(labels, " "); got != "api.md guide.md zebra.md" {
+		t.Fatalf("unexpected listing %q", got)
+	}
+	if m.overlay.cursor != 1 {
+		t.Fatalf("expected the cursor on the link target, got %d", m.overlay.cursor)
+	}
+
+	// Opening a file from the listing can be undone with backspace.
+	var cmd tea.Cmd
+	m, cmd = m.update(tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd == nil || len(m.history) != 1 || m.history[0].Path != current {
+		t.Fatalf("expected the current document in the history, got %+v", m.history)
+	}
+}
+
+func TestHighlightFocusedLink_SameLabel(t *testing.T) {
+	root := absEvalSymlinks(t, t.TempDir())
+	current := filepath.Join(root, "index.md")
+	mustWriteFile(t, current, "")
+	mustWriteFile(t, filepath.Join(root, "a.md"), "")
+	mustWriteFile(t, filepath.Join(root, "b.md"), "")
+
+	md := "Read the [Docs](https://example.com/docs), then the [Docs](a.md).\n\n" +
+		"Still lost? The [Docs](b.md) help too.\n"
+	links, err := followableLinksForDocument(root, current, md)
+	if err != nil {
+		t.Fatalf("followableLinksForDocument returned error: %v", err)
+	}
+	if len(links) != 2 {
+		t.Fatalf("expected 2 followable links, got %+v", links)
+	}
+
+	m := newTestPager(t, Config{}, "index.md", 80)
+	rendered, err := glamourRender(m, md)
+	if err != nil {
+		t.Fatalf("glamourRender returned error: %v", err)
+	}
+
+	for i, l := range links {
+		got := highlightFocusedLink(rendered, links, i, reverseSpan)
+		idx := strings.Index(got, reverseOn)
+		if idx < 0 {
+			t.Fatalf("link %d (%s) wasn't highlighted", i, l.ResolvedNote)
+		}
+		// The external link comes first, so link i is occurrence i+1.
+		if n := strings.Count(stripANSI(got[:idx]), "Docs"); n != i+1 {
+			t.Errorf("link %d (%s): highlighted occurrence %d of the label, expected %d", i, l.ResolvedNote, n, i+1)
+		}
+	}
+}
+
+func TestOpenPath(t *testing.T) {
+	base := absEvalSymlinks(t, t.TempDir())
+	root := filepath.Join(base, "root")
+	current := filepath.Join(root, "docs", "current.md")
+	mustWriteFile(t, current, "")
+	mustWriteFile(t, filepath.Join(root, "docs", "sibling.md"), "")
+	mustWriteFile(t, filepath.Join(root, "top.md"), "")
+	mustWriteFile(t, filepath.Join(root, "main.go"), "")
+	mustWriteFile(t, filepath.Join(base, "outside.md"), "")
+
+	cases := []struct {
+		command string
+		want    string // opened path, if any
+		status  string
+	}{
+		{command: "open sibling.md", want: filepath.Join(root, "docs", "sibling.md")},
+		{command: "open top.md", want: filepath.Join(root, "top.md")},
+		{command: "open " + filepath.Join(root, "top.md"), want: filepath.Join(root, "top.md")},
+		{command: "open ../../outside.md", status: "Can't open ../../outside.md: outside the root directory"},
+		{command: "open " + filepath.Join(base, "outside.md"), status: "Can't open " + filepath.Join(base, "outside.md") + ": outside the root directory"},
+		{command: "open missing.md", status: "Can't open missing.md: file not found"},
+		{command: "open main.go", status: "Can't open main.go: not a markdown document"},
+		{command: "open", status: "Usage: open <path>"},
+		{command: "nope", status: "Unknown command: nope"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.command, func(t *testing.T) {
+			m := newTestPager(t, Config{}, "docs/current.md", 80)
+			m.common.cwd = root
+			m.currentDocument = markdown{localPath: current, Note: "docs/current.md"}
+
+			cmd := m.runCommand(tc.command)
+			if tc.want == "" {
+				if len(m.history) != 0 || m.statusMessage != tc.status {
+					t.Fatalf("expected status %q and no navigation, got %q and history %v", tc.status, m.statusMessage, m.history)
+				}
+				return
+			}
+
+			if len(m.history) != 1 || m.history[0].Path != current {
+				t.Fatalf("expected the current document in the history, got %v", m.history)
+			}
+			loaded, ok := cmd().(fetchedMarkdownMsg)
+			if !ok || loaded.localPath != tc.want {
+				t.Fatalf("expected %s to be opened, got %+v", tc.want, loaded)
+			}
+		})
+	}
+}
+
+func TestFollowFocusedLink_External(t *testing.T) {
+	root := absEvalSymlinks(t, t.TempDir())
+	current := filepath.Join(root, "current.md")
+	body := "See [the site](https://example.com/docs) and [local](other.md).\n"
+	mustWriteFile(t, current, body)
+	mustWriteFile(t, filepath.Join(root, "other.md"), "")
+
+	var opened []string
+	orig := startOpener
+	t.Cleanup(func() { startOpener = orig })
+
+	for _, tc := range []struct {
+		name   string
+		err    error
+		status string
+	}{
+		{name: "success", status: "Opened in browser"},
+		{name: "failure", err: errors.New("no browser"), status: "Couldn't open browser: no browser"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			startOpener = func(cmd *exec.Cmd) error {
+				opened = append(opened, cmd.Args[len(cmd.Args)-1])
+				return tc.err
+			}
+
+			m := newTestPager(t, Config{}, "current.md", 80)
+			m.common.cwd = root
+			m.currentDocument = markdown{localPath: current, Note: "current.md", Body: body}
+			m.extractLinks()
+			if len(m.links) != 2 || !m.links[0].External || m.links[1].External {
+				t.Fatalf("expected an external and a local link, got %+v", m.links)
+			}
+
+			m = typeKeys(t, m, keyTab)
+			if want := "Open: https://example.com/docs"; m.statusMessage != want {
+				t.Fatalf("expected status %q, got %q", want, m.statusMessage)
+			}
+
+			opened = nil
+			m, cmd := m.update(tea.KeyMsg{Type: tea.KeyEnter})
+			m, _ = m.update(cmd())
+			if len(opened) != 1 || opened[0] != "https://example.com/docs" {
+				t.Fatalf("expected the link to be opened in the browser, got %q", opened)
+			}
+			if len(m.history) != 0 {
+				t.Fatalf("expected to stay on the document, got history %v", m.history)
+			}
+			if m.statusMessage != tc.status {
+				t.Fatalf("expected status %q, got %q", tc.status, m.statusMessage)
+			}
+		})
+	}
+}
+
+func TestFormattedLinkLabels(t *testing.T) {
+	root := absEvalSymlinks(t, t.TempDir())
+	current := filepath.Join(root, "index.md")
+	md := "See [**Bold** link](a.md), [*some* `code`](b.md) and [_very_\nlong](c.md).\n"
+	mustWriteFile(t, current, md)
+	for _, name := range []string{"a.md", "b.md", "c.md"} {
+		mustWriteFile(t, filepath.Join(root, name), "")
+	}
+
+	m := newTestPager(t, Config{}, "index.md", 80)
+	m.common.cwd = root
+	m.currentDocument = markdown{localPath: current, Note: "index.md", Body: md}
+	rendered, err := glamourRender(m, md)
+	if err != nil {
+		t.Fatalf("glamourRender returned error: %v", err)
+	}
+	m.rendered = rendered
+	m.applyRenderedContent()
+	m.extractLinks()
+
+	for i, want := range []struct{ label, note string }{
+		{"Bold link", "a.md"},
+		{"some code", "b.md"},
+		{"very long", "c.md"},
+	} {
+		l := m.links[i]
+		if l.Label != want.label {
+			t.Errorf("link %d: expected label %q, got %q", i, want.label, l.Label)
+		}
+
+		m = typeKeys(t, m, keyTab)
+		if status := "Open: " + want.note; m.statusMessage != status {
+			t.Errorf("link %d: expected status %q, got %q", i, status, m.statusMessage)
+		}
+
+		got := highlightFocusedLink(rendered, m.links, i, reverseSpan)
+		start := strings.Index(got, reverseOn)
+		end := strings.Index(got, reverseOff)
+		if start < 0 || end < start {
+			t.Fatalf("link %d (%s) wasn't highlighted", i, want.label)
+		}
+		if span := normalizeSpace(stripANSI(got[start:end])); span != want.label {
+			t.Errorf("link %d: expected %q to be highlighted, got %q", i, want.label, span)
+		}
+	}
+}
+
+func TestFollowNonMarkdownLinks(t *testing.T) {
+	base := absEvalSymlinks(t, t.TempDir())
+	root := filepath.Join(base, "root")
+	current := filepath.Join(root, "index.md")
+	body := "[code](main.go), [data](data/config.json), [outside](../secret.txt),\n" +
+		"[missing](missing.txt), [dir](pkg.d) and [doc](other.md).\n"
+	mustWriteFile(t, current, body)
+	mustWriteFile(t, filepath.Join(root, "main.go"), "package main\n")
+	mustWriteFile(t, filepath.Join(root, "data", "config.json"), "{}\n")
+	mustWriteFile(t, filepath.Join(root, "other.md"), "")
+	mustWriteFile(t, filepath.Join(base, "secret.txt"), "")
+	mustMkdirAll(t, filepath.Join(root, "pkg.d"))
+
+	for _, enabled := range []bool{false, true} {
+		t.Run(fmt.Sprintf("enabled_%v", enabled), func(t *testing.T) {
+			m := newTestPager(t, Config{FollowNonMarkdownLinks: enabled}, "index.md", 80)
+			m.common.cwd = root
+			m.currentDocument = markdown{localPath: current, Note: "index.md", Body: body}
+			m.extractLinks()
+
+			var labels []string
+			for _, l := range m.links {
+				labels = append(labels, l.Label)
+			}
+			want := []string{"doc"}
+			if enabled {
+				want = []string{"code", "data", "doc"}
+			}
+			if strings.Join(labels, ",") != strings.Join(want, ",") {
+				t.Fatalf("expected links %q, got %q", want, labels)
+			}
+			if !enabled {
+				return
+			}
+
+			m = typeKeys(t, m, keyTab, keyTab)
+			if want := "Edit: data/config.json"; m.statusMessage != want {
+				t.Fatalf("expected status %q, got %q", want, m.statusMessage)
+			}
+			if cmd := m.followFocusedLink(); cmd == nil || len(m.history) != 0 {
+				t.Fatalf("expected the file to be opened in the editor, got history %v", m.history)
+			}
+
+			m = typeKeys(t, m, keyTab)
+			if want := "Open: other.md"; m.statusMessage != want {
+				t.Fatalf("expected status %q, got %q", want, m.statusMessage)
+			}
+		})
+	}
+}
+
+func TestWikiLinks(t *testing.T) {
+	base := absEvalSymlinks(t, t.TempDir())
+	root := filepath.Join(base, "vault")
+	current := filepath.Join(root, "daily", "today.md")
+	body := "See [[Note Name]], [a link](plain.md) and [[Project Plan|the plan]].\n\n" +
+		"## [[Note Name#Next Steps]]\n\n" +
+		"Not `[[Code Span]]`, ![[Embedded]] or [[Missing Note]] or [[../secret]].\n\n" +
+		"```\n[[In Code]]\n```\n"
+	mustWriteFile(t, current, body)
+	mustWriteFile(t, filepath.Join(root, "Note Name.md"), "")
+	mustWriteFile(t, filepath.Join(root, "plain.md"), "")
+	mustWriteFile(t, filepath.Join(root, "daily", "plain.md"), "")
+	mustWriteFile(t, filepath.Join(root, "projects", "Project Plan.md"), "")
+	mustWriteFile(t, filepath.Join(root, ".trash", "Missing Note.md"), "")
+	mustWriteFile(t, filepath.Join(base, "secret.md"), "")
+
+	links, err := followableLinksForDocument(root, current, body)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var got []string
+	for _, l := range links {
+		got = append(got, l.Label+" -> "+filepath.ToSlash(l.ResolvedNote)+"#"+l.Fragment)
+	}
+	want := []string{
+		"Note Name -> Note Name.md#",
+		"a link -> daily/plain.md#",
+		"the plan -> projects/Project Plan.md#",
+		"Note Name#Next Steps -> Note Name.md#next-steps",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("expected links\n%s\ngot\n%s", strings.Join(want, "\n"), strings.Join(got, "\n"))
+	}
+
+	broken, err := brokenLinksForDocument(root, current, body, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got = nil
+	for _, l := range broken {
+		got = append(got, l.Label+": "+l.Reason)
+	}
+	want = []string{"Missing Note: " + brokenLinkNotFound, "../secret: " + brokenLinkOutsideRoot}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("expected broken links\n%s\ngot\n%s", strings.Join(want, "\n"), strings.Join(got, "\n"))
+	}
+}
+
+func TestWikiIndexCache(t *testing.T) {
+	root := absEvalSymlinks(t, t.TempDir())
+	current := filepath.Join(root, "index.md")
+	body := "See [[Later Note]].\n"
+	mustWriteFile(t, current, body)
+
+	var wiki wikiIndexCache
+	broken := func() int {
+		t.Helper()
+		b, err := brokenLinksForDocument(root, current, body, &wiki)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return len(b)
+	}
+	if broken() != 1 {
+		t.Fatal("expected the link to a missing note to be broken")
+	}
+
+	// The root isn't walked again until the index is reset.
+	mustWriteFile(t, filepath.Join(root, "notes", "Later Note.md"), "")
+	if broken() != 1 {
+		t.Fatal("expected the kept index to be used")
+	}
+	wiki.reset()
+	if broken() != 0 {
+		t.Fatal("expected the note to be found once the index is reset")
+	}
+}
+
+func TestLinkList(t *testing.T) {
+	root := absEvalSymlinks(t, t.TempDir())
+	current := filepath.Join(root, "index.md")
+	body := "[First](one.md), [Second](docs/two.md#usage) and [Third](three.md).\n"
+	mustWriteFile(t, current, body)
+	mustWriteFile(t, filepath.Join(root, "one.md"), "")
+	mustWriteFile(t, filepath.Join(root, "docs", "two.md"), "")
+	mustWriteFile(t, filepath.Join(root, "three.md"), "")
+
+	m := newTestPager(t, Config{}, "index.md", 80)
+	m.common.cwd = root
+	m.currentDocument = markdown{localPath: current, Note: "index.md", Body: body}
+	m.extractLinks()
+
+	var got []string
+	for _, it := range linkOverlayItems(m.links) {
+		got = append(got, it.Label+" "+it.Detail)
+	}
+	want := []string{"First one.md", "Second docs/two.md#usage", "Third three.md"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Fatalf("expected items %q, got %q", want, got)
+	}
+
+	// The list opens on the focused link, and closing it keeps the focus.
+	m = typeKeys(t, m, keyTab, keyTab, "L")
+	if m.overlay == nil || m.overlay.kind != overlayLinks || m.overlay.cursor != 1 {
+		t.Fatal("expected the link list to open on the focused link")
+	}
+	m = typeKeys(t, m, "j", keyEsc)
+	if m.overlay != nil || m.focusedLink != 1 {
+		t.Fatalf("expected esc to close the list and keep link 1 focused, got %d", m.focusedLink)
+	}
+
+	m = typeKeys(t, m, "L", "j")
+	if cmd := m.updateOverlay(tea.KeyMsg{Type: tea.KeyEnter}); cmd == nil {
+		t.Fatal("expected enter to follow the selected link")
+	}
+	if len(m.history) != 1 {
+		t.Fatalf("expected the link to be followed, got history %v", m.history)
+	}
+}
+
+func TestRefocusLinkAfterReload(t *testing.T) {
+	root := absEvalSymlinks(t, t.TempDir())
+	for _, name := range []string{"a.md", "b.md", "c.md"} {
+		mustWriteFile(t, filepath.Join(root, name), "# "+name+"\n")
+	}
+	m := newTestPager(t, Config{}, "a.md", 80)
+	m.common.cwd = root
+	m.currentDocument.localPath = filepath.Join(root, "a.md")
+
+	// reload pretends the document changed on disk while the link at the
+	// given index was focused.
+	reload := func(body string, focused int) {
+		t.Helper()
+		m.currentDocument.Body = body
+		prev := m.links[focused]
+		m.focusedLink = focused
+		m.reloading = true
+		m.extractLinks()
+		m.refocusLink(prev, focused)
+	}
+
+	m.currentDocument.Body = "[B](b.md) and [C](c.md)\n"
+	m.extractLinks()
+
+	// A link added above shifts the focused one down.
+	reload("[A](a.md), [B](b.md) and [C](c.md)\n", 1)
+	if m.focusedLink != 2 || m.links[m.focusedLink].Label != "C" {
+		t.Fatalf("expected C to stay focused, got %d", m.focusedLink)
+	}
+
+	// A link that's relabeled is found by where it leads.
+	reload("[A](a.md), [B](b.md) and [See C](c.md)\n", 2)
+	if m.focusedLink != 2 {
+		t.Fatalf("expected the relabeled link to stay focused, got %d", m.focusedLink)
+	}
+
+	reload("[A](a.md) and [B](b.md)\n", 2)
+	if m.focusedLink != -1 || !m.focusedLinkGone {
+		t.Fatalf("expected no link to be focused once it's gone, got %d", m.focusedLink)
+	}
+	m, _ = m.update(contentRenderedMsg("A and B"))
+	if m.statusMessage != "⟳ reloaded, the focused link is gone" || m.focusedLinkGone {
+		t.Fatalf("expected to be told the focused link is gone, got status %q", m.statusMessage)
+	}
+}
+
+func TestLinkSourceLine(t *testing.T) {
+	dir := t.TempDir()
+	doc := filepath.Join(dir, "guide.md")
+	mustWriteFile(t, doc, "# Guide\n\nIntro.\n\n## Install\n\nSteps.\n")
+
+	for _, tc := range []struct {
+		l    followableLink
+		want int
+	}{
+		{followableLink{ResolvedPath: doc, Fragment: "install"}, 5},
+		{followableLink{ResolvedPath: doc, Fragment: "L3"}, 3},
+		{followableLink{ResolvedPath: doc, Fragment: "nowhere"}, 0},
+		{followableLink{ResolvedPath: doc}, 0},
+		{followableLink{ResolvedPath: filepath.Join(dir, "missing.md"), Fragment: "install"}, 0},
+	} {
+		if got := linkSourceLine(tc.l, duplicateSlugsSuffix); got != tc.want {
+			t.Errorf("linkSourceLine(%s#%s): expected %d, got %d", filepath.Base(tc.l.ResolvedPath), tc.l.Fragment, tc.want, got)
+		}
+	}
+}
@@ -0,0 +1,187 @@
+package ui
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mattn/go-runewidth"
+)
+
+// textCell is a cell of the rendered output, by line and column.
+type textCell struct {
+	line, col int
+}
+
+// before reports whether the cell comes before another in reading order.
+func (c textCell) before(o textCell) bool {
+	return c.line < o.line || (c.line == o.line && c.col < o.col)
+}
+
+// mouseSelection is a region of the rendered output selected by dragging the
+// mouse, from the cell the button was pressed on to the one it's over.
+type mouseSelection struct {
+	anchor, cursor textCell
+	dragging       bool
+}
+
+// cells returns the first and last selected cell.
+func (s mouseSelection) cells() (textCell, textCell) {
+	if s.cursor.before(s.anchor) {
+		return s.cursor, s.anchor
+	}
+	return s.anchor, s.cursor
+}
+
+// empty reports whether nothing was dragged over, like after a click.
+func (s mouseSelection) empty() bool {
+	return s.anchor == s.cursor
+}
+
+// cellRunes returns the indexes of the runes of a line from the one that
+// covers column from to the one after the one that covers column to, so
+// that wide characters are selected whole.
+func cellRunes(runes []rune, from, to int) (int, int) {
+	start, end, col := len(runes), len(runes), 0
+	for i, r := range runes {
+		w := max(1, runewidth.RuneWidth(r))
+		if start == len(runes) && col+w > from {
+			start = i
+		}
+		if col > to {
+			end = i
+			break
+		}
+		col += w
+	}
+	return start, end
+}
+
+// selectedSpans returns the byte ranges of each line of the rendered output
+// that the region from first to last covers, by line. Columns before gutter
+// aren't covered.
+func selectedSpans(rendered string, first, last textCell, gutter int) map[int][2]int {
+	spans := map[int][2]int{}
+	start := 0
+	for i, l := range strings.Split(rendered, "\n") {
+		if i >= first.line && i <= last.line {
+			from, to := 0, runewidth.StringWidth(stripANSI(l))
+			if i == first.line {
+				from = first.col
+			}
+			if i == last.line {
+				to = last.col
+			}
+			from = max(from, gutter)
+			runes, offsets := printableRunesAndOffsets(l)
+			a, b := cellRunes(runes, from, to)
+			if a < b {
+				spans[i] = [2]int{start + offsets[a], start + offsets[b]}
+			}
+		}
+		start += len(l) + 1
+	}
+	return spans
+}
+
+// highlightRegion renders the region from first to last in reverse video.
+func highlightRegion(rendered string, first, last textCell) string {
+	spans := selectedSpans(rendered, first, last, 0)
+	// Work from the end so the offsets of earlier lines stay valid.
+	for l := last.line; l >= first.line; l-- {
+		if s, ok := spans[l]; ok {
+			rendered = highlightSpan(rendered, s[0], s[1])
+		}
+	}
+	return rendered
+}
+
+// regionText returns the text of the region from first to last, without
+// escape sequences, the padding at the end of lines or the first gutter
+// columns, where line numbers go.
+func regionText(rendered string, first, last textCell, gutter int) string {
+	spans := selectedSpans(rendered, first, last, gutter)
+	lines := make([]string, 0, last.line-first.line+1)
+	for l := first.line; l <= last.line; l++ {
+		var text string
+		if s, ok := spans[l]; ok {
+			text = strings.TrimRight(stripANSI(rendered[s[0]:s[1]]), " ")
+		}
+		lines = append(lines, text)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// mouseCell returns the cell of the rendered output at a position on the
+// screen.
+func (m pagerModel) mouseCell(x, y int) textCell {
+	line := y - m.viewport.YPosition + m.viewport.YOffset
+	line = max(0, min(m.viewport.TotalLineCount()-1, line))
+	return textCell{line: line, col: max(0, x-m.contentMargin()+m.xOffset)}
+}
+
+// updateMouse selects text by dragging with the left button. Other mouse
+// events, like the wheel, are left to the viewport.
+func (m *pagerModel) updateMouse(msg tea.MouseMsg) (tea.Cmd, bool) {
+	if m.capturesKeys() && m.mouseSelection == nil {
+		return nil, false
+	}
+	switch {
+	case msg.Button == tea.MouseButtonLeft && msg.Action == tea.MouseActionPress:
+		c := m.mouseCell(msg.X, msg.Y)
+		m.mouseSelection = &mouseSelection{anchor: c, cursor: c, dragging: true}
+		m.applyRenderedContent()
+		return nil, true
+
+	case msg.Action == tea.MouseActionMotion && m.mouseSelection != nil && m.mouseSelection.dragging:
+		// Dragging past the top or bottom scrolls.
+		switch {
+		case msg.Y < m.viewport.YPosition:
+			m.viewport.ScrollUp(1)
+		case msg.Y >= m.viewport.YPosition+m.viewport.Height:
+			m.viewport.ScrollDown(1)
+		}
+		m.mouseSelection.cursor = m.mouseCell(msg.X, msg.Y)
+		m.applyRenderedContent()
+		if m.common.cfg.HighPerformancePager {
+			return viewport.Sync(m.viewport), true
+		}
+		return nil, true
+
+	case msg.Action == tea.MouseActionRelease && m.mouseSelection != nil && m.mouseSelection.dragging:
+		m.mouseSelection.dragging = false
+		if m.mouseSelection.empty() {
+			m.stopMouseSelection()
+			return nil, true
+		}
+		return m.showStatusMessage(pagerStatusMessage{"c to copy the selection, esc to clear it", false}), true
+	}
+	return nil, false
+}
+
+// updateMouseSelection handles key presses while text is selected with the
+// mouse. Keys other than copying and clearing the selection clear it and do
+// what they usually do.
+func (m *pagerModel) updateMouseSelection(msg tea.KeyMsg) (tea.Cmd, bool) {
+	switch key := msg.String(); {
+	case m.keys.action(key) == actionCopy:
+		first, last := m.mouseSelection.cells()
+		gutter := 0
+		if m.common.cfg.CopyStripGutter {
+			gutter = m.gutterWidth()
+		}
+		text := regionText(m.rendered, first, last, gutter)
+		m.stopMouseSelection()
+		return m.copyContents(text, "Copied selection"), true
+	case key == keyEsc || key == "q":
+		m.stopMouseSelection()
+		return nil, true
+	}
+	m.stopMouseSelection()
+	return nil, false
+}
+
+func (m *pagerModel) stopMouseSelection() {
+	m.mouseSelection = nil
+	m.applyRenderedContent()
+}
@@ -0,0 +1,101 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestRegionText(t *testing.T) {
+	rendered := "\x1b[1mfirst\x1b[0m line   \n  second 世界 line\nthird"
+
+	for _, tc := range []struct {
+		first, last textCell
+		want        string
+	}{
+		{textCell{0, 0}, textCell{0, 4}, "first"},
+		{textCell{0, 6}, textCell{1, 8}, "line\n  second"},
+		// Half of a wide character selects all of it.
+		{textCell{1, 10}, textCell{1, 10}, "世"},
+		{textCell{1, 13}, textCell{2, 1}, " line\nth"},
+	} {
+		if got := regionText(rendered, tc.first, tc.last, 0); got != tc.want {
+			t.Errorf("regionText(%v, %v) = %q, want %q", tc.first, tc.last, got, tc.want)
+		}
+	}
+
+	highlighted := highlightRegion(rendered, textCell{0, 6}, textCell{1, 8})
+	if stripANSI(highlighted) != stripANSI(rendered) || !strings.Contains(highlighted, reverseOn+"line") {
+		t.Fatalf("expected the region in reverse video, got %q", highlighted)
+	}
+}
+
+func TestMouseSelection(t *testing.T) {
+	m := newTestPager(t, Config{}, "notes.txt", 80)
+	m, _ = m.update(contentRenderedMsg("alpha beta\ngamma delta\nepsilon"))
+
+	press := tea.MouseMsg{X: 6, Y: 0, Button: tea.MouseButtonLeft, Action: tea.MouseActionPress}
+	m, _ = m.update(press)
+	m, _ = m.update(tea.MouseMsg{X: 4, Y: 1, Button: tea.MouseButtonLeft, Action: tea.MouseActionMotion})
+	m, _ = m.update(tea.MouseMsg{X: 4, Y: 1, Button: tea.MouseButtonLeft, Action: tea.MouseActionRelease})
+	if m.mouseSelection == nil || !m.capturesKeys() {
+		t.Fatal("expected text to be selected")
+	}
+	if !strings.Contains(m.viewport.View(), reverseOn) {
+		t.Fatal("expected the selection to be shown in reverse video")
+	}
+
+	var copied string
+	stubClipboard(t, func(s string) error {
+		copied = s
+		return nil
+	}, failingClipboard)
+	m = typeKeys(t, m, "c")
+	if copied != "beta\ngamma" || m.mouseSelection != nil {
+		t.Fatalf("expected the selection to be copied, got %q", copied)
+	}
+
+	// A click selects nothing, and esc clears a selection.
+	m, _ = m.update(press)
+	m, _ = m.update(tea.MouseMsg{X: 6, Y: 0, Button: tea.MouseButtonLeft, Action: tea.MouseActionRelease})
+	if m.mouseSelection != nil {
+		t.Fatal("expected a click not to select anything")
+	}
+	m, _ = m.update(press)
+	m, _ = m.update(tea.MouseMsg{X: 9, Y: 0, Button: tea.MouseButtonLeft, Action: tea.MouseActionMotion})
+	m = typeKeys(t, m, keyEsc)
+	if m.mouseSelection != nil || strings.Contains(m.viewport.View(), reverseOn) {
+		t.Fatal("expected esc to clear the selection")
+	}
+}
+
+func TestMouseSelection_LineNumbers(t *testing.T) {
+	m := newTestPager(t, Config{ShowLineNumbers: true, CopyStripGutter: true}, "README.md", 80)
+	out, err := glamourRender(m, "alpha beta\n\ngamma delta\n")
+	if err != nil {
+		t.Fatalf("glamourRender returned error: %v", err)
+	}
+	m, _ = m.update(contentRenderedMsg(out))
+	if !strings.Contains(stripANSI(m.viewport.View()), "1") {
+		t.Fatal("expected line numbers in the view")
+	}
+
+	var copied string
+	stubClipboard(t, func(s string) error {
+		copied = s
+		return nil
+	}, failingClipboard)
+
+	// Dragging over the line numbers leaves them out.
+	m, _ = m.update(tea.MouseMsg{X: 0, Y: 0, Button: tea.MouseButtonLeft, Action: tea.MouseActionPress})
+	m, _ = m.update(tea.MouseMsg{X: 40, Y: 4, Button: tea.MouseButtonLeft, Action: tea.MouseActionMotion})
+	m, _ = m.update(tea.MouseMsg{X: 40, Y: 4, Button: tea.MouseButtonLeft, Action: tea.MouseActionRelease})
+	m = typeKeys(t, m, "c")
+	if !strings.Contains(copied, "alpha beta") || !strings.Contains(copied, "gamma delta") {
+		t.Fatalf("expected the selected text to be copied, got %q", copied)
+	}
+	if strings.ContainsAny(copied, "0123456789") {
+		t.Fatalf("expected the selection without line numbers, got %q", copied)
+	}
+}
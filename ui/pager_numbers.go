@@ -0,0 +1,115 @@
+package ui
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/glow/v2/utils"
+)
+
+// relativeLineNumbers rewrites the line number gutter of the rendered output
+// to show how far each line is from the one at the top of the viewport,
+// like vim's relativenumber. The top line keeps its own number. Numbers
+// start at first.
+//
+// Only digits and the spaces padding them are replaced, so the output keeps
+// its byte offsets and search matches and the like still line up.
+func relativeLineNumbers(rendered string, first, top int) string {
+	lines := strings.Split(rendered, "\n")
+
+	type gutter struct {
+		n          int
+		start, end int // byte range of the number and its padding
+	}
+	gutters := make([]gutter, len(lines))
+	current, prev := 0, first-1
+	for i, l := range lines {
+		// Numbers go up by one, and wrapped lines have an empty gutter.
+		n := prev + 1
+		num := strconv.Itoa(n)
+		width := max(lineNumberWidth, len(num))
+
+		printable, offsets := printableRunesAndOffsets(l)
+		if len(printable) < width || !strings.HasSuffix(string(printable[:width]), num) {
+			continue
+		}
+		pad := width - len(num)
+		for pad > 0 && printable[pad-1] == ' ' {
+			pad--
+		}
+		if pad > 0 && printable[pad-1] >= '0' && printable[pad-1] <= '9' {
+			continue
+		}
+		// The number ends at its last digit, before any escape sequences
+		// that come ahead of the next printable rune.
+		gutters[i] = gutter{n: n, start: offsets[pad], end: offsets[width-1] + 1}
+		prev = n
+		if i <= top {
+			current = n
+		}
+	}
+
+	for i, g := range gutters {
+		if g.n == 0 || g.n == current {
+			continue
+		}
+		distance := strconv.Itoa(max(g.n-current, current-g.n))
+		if len(distance) > g.end-g.start {
+			continue
+		}
+		l := lines[i]
+		lines[i] = l[:g.start] + fmt.Sprintf("%*s", g.end-g.start, distance) + l[g.end:]
+	}
+	return strings.Join(lines, "\n")
+}
+
+// toggleRelativeNumbers switches between absolute and relative line numbers.
+func (m *pagerModel) toggleRelativeNumbers() tea.Cmd {
+	if m.gutterWidth() == 0 || (utils.IsMarkdownFile(m.currentDocument.Note) && !m.common.cfg.ShowLineNumbers) {
+		return m.showStatusMessage(pagerStatusMessage{"No line numbers", false})
+	}
+	m.relativeNumbers = !m.relativeNumbers
+	m.applyRenderedContent()
+
+	msg := "Relative line numbers"
+	if !m.relativeNumbers {
+		msg = "Absolute line numbers"
+	}
+	return m.showStatusMessage(pagerStatusMessage{msg, false})
+}
+
+// renumberLines updates relative line numbers after scrolling.
+func (m *pagerModel) renumberLines() tea.Cmd {
+	if !m.relativeNumbers || m.numberedTop == m.viewport.YOffset {
+		return nil
+	}
+	m.applyRenderedContent()
+	if m.common.cfg.HighPerformancePager {
+		return viewport.Sync(m.viewport)
+	}
+	return nil
+}
+
+// linePositionView returns the line at the top of the viewport and the number
+// of lines for the status bar, like L120/480, or "" if it's not shown.
+func (m pagerModel) linePositionView() string {
+	total := m.viewport.TotalLineCount()
+	if !m.showLinePosition || total == 0 {
+		return ""
+	}
+	return fmt.Sprintf(" L%d/%d", min(m.viewport.YOffset+1, total), total)
+}
+
+// toggleLinePosition shows or hides the line position in the status bar.
+func (m *pagerModel) toggleLinePosition() tea.Cmd {
+	m.showLinePosition = !m.showLinePosition
+
+	msg := "Line position shown"
+	if !m.showLinePosition {
+		msg = "Line position hidden"
+	}
+	return m.showStatusMessage(pagerStatusMessage{msg, false})
+}
@@ -0,0 +1,164 @@
+package ui
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/reflow/truncate"
+)
+
+var (
+	overlayTitleStyle = lipgloss.NewStyle().
+				Foreground(cream).
+				Background(fuchsia).
+				Padding(0, 1).
+				Render
+
+	overlaySelectedStyle = lipgloss.NewStyle().
+				Foreground(fuchsia).
+				Render
+
+	overlayDetailStyle = lipgloss.NewStyle().
+				Foreground(gray).
+				Render
+)
+
+// overlayKind identifies what a list overlay is showing.
+type overlayKind int
+
+const (
+	overlayRelatedDocs overlayKind = iota
+	overlayBlame
+	overlayLinkGraph
+	overlayDirectory
+	overlayStats
+	overlayTOC
+	overlayLinks
+	overlayBookmarks
+	overlayRecent
+)
+
+// overlayItem is a selectable entry in a list overlay.
+type overlayItem struct {
+	Label  string
+	Detail string
+
+	// Local file to open when the item is selected.
+	Path string
+	Note string
+}
+
+// listOverlay is a selectable list drawn in place of the viewport.
+type listOverlay struct {
+	kind   overlayKind
+	title  string
+	items  []overlayItem
+	cursor int
+}
+
+func (o *listOverlay) moveCursor(delta int) {
+	if len(o.items) == 0 {
+		return
+	}
+	o.cursor = max(0, min(len(o.items)-1, o.cursor+delta))
+}
+
+func (o listOverlay) selectedItem() (overlayItem, bool) {
+	if o.cursor < 0 || o.cursor >= len(o.items) {
+		return overlayItem{}, false
+	}
+	return o.items[o.cursor], true
+}
+
+// view renders the overlay so that it fills exactly width x height cells.
+func (o listOverlay) view(width, height int) string {
+	const headerHeight = 2 // title and gap
+
+	lines := []string{" " + overlayTitleStyle(o.title), ""}
+
+	if len(o.items) == 0 {
+		lines = append(lines, "  "+overlayDetailStyle("Nothing to show."))
+	}
+
+	// Scroll the list so the cursor is always visible.
+	visible := max(1, height-headerHeight)
+	start := 0
+	if o.cursor >= visible {
+		start = o.cursor - visible + 1
+	}
+	end := min(len(o.items), start+visible)
+
+	for i := start; i < end; i++ {
+		item := o.items[i]
+		prefix, label := "  ", item.Label
+		if i == o.cursor {
+			prefix, label = overlaySelectedStyle("│ "), overlaySelectedStyle(label)
+		}
+		line := prefix + label
+		if item.Detail != "" {
+			line += "  " + overlayDetailStyle(item.Detail)
+		}
+		lines = append(lines, truncate.StringWithTail(" "+line, uint(max(0, width)), ellipsis)) //nolint:gosec
+	}
+
+	for len(lines) < height {
+		lines = append(lines, "")
+	}
+	return strings.Join(lines[:max(0, height)], "\n")
+}
+
+// capturesKeys reports whether the pager wants to receive every key press,
+// including ones that would normally be handled by the main model.
+func (m pagerModel) capturesKeys() bool {
+	return m.overlay != nil || m.table != nil || m.presenting || m.searching || m.promptingCommand || m.definitions != nil || m.selection != nil || m.hints != nil || m.mouseSelection != nil
+}
+
+func (m *pagerModel) openOverlay(o *listOverlay) {
+	m.overlay = o
+}
+
+func (m *pagerModel) closeOverlay() {
+	m.overlay = nil
+}
+
+// updateOverlay handles key presses while an overlay is open.
+func (m *pagerModel) updateOverlay(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "k", "up", "ctrl+k":
+		m.overlay.moveCursor(-1)
+	case "j", "down", "ctrl+j":
+		m.overlay.moveCursor(1)
+	case "home", "g":
+		m.overlay.cursor = 0
+	case "end", "G":
+		m.overlay.cursor = max(0, len(m.overlay.items)-1)
+	case "q", keyEsc:
+		m.closeOverlay()
+	case "d", "x":
+		if m.overlay.kind == overlayBookmarks {
+			return m.deleteSelectedBookmark()
+		}
+	case keyEnter:
+		item, ok := m.overlay.selectedItem()
+		kind, cursor := m.overlay.kind, m.overlay.cursor
+		m.closeOverlay()
+		if ok && kind == overlayTOC {
+			// Items are the document's headings, in order.
+			return m.jumpToHeading(cursor)
+		}
+		if ok && kind == overlayBookmarks && cursor < len(m.bookmarks) {
+			// Items are the bookmarks, in order.
+			return m.openBookmark(m.bookmarks[cursor])
+		}
+		if ok && kind == overlayLinks && cursor < len(m.links) {
+			// Items are the document's links, in order.
+			m.focusedLink = cursor
+			return m.followFocusedLink()
+		}
+		if ok && item.Path != "" {
+			return m.navigateTo(item.Path, item.Note)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package ui
+
+import (
+	"path/filepath"
+
+	"github.com/charmbracelet/log"
+)
+
+// scrollPositions are the scroll offsets documents were left at, by absolute
+// path, kept between sessions.
+type scrollPositions map[string]int
+
+// loadScrollPositions reads the positions kept in a file. Positions of files
+// that no longer exist are dropped.
+func loadScrollPositions(path string) scrollPositions {
+	var positions scrollPositions
+	if !loadStateFile(path, "scroll positions", &positions) || positions == nil {
+		return scrollPositions{}
+	}
+	for p := range positions {
+		if !fileExists(p) {
+			delete(positions, p)
+		}
+	}
+	return positions
+}
+
+// save writes the positions to a file.
+func (p scrollPositions) save(path string) error {
+	return saveStateFile(path, p)
+}
+
+// positionKey returns the key the position of a local file is kept under.
+func positionKey(localPath string) string {
+	if localPath == "" {
+		return ""
+	}
+	abs, err := filepath.Abs(localPath)
+	if err != nil {
+		return ""
+	}
+	return abs
+}
+
+// rememberPosition notes where the current document is scrolled to. Documents
+// left at the top are forgotten.
+func (m *pagerModel) rememberPosition() {
+	key := positionKey(m.currentDocument.localPath)
+	if key == "" || m.positions == nil {
+		return
+	}
+	if m.movedPositions == nil {
+		m.movedPositions = map[string]bool{}
+	}
+	m.movedPositions[key] = true
+	if m.viewport.YOffset == 0 {
+		delete(m.positions, key)
+		return
+	}
+	m.positions[key] = m.viewport.YOffset
+}
+
+// savePositions remembers where the current document is scrolled to and
+// writes the positions to the positions file, keeping those saved by others.
+func (m *pagerModel) savePositions() {
+	path := m.common.cfg.PositionsFile
+	if path == "" {
+		return
+	}
+	m.rememberPosition()
+
+	// Another glow may have saved positions of its own since they were
+	// read, so read them again and only change those moved here.
+	positions := loadScrollPositions(path)
+	for key := range m.movedPositions {
+		if y, ok := m.positions[key]; ok {
+			positions[key] = y
+		} else {
+			delete(positions, key)
+		}
+	}
+	m.positions = positions
+	m.movedPositions = nil
+	if err := m.positions.save(path); err != nil {
+		log.Debug("error saving scroll positions", "file", path, "error", err)
+	}
+}
+
+// restorePosition scrolls a document that's just been opened to where it was
+// left, unless it's already on its way somewhere else, like back in the
+// history or to a link fragment. Reloading the same document leaves it be.
+func (m *pagerModel) restorePosition() {
+	key := positionKey(m.currentDocument.localPath)
+	if key == "" || key == m.positionPath {
+		return
+	}
+	m.positionPath = key
+	if m.pendingRestoreYOffset != nil || m.pendingAnchor != nil ||
+		m.pendingSourceLine > 0 || m.pendingFragment != "" {
+		return
+	}
+	if y, ok := m.positions[key]; ok {
+		m.pendingRestoreYOffset = &y
+	}
+}
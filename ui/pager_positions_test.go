@@ -0,0 +1,81 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestScrollPositions(t *testing.T) {
+	dir := t.TempDir()
+	doc := filepath.Join(dir, "notes.md")
+	if err := os.WriteFile(doc, []byte("notes"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	file := filepath.Join(dir, "state", "positions.json")
+
+	m := newTestPager(t, Config{PositionsFile: file}, "notes.md", 80)
+	m.currentDocument.localPath = doc
+	m.restorePosition()
+	m, _ = m.update(contentRenderedMsg(strings.Repeat("line\n", 100)))
+	m.viewport.SetYOffset(30)
+	m.unload()
+
+	// A file that's gone is forgotten when the positions are read again.
+	gone := filepath.Join(dir, "gone.md")
+	positions := loadScrollPositions(file)
+	positions[gone] = 10
+	if err := positions.save(file); err != nil {
+		t.Fatal(err)
+	}
+
+	m = newTestPager(t, Config{PositionsFile: file}, "notes.md", 80)
+	if _, ok := m.positions[gone]; ok || m.positions[doc] != 30 {
+		t.Fatalf("expected only the position of notes.md to be kept, got %v", m.positions)
+	}
+	m.currentDocument.localPath = doc
+	m.restorePosition()
+	m, _ = m.update(contentRenderedMsg(strings.Repeat("line\n", 100)))
+	if m.viewport.YOffset != 30 {
+		t.Fatalf("expected the document to open where it was left, got offset %d", m.viewport.YOffset)
+	}
+
+	// Reloading the same document doesn't jump back.
+	m.viewport.SetYOffset(50)
+	m.restorePosition()
+	m, _ = m.update(contentRenderedMsg(strings.Repeat("line\n", 100)))
+	if m.viewport.YOffset != 50 {
+		t.Fatalf("expected a reload to stay put, got offset %d", m.viewport.YOffset)
+	}
+}
+
+func TestScrollPositions_Merge(t *testing.T) {
+	dir := t.TempDir()
+	a, b := filepath.Join(dir, "a.md"), filepath.Join(dir, "b.md")
+	for _, p := range []string{a, b} {
+		if err := os.WriteFile(p, []byte("notes"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	file := filepath.Join(dir, "positions.json")
+	if err := (scrollPositions{b: 5}).save(file); err != nil {
+		t.Fatal(err)
+	}
+
+	// Two glows open at once each keep the other's positions when saving.
+	open := func(path string, y int) pagerModel {
+		m := newTestPager(t, Config{PositionsFile: file}, filepath.Base(path), 80)
+		m.currentDocument.localPath = path
+		m, _ = m.update(contentRenderedMsg(strings.Repeat("line\n", 100)))
+		m.viewport.SetYOffset(y)
+		return m
+	}
+	first, second := open(a, 20), open(b, 0)
+	first.unload()
+	second.unload()
+
+	if saved := loadScrollPositions(file); len(saved) != 1 || saved[a] != 20 {
+		t.Fatalf("expected a's position to be kept and b's to be forgotten at the top, got %v", saved)
+	}
+}
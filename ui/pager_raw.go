@@ -0,0 +1,69 @@
+package ui
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/glow/v2/utils"
+)
+
+// toggleRaw switches between the rendered document and its source, keeping
+// the same source line at the top of the viewport.
+func (m *pagerModel) toggleRaw() tea.Cmd {
+	if m.currentDocument.binary {
+		return m.showStatusMessage(pagerStatusMessage{"Not a text document", false})
+	}
+	m.pendingSourceLine = m.topSourceLine()
+	m.currentDocument.raw = !m.currentDocument.raw
+
+	msg := "Rendered"
+	if m.currentDocument.raw {
+		msg = "Raw"
+	}
+	return tea.Batch(
+		m.showStatusMessage(pagerStatusMessage{msg, false}),
+		renderWithGlamour(*m, m.currentDocument.Body),
+	)
+}
+
+// topSourceLine returns the source line, counting from 1, of the first line
+// at the top of the viewport that comes from the source, or 0.
+func (m pagerModel) topSourceLine() int {
+	if m.currentDocument.raw {
+		return m.viewport.YOffset + 1
+	}
+	source := m.renderedSourceLines()
+	for l := max(0, m.viewport.YOffset); l < len(source); l++ {
+		if source[l] > 0 {
+			return source[l]
+		}
+	}
+	return 0
+}
+
+// renderedSourceLines maps each rendered line to the source line it came
+// from, or 0.
+func (m pagerModel) renderedSourceLines() []int {
+	if m.currentDocument.raw {
+		source := make([]int, m.viewport.TotalLineCount())
+		for i := range source {
+			source[i] = i + 1
+		}
+		return source
+	}
+	if !utils.IsMarkdownFile(m.currentDocument.Note) {
+		return renderedSourceLines(m.currentDocument.Body, m.rendered, true)
+	}
+	// Line numbers and heading indicators aren't part of the text.
+	rendered := stripGutter(stripANSI(m.rendered), m.gutterWidth())
+	return renderedSourceLines(m.currentDocument.Body, rendered, false)
+}
+
+// sourceLineOffset returns the first rendered line that comes from the given
+// source line or one after it, or -1.
+func sourceLineOffset(source []int, n int) int {
+	for i, s := range source {
+		if s >= n {
+			return i
+		}
+	}
+	return -1
+}
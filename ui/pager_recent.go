@@ -0,0 +1,101 @@
+package ui
+
+import (
+	"slices"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/log"
+)
+
+// maxRecentDocuments is how many recently viewed documents are remembered.
+const maxRecentDocuments = 30
+
+// loadRecentDocuments reads the recently viewed documents kept in a file,
+// most recent first. Documents that no longer exist are dropped.
+func loadRecentDocuments(path string) []string {
+	var recent []string
+	if !loadStateFile(path, "recent documents", &recent) {
+		return nil
+	}
+	recent = slices.DeleteFunc(recent, func(p string) bool { return !fileExists(p) })
+	return recent[:min(len(recent), maxRecentDocuments)]
+}
+
+// saveRecentDocuments writes the recently viewed documents to a file.
+func saveRecentDocuments(path string, recent []string) error {
+	return saveStateFile(path, recent)
+}
+
+// addRecentDocument moves a document to the front of the recently viewed
+// ones, forgetting the oldest once there are too many.
+func addRecentDocument(recent []string, path string) []string {
+	recent = slices.DeleteFunc(recent, func(p string) bool { return p == path })
+	recent = append([]string{path}, recent...)
+	return recent[:min(len(recent), maxRecentDocuments)]
+}
+
+// rememberRecent notes that the current document was viewed. The recent
+// documents file is written when the document is unloaded or glow quits.
+func (m *pagerModel) rememberRecent() {
+	path := positionKey(m.currentDocument.localPath)
+	if path == "" || (len(m.recent) > 0 && m.recent[0] == path) {
+		return
+	}
+	m.recent = addRecentDocument(m.recent, path)
+	if m.common.cfg.RecentFile != "" {
+		m.viewedRecent = append(m.viewedRecent, path)
+	}
+}
+
+// saveRecent writes the recently viewed documents to the recent documents
+// file, keeping those saved by others.
+func (m *pagerModel) saveRecent() {
+	file := m.common.cfg.RecentFile
+	if file == "" || len(m.viewedRecent) == 0 {
+		return
+	}
+
+	// Another glow may have saved documents of its own since they were
+	// read, so read them again and add those viewed here.
+	recent := loadRecentDocuments(file)
+	for _, p := range m.viewedRecent {
+		recent = addRecentDocument(recent, p)
+	}
+	m.recent = recent
+	m.viewedRecent = nil
+	if err := saveRecentDocuments(file, m.recent); err != nil {
+		log.Debug("error saving recent documents", "file", file, "error", err)
+	}
+}
+
+// recentOverlayItems lists the recently viewed documents other than the
+// current one, most recent first.
+func recentOverlayItems(cwd, current string, recent []string) []overlayItem {
+	items := make([]overlayItem, 0, len(recent))
+	for _, p := range recent {
+		if p == current {
+			continue
+		}
+		note := stripAbsolutePath(p, cwd)
+		if note == "" {
+			// The document is gone.
+			note = p
+		}
+		items = append(items, overlayItem{Label: note, Path: p, Note: note})
+	}
+	return items
+}
+
+// openRecent lists the recently viewed documents to pick one to open.
+func (m *pagerModel) openRecent() tea.Cmd {
+	items := recentOverlayItems(m.common.cwd, positionKey(m.currentDocument.localPath), m.recent)
+	if len(items) == 0 {
+		return m.showStatusMessage(pagerStatusMessage{"No other recent documents", false})
+	}
+	m.openOverlay(&listOverlay{
+		kind:  overlayRecent,
+		title: "Recent documents",
+		items: items,
+	})
+	return nil
+}
@@ -0,0 +1,73 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestRecentDocuments(t *testing.T) {
+	recent := addRecentDocument([]string{"/a", "/b", "/c"}, "/b")
+	if !slices.Equal(recent, []string{"/b", "/a", "/c"}) {
+		t.Fatalf("expected a viewed document to move to the front, got %q", recent)
+	}
+
+	dir := t.TempDir()
+	a, b := filepath.Join(dir, "a.md"), filepath.Join(dir, "b.md")
+	for _, p := range []string{a, b} {
+		if err := os.WriteFile(p, []byte("# Doc\n"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	file := filepath.Join(dir, "recent.json")
+	if err := saveRecentDocuments(file, []string{b, filepath.Join(dir, "gone.md"), a}); err != nil {
+		t.Fatal(err)
+	}
+
+	m := newTestPager(t, Config{RecentFile: file}, "a.md", 80)
+	if !slices.Equal(m.recent, []string{b, a}) {
+		t.Fatalf("expected documents that are gone to be forgotten, got %q", m.recent)
+	}
+	m.currentDocument.localPath = a
+	m.rememberRecent()
+	if saved := loadRecentDocuments(file); !slices.Equal(saved, []string{b, a}) {
+		t.Fatalf("expected the file to be left alone until the document is unloaded, got %q", saved)
+	}
+
+	// Another glow saved a document of its own meanwhile.
+	c := filepath.Join(dir, "c.md")
+	if err := os.WriteFile(c, []byte("# Doc\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := saveRecentDocuments(file, []string{c, b, a}); err != nil {
+		t.Fatal(err)
+	}
+	m.saveRecent()
+	if saved := loadRecentDocuments(file); !slices.Equal(saved, []string{a, c, b}) {
+		t.Fatalf("expected the current document to be saved first, got %q", saved)
+	}
+
+	m, _ = m.update(tea.KeyMsg{Type: tea.KeyCtrlO})
+	if m.overlay == nil || len(m.overlay.items) != 2 || m.overlay.items[0].Path != c {
+		t.Fatal("expected the other recent documents to be listed")
+	}
+	m = typeKeys(t, m, keyEnter)
+	if len(m.history) != 1 || m.history[0].Path != a {
+		t.Fatalf("expected the current document to be on the back-stack, got %+v", m.history)
+	}
+}
+
+func TestRecentDocuments_Session(t *testing.T) {
+	// Without a file, documents are only remembered for the session.
+	m := newTestPager(t, Config{}, "a.md", 80)
+	for _, p := range []string{"/a.md", "/b.md"} {
+		m.currentDocument.localPath = p
+		m.rememberRecent()
+	}
+	if !slices.Equal(m.recent, []string{"/b.md", "/a.md"}) {
+		t.Fatalf("expected both documents to be remembered, got %q", m.recent)
+	}
+}
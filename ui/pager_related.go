@@ -0,0 +1,120 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/charmbracelet/log"
+)
+
+// siblingMarkdownFiles returns the absolute paths of the markdown files that
+// live in the same directory as path, excluding path itself.
+func siblingMarkdownFiles(path string) ([]string, error) {
+	dir := filepath.Dir(path)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err //nolint:wrapcheck
+	}
+
+	var out []string
+	for _, e := range entries {
+		if !e.Type().IsRegular() || !isMarkdownName(e.Name()) {
+			continue
+		}
+		p := filepath.Join(dir, e.Name())
+		if p == path {
+			continue
+		}
+		out = append(out, p)
+	}
+	sort.Strings(out)
+	return out, nil
+}
+
+func isMarkdownName(name string) bool {
+	for _, pattern := range markdownExtensions {
+		if ok, _ := filepath.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// relatedDocs returns documents related to the one at path: the targets of
+// its followable links in document order, followed by its sibling markdown
+// files.
+func relatedDocs(cwd, path string, links []followableLink) []overlayItem {
+	var (
+		items []overlayItem
+		index = map[string]int{}
+		self  = evalSymlinksOrSelf(path)
+	)
+
+	for _, l := range links {
+		if l.ResolvedPath == "" || l.ResolvedPath == self {
+			continue
+		}
+		if _, ok := index[l.ResolvedPath]; ok {
+			continue
+		}
+		index[l.ResolvedPath] = len(items)
+		items = append(items, overlayItem{
+			Label:  l.ResolvedNote,
+			Detail: "linked",
+			Path:   l.ResolvedPath,
+			Note:   l.ResolvedNote,
+		})
+	}
+
+	siblings, err := siblingMarkdownFiles(path)
+	if err != nil {
+		log.Debug("error listing sibling documents", "error", err)
+	}
+	for _, p := range siblings {
+		if i, ok := index[evalSymlinksOrSelf(p)]; ok {
+			items[i].Detail = "linked, sibling"
+			continue
+		}
+		note := stripAbsolutePath(p, cwd)
+		items = append(items, overlayItem{
+			Label:  note,
+			Detail: "sibling",
+			Path:   p,
+			Note:   note,
+		})
+	}
+
+	return items
+}
+
+func evalSymlinksOrSelf(path string) string {
+	if p, err := filepath.EvalSymlinks(path); err == nil {
+		return p
+	}
+	return path
+}
+
+// directoryListing returns the markdown files in the directory of target,
+// including target itself, along with the index of target in the list.
+func directoryListing(cwd, target string) ([]overlayItem, int, error) {
+	files, err := siblingMarkdownFiles(target)
+	if err != nil {
+		return nil, -1, err
+	}
+	files = append(files, target)
+	sort.Strings(files)
+
+	items := make([]overlayItem, 0, len(files))
+	cursor := -1
+	for i, p := range files {
+		note := stripAbsolutePath(p, cwd)
+		item := overlayItem{Label: filepath.Base(p), Path: p, Note: note}
+		if p == target {
+			item.Detail = "link target"
+			cursor = i
+		}
+		items = append(items, item)
+	}
+	return items, cursor, nil
+}
@@ -0,0 +1,90 @@
+package ui
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const (
+	// smoothScrollFrames is how many frames a smooth scroll takes.
+	smoothScrollFrames = 8
+
+	// smoothScrollInterval is the time between the frames of a smooth
+	// scroll.
+	smoothScrollInterval = 16 * time.Millisecond
+)
+
+// smoothScroll is a scroll of the viewport under way, a few lines per frame.
+type smoothScroll struct {
+	target     int
+	framesLeft int
+}
+
+// smoothScrollMsg moves a smooth scroll along by a frame. Frames of a scroll
+// that was cancelled or replaced are ignored.
+type smoothScrollMsg struct {
+	scroll *smoothScroll
+}
+
+func smoothScrollFrame(s *smoothScroll) tea.Cmd {
+	return tea.Tick(smoothScrollInterval, func(time.Time) tea.Msg {
+		return smoothScrollMsg{s}
+	})
+}
+
+// scrollTo scrolls the viewport so that a line is at the top, as far as the
+// document allows. With smooth scrolling on, it gets there over a few frames,
+// except in high performance mode, where every frame would be a redraw.
+func (m *pagerModel) scrollTo(line int) tea.Cmd {
+	m.smoothScroll = nil
+
+	// Let the viewport clamp the line.
+	vp := m.viewport
+	vp.SetYOffset(line)
+	line = vp.YOffset
+
+	if !m.common.cfg.SmoothScroll || m.common.cfg.HighPerformancePager || line == m.viewport.YOffset {
+		m.viewport.SetYOffset(line)
+		return nil
+	}
+	m.smoothScroll = &smoothScroll{target: line, framesLeft: smoothScrollFrames}
+	return smoothScrollFrame(m.smoothScroll)
+}
+
+// stepSmoothScroll moves a smooth scroll along by a frame.
+func (m *pagerModel) stepSmoothScroll(msg smoothScrollMsg) tea.Cmd {
+	s := m.smoothScroll
+	if s == nil || msg.scroll != s {
+		return nil
+	}
+	s.framesLeft--
+	if s.framesLeft <= 0 {
+		m.finishSmoothScroll()
+		return nil
+	}
+	// Cover an even share of what's left, at least a line.
+	d := s.target - m.viewport.YOffset
+	step := d / (s.framesLeft + 1)
+	switch {
+	case step == 0 && d > 0:
+		step = 1
+	case step == 0 && d < 0:
+		step = -1
+	}
+	m.viewport.SetYOffset(m.viewport.YOffset + step)
+	if m.viewport.YOffset == s.target {
+		m.smoothScroll = nil
+		return nil
+	}
+	return smoothScrollFrame(s)
+}
+
+// finishSmoothScroll jumps to where a smooth scroll under way is going.
+func (m *pagerModel) finishSmoothScroll() {
+	if m.smoothScroll == nil {
+		return
+	}
+	m.viewport.SetYOffset(m.smoothScroll.target)
+	m.smoothScroll = nil
+}
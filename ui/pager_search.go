@@ -0,0 +1,364 @@
+package ui
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/reflow/ansi"
+)
+
+// Number of queries we remember for the search prompt's history.
+const searchHistoryLimit = 100
+
+// searchMatch is an occurrence of the search query in the rendered output.
+type searchMatch struct {
+	Line int
+
+	// Byte offsets of the match in the rendered output.
+	Start int
+	End   int
+}
+
+func newSearchInput() textinput.Model {
+	si := textinput.New()
+	si.Prompt = "/"
+	si.PromptStyle = lipgloss.NewStyle().Foreground(fuchsia)
+	si.Cursor.Style = lipgloss.NewStyle().Foreground(fuchsia)
+	return si
+}
+
+// searchOptions are the ways a query can be matched, toggled in the search
+// prompt.
+type searchOptions struct {
+	ignoreCase bool
+	regex      bool
+}
+
+// label returns a prefix for queries that shows which options are on.
+func (o searchOptions) label() string {
+	var s string
+	if o.ignoreCase {
+		s += "i:"
+	}
+	if o.regex {
+		s += "re:"
+	}
+	return s
+}
+
+// compileQuery returns a pattern that matches query according to the
+// options.
+func compileQuery(query string, opts searchOptions) (*regexp.Regexp, error) {
+	pattern := query
+	if !opts.regex {
+		pattern = regexp.QuoteMeta(query)
+	}
+	if opts.ignoreCase {
+		pattern = "(?i)" + pattern
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid regular expression: %w", err)
+	}
+	return re, nil
+}
+
+// findMatches returns every occurrence of query in the printable text of the
+// rendered output. Matches don't span lines.
+func findMatches(rendered, query string) []searchMatch {
+	if query == "" {
+		return nil
+	}
+	return findPattern(rendered, regexp.MustCompile(regexp.QuoteMeta(query)))
+}
+
+// findPattern returns every non-empty match of re in the printable text of
+// the rendered output. Matches don't span lines.
+func findPattern(rendered string, re *regexp.Regexp) []searchMatch {
+
+	var (
+		matches   []searchMatch
+		lineStart int
+	)
+	for i, line := range strings.Split(rendered, "\n") {
+		printable, offsets := printableRunesAndOffsets(line)
+		text := string(printable)
+
+		// Map byte offsets in the printable text to rune indices.
+		runeIndex := make(map[int]int, len(printable)+1)
+		b := 0
+		for r, c := range printable {
+			runeIndex[b] = r
+			b += len(string(c))
+		}
+		runeIndex[b] = len(printable)
+
+		for _, loc := range re.FindAllStringIndex(text, -1) {
+			start, end := loc[0], loc[1]
+			if start == end {
+				// There's nothing to highlight.
+				continue
+			}
+			matches = append(matches, searchMatch{
+				Line:  i,
+				Start: lineStart + offsets[runeIndex[start]],
+				End:   lineStart + offsets[runeIndex[end]],
+			})
+		}
+
+		lineStart += len(line) + 1
+	}
+	return matches
+}
+
+// currentMatchSpan sets the current match apart from the other matches,
+// which are in plain reverse video.
+var currentMatchSpan = spanStyle{on: "\x1b[1;7m", off: "\x1b[22;27m"}
+
+// highlightMatches highlights every match in the rendered output, the one at
+// index current more prominently.
+func highlightMatches(rendered string, matches []searchMatch, current int) string {
+	// Work backwards so earlier offsets stay valid.
+	for i := len(matches) - 1; i >= 0; i-- {
+		m := matches[i]
+		if m.Start < 0 || m.End > len(rendered) || m.End <= m.Start {
+			continue
+		}
+		style := reverseSpan
+		if i == current {
+			style = currentMatchSpan
+		}
+		rendered = styleSpan(rendered, m.Start, m.End, style)
+	}
+	return rendered
+}
+
+func (m *pagerModel) startSearch() tea.Cmd {
+	m.searching = true
+	m.searchHistoryPos = len(m.searchHistory)
+	m.searchDraft = ""
+	m.searchInput.Reset()
+	m.updateSearchPrompt()
+	m.searchInput.Focus()
+	return textinput.Blink
+}
+
+// updateSearchPrompt shows the search options in the prompt.
+func (m *pagerModel) updateSearchPrompt() {
+	m.searchInput.Prompt = "/" + m.searchOptions.label()
+	m.searchInput.Width = max(0, m.common.width-ansi.PrintableRuneWidth(m.searchInput.Prompt)-1)
+}
+
+func (m *pagerModel) stopSearch() {
+	m.searching = false
+	m.searchInput.Blur()
+}
+
+// updateSearch handles key presses while the search prompt is open.
+func (m *pagerModel) updateSearch(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case keyEsc:
+		m.stopSearch()
+		return nil
+	case keyEnter:
+		query := m.searchInput.Value()
+		m.stopSearch()
+		if query == "" {
+			return nil
+		}
+		m.addSearchHistory(query)
+		return m.runSearch(query)
+	case "ctrl+t":
+		m.searchOptions.ignoreCase = !m.searchOptions.ignoreCase
+		m.updateSearchPrompt()
+		return nil
+	case "ctrl+r":
+		m.searchOptions.regex = !m.searchOptions.regex
+		m.updateSearchPrompt()
+		return nil
+	case "up", "ctrl+p":
+		m.browseSearchHistory(-1)
+		return nil
+	case "down", "ctrl+n":
+		m.browseSearchHistory(1)
+		return nil
+	}
+
+	var cmd tea.Cmd
+	m.searchInput, cmd = m.searchInput.Update(msg)
+	return cmd
+}
+
+// addSearchHistory records a query, skipping it if it repeats the previous
+// one.
+func (m *pagerModel) addSearchHistory(query string) {
+	if n := len(m.searchHistory); n > 0 && m.searchHistory[n-1] == query {
+		return
+	}
+	m.searchHistory = append(m.searchHistory, query)
+	if len(m.searchHistory) > searchHistoryLimit {
+		m.searchHistory = m.searchHistory[len(m.searchHistory)-searchHistoryLimit:]
+	}
+}
+
+// browseSearchHistory moves through previous queries like a shell does. The
+// query being typed is kept so coming back down restores it.
+func (m *pagerModel) browseSearchHistory(delta int) {
+	pos := max(0, min(len(m.searchHistory), m.searchHistoryPos+delta))
+	if pos == m.searchHistoryPos {
+		return
+	}
+	if m.searchHistoryPos == len(m.searchHistory) {
+		m.searchDraft = m.searchInput.Value()
+	}
+	m.searchHistoryPos = pos
+
+	if pos == len(m.searchHistory) {
+		m.searchInput.SetValue(m.searchDraft)
+	} else {
+		m.searchInput.SetValue(m.searchHistory[pos])
+	}
+	m.searchInput.CursorEnd()
+}
+
+// runSearch highlights every match of query and jumps to the first one at or
+// below the top of the viewport.
+func (m *pagerModel) runSearch(query string) tea.Cmd {
+	re, err := compileQuery(query, m.searchOptions)
+	if err != nil {
+		return m.showStatusMessage(pagerStatusMessage{err.Error(), true})
+	}
+
+	m.searchQuery = m.searchOptions.label() + query
+	m.searchPattern = re
+	m.searchMatches = findPattern(m.rendered, re)
+	m.applyRenderedContent()
+
+	if len(m.searchMatches) == 0 {
+		return m.showStatusMessage(pagerStatusMessage{fmt.Sprintf("No matches for “%s”", m.searchQuery), true})
+	}
+
+	m.searchMatch = 0
+	for i, match := range m.searchMatches {
+		if match.Line >= m.viewport.YOffset {
+			m.searchMatch = i
+			break
+		}
+	}
+	return m.jumpToMatch(m.searchMatch)
+}
+
+// jumpToMatch makes match i the current one and scrolls it to the middle of
+// the viewport, as far as possible.
+func (m *pagerModel) jumpToMatch(i int) tea.Cmd {
+	m.searchMatch = i
+	m.applyRenderedContent()
+	m.viewport.SetYOffset(max(0, m.searchMatches[i].Line-m.viewport.Height/2))
+
+	cmds := []tea.Cmd{
+		m.showStatusMessage(pagerStatusMessage{fmt.Sprintf("Match %d/%d", i+1, len(m.searchMatches)), false}),
+	}
+	if m.common.cfg.HighPerformancePager {
+		cmds = append(cmds, viewport.Sync(m.viewport))
+	}
+	return tea.Batch(cmds...)
+}
+
+// refreshSearch finds the matches of the current query again after the
+// document was re-rendered. The current match stays on the same line of text
+// if that's still around, or else moves to the next match below where it
+// was. The search is cleared if nothing matches anymore.
+func (m *pagerModel) refreshSearch(previous string) tea.Cmd {
+	var (
+		anchor     string
+		oldLine    int
+		occurrence int
+	)
+	if m.searchMatch < len(m.searchMatches) {
+		current := m.searchMatches[m.searchMatch]
+		oldLine = current.Line
+		if lines := strings.Split(previous, "\n"); oldLine < len(lines) {
+			anchor = normalizeSpace(stripANSI(lines[oldLine]))
+		}
+		for i := m.searchMatch - 1; i >= 0 && m.searchMatches[i].Line == oldLine; i-- {
+			occurrence++
+		}
+	}
+
+	query := m.searchQuery
+	m.searchMatches = findPattern(m.rendered, m.searchPattern)
+	if len(m.searchMatches) == 0 {
+		m.clearSearch()
+		return m.showStatusMessage(pagerStatusMessage{fmt.Sprintf("No more matches for “%s”", query), false})
+	}
+	m.searchMatch = stableMatch(m.rendered, m.searchMatches, anchor, oldLine, occurrence)
+	return nil
+}
+
+// stableMatch returns the index of the match on the line reading anchor that
+// is closest to oldLine, picking the same occurrence on the line as before.
+// Without such a line it's the first match at or below oldLine.
+func stableMatch(rendered string, matches []searchMatch, anchor string, oldLine, occurrence int) int {
+	lines := strings.Split(rendered, "\n")
+
+	best, bestDistance := -1, 0
+	for i, match := range matches {
+		if i > 0 && matches[i-1].Line == match.Line {
+			continue
+		}
+		if anchor == "" || normalizeSpace(stripANSI(lines[match.Line])) != anchor {
+			continue
+		}
+		distance := match.Line - oldLine
+		if distance < 0 {
+			distance = -distance
+		}
+		if best < 0 || distance < bestDistance {
+			best, bestDistance = i, distance
+		}
+	}
+	if best >= 0 {
+		last := best
+		for last+1 < len(matches) && matches[last+1].Line == matches[best].Line {
+			last++
+		}
+		return min(best+occurrence, last)
+	}
+
+	for i, match := range matches {
+		if match.Line >= oldLine {
+			return i
+		}
+	}
+	return len(matches) - 1
+}
+
+// nextMatch moves to the next match, or the previous one if dir is negative,
+// wrapping around at the ends.
+func (m *pagerModel) nextMatch(dir int) tea.Cmd {
+	n := len(m.searchMatches)
+	if n == 0 {
+		if m.searchQuery == "" {
+			return m.showStatusMessage(pagerStatusMessage{"Press / to search", false})
+		}
+		return m.showStatusMessage(pagerStatusMessage{fmt.Sprintf("No matches for “%s”", m.searchQuery), true})
+	}
+	return m.jumpToMatch(((m.searchMatch+dir)%n + n) % n)
+}
+
+func (m *pagerModel) clearSearch() {
+	m.searchQuery = ""
+	m.searchPattern = nil
+	m.searchMatches = nil
+	m.searchMatch = 0
+}
+
+func (m pagerModel) searchBarView(b *strings.Builder) {
+	s := m.searchInput.View()
+	fmt.Fprint(b, s+strings.Repeat(" ", max(0, m.common.width-ansi.PrintableRuneWidth(s))))
+}
@@ -0,0 +1,212 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func typeKeys(t *testing.T, m pagerModel, keys ...string) pagerModel {
+	t.Helper()
+	for _, k := range keys {
+		var msg tea.KeyMsg
+		switch k {
+		case keyEnter:
+			msg = tea.KeyMsg{Type: tea.KeyEnter}
+		case keyEsc:
+			msg = tea.KeyMsg{Type: tea.KeyEsc}
+		case keyTab:
+			msg = tea.KeyMsg{Type: tea.KeyTab}
+		case "up":
+			msg = tea.KeyMsg{Type: tea.KeyUp}
+		case "down":
+			msg = tea.KeyMsg{Type: tea.KeyDown}
+		default:
+			msg = tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)}
+		}
+		m, _ = m.update(msg)
+	}
+	return m
+}
+
+func TestFindMatches(t *testing.T) {
+	rendered := "\x1b[1mfoo\x1b[0m bar foo\nnothing\nfo\x1b[31mo\x1b[0m"
+	matches := findMatches(rendered, "foo")
+	if len(matches) != 3 {
+		t.Fatalf("expected 3 matches, got %d", len(matches))
+	}
+
+	wantLines := []int{0, 0, 2}
+	for i, match := range matches {
+		if match.Line != wantLines[i] {
+			t.Errorf("match %d: expected line %d, got %d", i, wantLines[i], match.Line)
+		}
+		if got := stripANSI(rendered[match.Start:match.End]); got != "foo" {
+			t.Errorf("match %d: expected span to contain %q, got %q", i, "foo", got)
+		}
+	}
+}
+
+func TestSearchHistory(t *testing.T) {
+	m := newTestPager(t, Config{}, "README.md", 80)
+	m.rendered = "alpha\nbeta\ngamma"
+
+	m = typeKeys(t, m, "/", "alpha", keyEnter, "/", "beta", keyEnter, "/", "beta", keyEnter)
+	if got := len(m.searchHistory); got != 2 {
+		t.Fatalf("expected repeated queries to be recorded once, got %d entries", got)
+	}
+
+	m = typeKeys(t, m, "/", "gam")
+	for _, step := range []struct {
+		key  string
+		want string
+	}{
+		{"up", "beta"},
+		{"up", "alpha"},
+		{"up", "alpha"},
+		{"down", "beta"},
+		{"down", "gam"},
+	} {
+		m = typeKeys(t, m, step.key)
+		if got := m.searchInput.Value(); got != step.want {
+			t.Fatalf("after %s: expected %q, got %q", step.key, step.want, got)
+		}
+	}
+
+	m = typeKeys(t, m, keyEsc)
+	m.unload()
+	if got := len(m.searchHistory); got != 2 {
+		t.Fatalf("expected history to survive unloading, got %d entries", got)
+	}
+}
+
+func TestRefreshSearch_OnReload(t *testing.T) {
+	m := newTestPager(t, Config{}, "README.md", 80)
+	m, _ = m.update(contentRenderedMsg("intro\nfoo one\ntext\nfoo two\nend"))
+	_ = m.runSearch("foo")
+	_ = m.jumpToMatch(1)
+
+	// A line inserted above moves the current match down a line.
+	m, _ = m.update(reloadMsg{})
+	m, _ = m.update(contentRenderedMsg("new\nintro\nfoo one\ntext\nfoo two\nend"))
+	if len(m.searchMatches) != 2 || m.searchMatch != 1 || m.searchMatches[1].Line != 4 {
+		t.Fatalf("expected to stay on the second match, now on line 4, got match %d of %+v", m.searchMatch, m.searchMatches)
+	}
+
+	// With its line gone, the current match moves to the next one below.
+	m, _ = m.update(contentRenderedMsg("foo zero\nnew\nintro\ntext\nend\nfoo three"))
+	if m.searchMatch != 1 || m.searchMatches[1].Line != 5 {
+		t.Fatalf("expected the match below the old one, got match %d of %+v", m.searchMatch, m.searchMatches)
+	}
+
+	m, _ = m.update(contentRenderedMsg("nothing to see"))
+	if m.searchQuery != "" || len(m.searchMatches) != 0 {
+		t.Fatalf("expected the search to be cleared, got %q with %d matches", m.searchQuery, len(m.searchMatches))
+	}
+	if m.statusMessage != "No more matches for “foo”" {
+		t.Fatalf("unexpected status %q", m.statusMessage)
+	}
+}
+
+func TestNextMatch(t *testing.T) {
+	m := newTestPager(t, Config{}, "README.md", 80)
+	lines := make([]string, 200)
+	for i := range lines {
+		lines[i] = fmt.Sprintf("line %d", i)
+	}
+	for _, i := range []int{10, 100, 190} {
+		lines[i] = "a foo here"
+	}
+	m, _ = m.update(contentRenderedMsg(strings.Join(lines, "\n")))
+	_ = m.runSearch("foo")
+
+	for _, want := range []struct {
+		key  string
+		line int
+		msg  string
+	}{
+		{"n", 100, "Match 2/3"},
+		{"n", 190, "Match 3/3"},
+		{"n", 10, "Match 1/3"},
+		{"N", 190, "Match 3/3"},
+		{"N", 100, "Match 2/3"},
+	} {
+		m = typeKeys(t, m, want.key)
+		if got := m.searchMatches[m.searchMatch].Line; got != want.line || m.statusMessage != want.msg {
+			t.Fatalf("%s: expected %s on line %d, got line %d (%q)", want.key, want.msg, want.line, got, m.statusMessage)
+		}
+	}
+
+	// The current match is in the middle of the viewport and stands out.
+	if want := 100 - m.viewport.Height/2; m.viewport.YOffset != want {
+		t.Fatalf("expected the match to be centered at offset %d, got %d", want, m.viewport.YOffset)
+	}
+	view := m.viewport.View()
+	if strings.Count(view, currentMatchSpan.on) != 1 {
+		t.Fatalf("expected exactly one current match highlight in view:\n%q", view)
+	}
+}
+
+func TestNextMatch_HighPerformanceSync(t *testing.T) {
+	m := newTestPager(t, Config{HighPerformancePager: true}, "README.md", 80)
+	m, _ = m.update(contentRenderedMsg("foo\nbar\nfoo"))
+	_ = m.runSearch("foo")
+
+	cmd := m.nextMatch(1)
+	batch, ok := cmd().(tea.BatchMsg)
+	if !ok {
+		t.Fatalf("expected a batch of commands, got %T", cmd())
+	}
+	if len(batch) != 2 {
+		t.Fatalf("expected a status message and a viewport sync, got %d commands", len(batch))
+	}
+}
+
+func TestSearchOptions(t *testing.T) {
+	m := newTestPager(t, Config{}, "README.md", 80)
+	m, _ = m.update(contentRenderedMsg("Foo and foo\nfooo\nbar"))
+
+	search := func(query string, toggles ...tea.KeyType) {
+		t.Helper()
+		m = typeKeys(t, m, "/")
+		for _, k := range toggles {
+			m, _ = m.update(tea.KeyMsg{Type: k})
+		}
+		m = typeKeys(t, m, query, keyEnter)
+	}
+
+	search("foo")
+	if len(m.searchMatches) != 2 {
+		t.Fatalf("expected a case-sensitive search to find 2 matches, got %d", len(m.searchMatches))
+	}
+
+	search("foo", tea.KeyCtrlT)
+	if len(m.searchMatches) != 3 || m.searchQuery != "i:foo" {
+		t.Fatalf("expected a case-insensitive search to find 3 matches, got %d for %q", len(m.searchMatches), m.searchQuery)
+	}
+
+	// Options stick around for the next search.
+	m = typeKeys(t, m, "/")
+	m, _ = m.update(tea.KeyMsg{Type: tea.KeyCtrlR})
+	if m.searchInput.Prompt != "/i:re:" {
+		t.Fatalf("expected the prompt to show the options, got %q", m.searchInput.Prompt)
+	}
+	m, _ = m.update(tea.KeyMsg{Type: tea.KeyCtrlT})
+	m = typeKeys(t, m, "fo+", keyEnter)
+	if len(m.searchMatches) != 2 || m.searchQuery != "re:fo+" {
+		t.Fatalf("expected a regex search to find 2 matches, got %d for %q", len(m.searchMatches), m.searchQuery)
+	}
+	if got := m.rendered[m.searchMatches[1].Start:m.searchMatches[1].End]; got != "fooo" {
+		t.Fatalf("expected the whole regex match to be highlighted, got %q", got)
+	}
+
+	search("fo(")
+	if !strings.HasPrefix(m.statusMessage, "invalid regular expression") {
+		t.Fatalf("expected an error about the regex, got %q", m.statusMessage)
+	}
+	if m.searchQuery != "re:fo+" {
+		t.Fatalf("expected the previous search to stay active, got %q", m.searchQuery)
+	}
+}
@@ -0,0 +1,197 @@
+package ui
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// lineSelection is a range of lines in the rendered output, from where the
+// selection was started to the cursor.
+type lineSelection struct {
+	anchor int
+	cursor int
+}
+
+// lines returns the first and last selected line.
+func (s lineSelection) lines() (int, int) {
+	return min(s.anchor, s.cursor), max(s.anchor, s.cursor)
+}
+
+func highlightLines(rendered string, first, last int) string {
+	for l := first; l <= last; l++ {
+		rendered = highlightLine(rendered, l)
+	}
+	return rendered
+}
+
+func (m *pagerModel) startSelection() tea.Cmd {
+	line := m.viewport.YOffset
+	m.selection = &lineSelection{anchor: line, cursor: line}
+	m.applyRenderedContent()
+	return m.showStatusMessage(pagerStatusMessage{"Select lines with j/k, c to copy, space to toggle a task", false})
+}
+
+func (m *pagerModel) stopSelection() {
+	m.selection = nil
+	m.applyRenderedContent()
+}
+
+// moveSelection moves the selection cursor, scrolling to keep it in view.
+func (m *pagerModel) moveSelection(delta int) tea.Cmd {
+	last := max(0, m.viewport.TotalLineCount()-1)
+	m.selection.cursor = max(0, min(last, m.selection.cursor+delta))
+	switch c := m.selection.cursor; {
+	case c < m.viewport.YOffset:
+		m.viewport.SetYOffset(c)
+	case c >= m.viewport.YOffset+m.viewport.Height:
+		m.viewport.SetYOffset(c - m.viewport.Height + 1)
+	}
+	m.applyRenderedContent()
+	if m.common.cfg.HighPerformancePager {
+		return viewport.Sync(m.viewport)
+	}
+	return nil
+}
+
+// updateSelection handles key presses while selecting lines.
+func (m *pagerModel) updateSelection(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "j", "down", "ctrl+j":
+		return m.moveSelection(1)
+	case "k", "up", "ctrl+k":
+		return m.moveSelection(-1)
+	case "f", "pgdown":
+		return m.moveSelection(m.viewport.Height)
+	case "b", "pgup":
+		return m.moveSelection(-m.viewport.Height)
+	case " ":
+		return m.toggleTask()
+	case "c":
+		cmd := m.copySelection()
+		m.stopSelection()
+		return cmd
+	case keyEsc, "q", "V":
+		m.stopSelection()
+	}
+	return nil
+}
+
+// copySelection copies the source lines behind the selected lines.
+func (m *pagerModel) copySelection() tea.Cmd {
+	first, last := m.selection.lines()
+	source := m.renderedSourceLines()
+
+	from, to := selectionSourceRange(source, first, last)
+	if from == 0 {
+		return m.showStatusMessage(pagerStatusMessage{"Couldn't find the selected lines in the source", true})
+	}
+
+	lines := strings.Split(m.currentDocument.Body, "\n")
+	text := strings.Join(lines[from-1:to], "\n") + "\n"
+	text = trimTrailingNewlines(text, m.common.cfg.CopyTrailingNewline)
+
+	n := to - from + 1
+	msg := fmt.Sprintf("Copied %d lines", n)
+	if n == 1 {
+		msg = "Copied 1 line"
+	}
+	return m.copyContents(text, msg)
+}
+
+// selectionSourceRange returns the source lines, starting at 1, that the
+// rendered lines first to last were made from, or 0 if they're unknown.
+func selectionSourceRange(source []int, first, last int) (int, int) {
+	first = max(0, first)
+	last = min(len(source)-1, last)
+
+	var from, to int
+	for i := first; i <= last; i++ {
+		if source[i] == 0 {
+			continue
+		}
+		if from == 0 {
+			from = source[i]
+		}
+		to = max(to, source[i])
+	}
+	return from, to
+}
+
+// renderedSourceLines maps each line of the rendered output to the line of
+// the markdown source it came from, starting at 1, or 0 if it's unknown.
+// Code files have line numbers that match the source, so that's just a
+// matter of reading the gutter. Otherwise the text of rendered lines is
+// looked up in the source, top to bottom.
+func renderedSourceLines(markdown, rendered string, gutter bool) []int {
+	lines := strings.Split(rendered, "\n")
+	out := make([]int, len(lines))
+
+	if gutter {
+		prev := 0
+		for i, l := range lines {
+			l = stripANSI(l)
+			if len(l) >= lineNumberWidth {
+				if n, err := strconv.Atoi(strings.TrimSpace(l[:lineNumberWidth])); err == nil {
+					prev = n
+				}
+			}
+			// Continuations of wrapped lines belong to the line above.
+			out[i] = prev
+		}
+		return out
+	}
+
+	// Compare letters and digits only, so markup doesn't get in the way.
+	// Rendered lines are looked for from where the previous one was found
+	// on, so that a line is never attributed to one above it, and a line
+	// that's repeated maps to each of its occurrences in turn. Several
+	// rendered lines can come from the same source line when it's wrapped.
+	const keyLength = 16
+	source := strings.Split(markdown, "\n")
+	keys := make([]string, len(source))
+	for i, s := range source {
+		keys[i] = alnumKey(s)
+	}
+
+	line, col := 0, 0
+	for i, l := range lines {
+		key := alnumKey(stripANSI(l))
+		if key == "" {
+			continue
+		}
+		short := key[:min(len(key), keyLength)]
+		for j := line; j < len(keys); j++ {
+			start := 0
+			if j == line {
+				start = col
+			}
+			k := strings.Index(keys[j][start:], short)
+			if k < 0 {
+				continue
+			}
+			k += start
+			out[i] = j + 1
+			line, col = j, k+len(short)
+			if strings.HasPrefix(keys[j][k:], key) {
+				col = k + len(key)
+			}
+			break
+		}
+	}
+	return out
+}
+
+func alnumKey(s string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(s) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
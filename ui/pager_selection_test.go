@@ -0,0 +1,239 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestCopySelection(t *testing.T) {
+	var copied string
+	stubClipboard(t, func(s string) error {
+		copied = s
+		return nil
+	}, failingClipboard)
+
+	body := "# Title\n\nFirst paragraph with **bold** text.\n\n- item one\n- item [two](two.md)\n\nLast words.\n"
+	m := newTestPager(t, Config{}, "README.md", 80)
+	m.currentDocument.Body = body
+	out, err := glamourRender(m, body)
+	if err != nil {
+		t.Fatalf("glamourRender returned error: %v", err)
+	}
+	m, _ = m.update(contentRenderedMsg(out))
+
+	lineOf := func(text string) int {
+		for i, l := range strings.Split(stripANSI(m.rendered), "\n") {
+			if strings.Contains(l, text) {
+				return i
+			}
+		}
+		t.Fatalf("%q not found in the rendered output", text)
+		return -1
+	}
+
+	m = typeKeys(t, m, "V")
+	if m.selection == nil {
+		t.Fatal("expected a selection to be started")
+	}
+	m.selection.anchor = lineOf("item two")
+	m.selection.cursor = lineOf("item one")
+	m = typeKeys(t, m, "c")
+
+	if want := "- item one\n- item [two](two.md)\n"; copied != want {
+		t.Fatalf("expected %q on the clipboard, got %q", want, copied)
+	}
+	if m.statusMessage != "Copied 2 lines" {
+		t.Fatalf("unexpected status %q", m.statusMessage)
+	}
+	if m.selection != nil {
+		t.Fatal("expected copying to end the selection")
+	}
+
+	// Without a selection the whole document is copied.
+	m = typeKeys(t, m, "c")
+	if copied != body {
+		t.Fatalf("expected the whole document on the clipboard, got %q", copied)
+	}
+}
+
+func TestCopySelection_LineNumbers(t *testing.T) {
+	var copied string
+	stubClipboard(t, func(s string) error {
+		copied = s
+		return nil
+	}, failingClipboard)
+
+	body := "# Title\n\nFirst paragraph.\n\n- item one\n- item two\n\nLast words.\n"
+	m := newTestPager(t, Config{ShowLineNumbers: true, HeadingGutterIndicator: "#"}, "README.md", 80)
+	m.currentDocument.Body = body
+	out, err := glamourRender(m, body)
+	if err != nil {
+		t.Fatalf("glamourRender returned error: %v", err)
+	}
+	m, _ = m.update(contentRenderedMsg(out))
+
+	m = typeKeys(t, m, "V")
+	for i, l := range strings.Split(stripANSI(m.rendered), "\n") {
+		if strings.Contains(l, "item two") {
+			m.selection.anchor, m.selection.cursor = i, i
+		}
+	}
+	m = typeKeys(t, m, "c")
+	if copied != "- item two\n" {
+		t.Fatalf("expected the selected line on the clipboard, got %q (status %q)", copied, m.statusMessage)
+	}
+}
+
+func TestRenderedSourceLines_Gutter(t *testing.T) {
+	rendered := "   1package main\n   2\n   3var x = \"aaaa\n    aaaa\"\n   4func main() {}"
+	got := renderedSourceLines("", rendered, true)
+	want := []int{1, 2, 3, 3, 4}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("expected %v, got %v", want, got)
+		}
+	}
+}
+
+func TestRenderedSourceLines(t *testing.T) {
+	markdown := "# Buy milk list\n\n- [ ] test\n- [ ] test\n- [ ] buy milk\n\nA paragraph that is long enough to be wrapped onto two lines."
+	rendered := "  Buy milk list\n\n  [ ] test\n  [ ] test\n  [ ] buy milk\n\n  A paragraph that is long enough\n  to be wrapped onto two lines."
+	got := renderedSourceLines(markdown, rendered, false)
+	want := []int{1, 0, 3, 4, 5, 0, 7, 7}
+	if !slices.Equal(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+}
+
+func TestToggleTask(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "todo.md")
+	const content = "---\ntitle: Todo\n---\n# Todo\n\n- [ ] Buy milk\n- [x] Walk the dog\n\n```\n- [ ] Not a task\n```\n"
+	mustWriteFile(t, path, content)
+
+	m := newTestPager(t, Config{}, "todo.md", 80)
+	m.currentDocument.localPath = path
+	m.currentDocument.setContent([]byte(content))
+	render := func() {
+		t.Helper()
+		out, err := glamourRender(m, m.currentDocument.Body)
+		if err != nil {
+			t.Fatalf("glamourRender returned error: %v", err)
+		}
+		m, _ = m.update(contentRenderedMsg(out))
+	}
+	lineOf := func(text string) int {
+		for i, l := range strings.Split(stripANSI(m.rendered), "\n") {
+			if strings.Contains(l, text) {
+				return i
+			}
+		}
+		t.Fatalf("%q not found in the rendered output", text)
+		return -1
+	}
+	render()
+
+	m = typeKeys(t, m, "V")
+	m.selection.cursor = lineOf("Buy milk")
+	m, cmd := m.update(tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}})
+	if m.statusMessage != "Checked task" {
+		t.Fatalf("expected the task to be checked, got status %q", m.statusMessage)
+	}
+	if cmd == nil {
+		t.Fatal("expected the document to be rendered again")
+	}
+	want := strings.Replace(content, "- [ ] Buy milk", "- [x] Buy milk", 1)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != want {
+		t.Fatalf("expected the file to be changed to %q, got %q", want, data)
+	}
+	if !strings.Contains(m.currentDocument.Body, "- [x] Buy milk") {
+		t.Fatal("expected the change to show right away")
+	}
+
+	// The watcher sees our own write, which isn't reloaded.
+	m, _ = m.update(reloadMsg{})
+	if m.reloading {
+		t.Fatal("expected our own write not to be reloaded")
+	}
+
+	render()
+	m.selection.cursor = lineOf("Walk the dog")
+	m, _ = m.update(tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}})
+	if m.statusMessage != "Unchecked task" {
+		t.Fatalf("expected the task to be unchecked, got status %q", m.statusMessage)
+	}
+
+	for _, text := range []string{"Not a task", "Todo"} {
+		m.selection.cursor = lineOf(text)
+		m, _ = m.update(tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}})
+		if m.statusMessage != "Not a task item" {
+			t.Fatalf("expected %q not to be toggled, got status %q", text, m.statusMessage)
+		}
+	}
+
+	// Changes made elsewhere are reloaded as usual, and the file isn't
+	// changed if the task's line doesn't match anymore.
+	mustWriteFile(t, path, "# Todo\n\n- [ ] Something else\n")
+	m, _ = m.update(reloadMsg{})
+	if !m.reloading {
+		t.Fatal("expected a change made elsewhere to be reloaded")
+	}
+	m.selection.cursor = lineOf("Buy milk")
+	m, _ = m.update(tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}})
+	if !strings.HasPrefix(m.statusMessage, "Couldn't toggle task") {
+		t.Fatalf("expected a stale document not to be written, got status %q", m.statusMessage)
+	}
+}
+
+func TestToggleTask_SameText(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "todo.md")
+	const content = "# Buy milk list\n\n- [ ] test\n- [ ] test\n- [ ] buy milk\n"
+	mustWriteFile(t, path, content)
+
+	m := newTestPager(t, Config{ShowLineNumbers: true}, "todo.md", 80)
+	m.currentDocument.localPath = path
+	m.currentDocument.setContent([]byte(content))
+	out, err := glamourRender(m, m.currentDocument.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, _ = m.update(contentRenderedMsg(out))
+	m = typeKeys(t, m, "V")
+
+	toggle := func(text string, nth int, want string) {
+		t.Helper()
+		m.selection.cursor = -1
+		for i, l := range strings.Split(stripANSI(m.rendered), "\n") {
+			if strings.HasSuffix(strings.TrimRight(l, " "), "] "+text) {
+				if nth == 0 {
+					m.selection.cursor = i
+					break
+				}
+				nth--
+			}
+		}
+		m, _ = m.update(tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}})
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != want {
+			t.Fatalf("expected %q, got %q (status %q)", want, data, m.statusMessage)
+		}
+	}
+
+	// The second of two identical tasks is the one toggled.
+	toggle("test", 1, "# Buy milk list\n\n- [ ] test\n- [x] test\n- [ ] buy milk\n")
+	// A task is found even if its text is in a heading above it.
+	toggle("buy milk", 0, "# Buy milk list\n\n- [ ] test\n- [x] test\n- [x] buy milk\n")
+}
@@ -0,0 +1,164 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/glow/v2/utils"
+	"github.com/charmbracelet/log"
+)
+
+const defaultSlideSeparator = "---"
+
+type slidesRenderedMsg []string
+
+// splitSlides splits a markdown document into slides on lines consisting
+// solely of the separator, like "---" or a form feed. Separators inside
+// fenced code blocks and front matter are ignored, and so are dashes and
+// equals signs that underline a heading.
+func splitSlides(markdown, separator string) []string {
+	// Only spaces and tabs are trimmed, so that separators can be other
+	// whitespace, like a form feed.
+	const blank = " \t\r"
+	separator = strings.Trim(separator, blank)
+	if separator == "" {
+		separator = defaultSlideSeparator
+	}
+	underline := strings.Trim(separator, "-=") == ""
+	_, body := utils.SplitFrontmatter([]byte(markdown))
+
+	var (
+		slides  []string
+		current []string
+		inFence bool
+		prev    string
+	)
+	for _, line := range strings.Split(string(body), "\n") {
+		trimmed := strings.Trim(line, blank)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			inFence = !inFence
+		}
+		// A line of dashes right below a line of text, other than a
+		// heading, makes it a setext heading.
+		p := strings.TrimSpace(prev)
+		setext := underline && p != "" && !strings.HasPrefix(p, "#")
+		prev = line
+		if !inFence && !setext && trimmed == separator {
+			slides = append(slides, strings.Join(current, "\n"))
+			current = nil
+			continue
+		}
+		current = append(current, line)
+	}
+	slides = append(slides, strings.Join(current, "\n"))
+
+	// Drop empty slides, such as the ones caused by a leading separator.
+	out := slides[:0]
+	for _, s := range slides {
+		if strings.TrimSpace(s) != "" {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// centerVertically pads s with blank lines so that it sits in the middle of
+// a viewport of the given height.
+func centerVertically(s string, height int) string {
+	s = strings.Trim(s, "\n")
+	lines := strings.Count(s, "\n") + 1
+	if lines >= height {
+		return s
+	}
+	return strings.Repeat("\n", (height-lines)/2) + s
+}
+
+func (m *pagerModel) togglePresentation() tea.Cmd {
+	if m.presenting {
+		m.stopPresentation()
+		return nil
+	}
+
+	sources := splitSlides(m.renderedMarkdown(m.currentDocument.Body), m.common.cfg.SlideSeparator)
+	if len(sources) == 0 {
+		return m.showStatusMessage(pagerStatusMessage{"Nothing to present", false})
+	}
+
+	m.presenting = true
+	m.slide = 0
+	m.slides = nil
+	m.presentationYOffset = m.viewport.YOffset
+	return renderSlides(*m, sources)
+}
+
+func (m *pagerModel) stopPresentation() {
+	m.presenting = false
+	m.slides = nil
+	m.slide = 0
+	m.applyRenderedContent()
+	m.viewport.SetYOffset(m.presentationYOffset)
+}
+
+func (m *pagerModel) showSlide(i int) tea.Cmd {
+	if len(m.slides) == 0 {
+		return nil
+	}
+	m.slide = max(0, min(len(m.slides)-1, i))
+	m.setContent(centerVertically(m.slides[m.slide], m.viewport.Height))
+	m.viewport.GotoTop()
+	if m.common.cfg.HighPerformancePager {
+		return viewport.Sync(m.viewport)
+	}
+	return nil
+}
+
+// updatePresentation handles key presses while presenting. Keys that don't
+// change slides scroll within the current slide.
+func (m *pagerModel) updatePresentation(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "right", "l", "n", "pgdown", " ":
+		return m.showSlide(m.slide + 1)
+	case "left", "h", "p", "pgup":
+		return m.showSlide(m.slide - 1)
+	case "home", "g":
+		return m.showSlide(0)
+	case "end", "G":
+		return m.showSlide(len(m.slides) - 1)
+	case keyEsc, "q", "P":
+		m.stopPresentation()
+		if m.common.cfg.HighPerformancePager {
+			return viewport.Sync(m.viewport)
+		}
+		return nil
+	}
+
+	var cmd tea.Cmd
+	m.viewport, cmd = m.viewport.Update(msg)
+	return cmd
+}
+
+func (m pagerModel) slideIndicator() string {
+	if len(m.slides) == 0 {
+		return "slide -/-"
+	}
+	return fmt.Sprintf("slide %d/%d", m.slide+1, len(m.slides))
+}
+
+// COMMANDS
+
+func renderSlides(m pagerModel, sources []string) tea.Cmd {
+	return func() tea.Msg {
+		slides := make([]string, 0, len(sources))
+		for _, src := range sources {
+			s, err := glamourRender(m, src)
+			if err != nil {
+				log.Error("error rendering slide", "error", err)
+				return errMsg{err}
+			}
+			slides = append(slides, s)
+		}
+		return slidesRenderedMsg(slides)
+	}
+}
@@ -0,0 +1,41 @@
+package ui
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestSplitSlides(t *testing.T) {
+	for _, tc := range []struct {
+		name, markdown, separator string
+		want                      []string
+	}{
+		{
+			name:     "dashes",
+			markdown: "# One\n\n---\n\n# Two\n\n```\n---\n```\n",
+			want:     []string{"# One\n", "\n# Two\n\n```\n---\n```\n"},
+		},
+		{
+			name:      "form feed",
+			markdown:  "# One\n\f\n# Two\n",
+			separator: "\f",
+			want:      []string{"# One", "# Two\n"},
+		},
+		{
+			name:     "setext heading",
+			markdown: "Title\n---\n\nText\n\n---\n\nMore",
+			want:     []string{"Title\n---\n\nText\n", "\nMore"},
+		},
+		{
+			name:     "front matter",
+			markdown: "---\ntitle: Talk\n---\n# One\n---\n# Two",
+			want:     []string{"# One", "# Two"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := splitSlides(tc.markdown, tc.separator); !slices.Equal(got, tc.want) {
+				t.Fatalf("expected %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,161 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/glow/v2/utils"
+	"github.com/dustin/go-humanize"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/text"
+)
+
+const (
+	// Reading speed the reading time in the status bar assumes.
+	wordsPerMinute = 200
+
+	// Narrowest the note in the status bar gets before the reading time is
+	// left out.
+	readingTimeMinNoteWidth = 24
+)
+
+// documentStats are numbers about a markdown document.
+type documentStats struct {
+	Words      int
+	Lines      int
+	Characters int
+	Headings   int
+	Links      int
+	Followable int
+	External   int
+	CodeBlocks int
+}
+
+// computeStats counts the words, lines, headings, links and so on of a
+// markdown document. followable is the number of links we can follow.
+func computeStats(markdown string, followable int) documentStats {
+	s := documentStats{
+		Words:      countWords(markdown),
+		Characters: utf8.RuneCountInString(markdown),
+		Headings:   len(extractHeadings(markdown)),
+		Followable: followable,
+		CodeBlocks: len(extractCodeBlocks(markdown)),
+	}
+	if markdown != "" {
+		s.Lines = strings.Count(strings.TrimSuffix(markdown, "\n"), "\n") + 1
+	}
+	links := extractRawLinks(markdown)
+	s.Links = len(links)
+	for _, l := range links {
+		if isExternalHref(l.href) {
+			s.External++
+		}
+	}
+	return s
+}
+
+// countWords counts the whitespace separated words of a markdown document,
+// leaving out markup like # and ``` that doesn't contain any letters or
+// digits.
+func countWords(markdown string) int {
+	n := 0
+	for _, w := range strings.Fields(markdown) {
+		if strings.IndexFunc(w, func(r rune) bool { return unicode.IsLetter(r) || unicode.IsDigit(r) }) >= 0 {
+			n++
+		}
+	}
+	return n
+}
+
+// countProseWords counts the words of a markdown document like countWords,
+// leaving out the contents of code blocks.
+func countProseWords(markdown string) int {
+	source := []byte(markdown)
+	doc := goldmark.New().Parser().Parse(text.NewReader(source))
+
+	blank := func(seg text.Segment) {
+		for j := seg.Start; j < seg.Stop; j++ {
+			if source[j] != '\n' {
+				source[j] = ' '
+			}
+		}
+	}
+	_ = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		switch n.(type) {
+		case *ast.FencedCodeBlock, *ast.CodeBlock:
+			// The language of a fenced code block isn't a word either.
+			if f, ok := n.(*ast.FencedCodeBlock); ok && f.Info != nil {
+				blank(f.Info.Segment)
+			}
+			lines := n.Lines()
+			for i := 0; i < lines.Len(); i++ {
+				blank(lines.At(i))
+			}
+			return ast.WalkSkipChildren, nil
+		}
+		return ast.WalkContinue, nil
+	})
+	return countWords(string(source))
+}
+
+// readingTimeView returns the word count and reading time of the document
+// for the status bar, or "" if they're not shown.
+func (m pagerModel) readingTimeView() string {
+	if !m.showReadingTime || m.words == 0 {
+		return ""
+	}
+	minutes := max(1, (m.words+wordsPerMinute-1)/wordsPerMinute)
+	return fmt.Sprintf(" %s words · %d min", humanize.Comma(int64(m.words)), minutes)
+}
+
+// countDocumentWords counts the words of the document for the reading time,
+// if it's a markdown document.
+func (m *pagerModel) countDocumentWords() {
+	m.words = 0
+	switch {
+	case !utils.IsMarkdownFile(m.currentDocument.Note) || m.currentDocument.raw:
+	case m.common.cfg.ReadingTimeSkipCode:
+		m.words = countProseWords(m.renderedMarkdown(m.currentDocument.Body))
+	default:
+		m.words = countWords(m.renderedMarkdown(m.currentDocument.Body))
+	}
+}
+
+func (m *pagerModel) toggleReadingTime() tea.Cmd {
+	if m.words == 0 {
+		return m.showStatusMessage(pagerStatusMessage{"No words to count", false})
+	}
+	m.showReadingTime = !m.showReadingTime
+	msg := "Reading time shown"
+	if !m.showReadingTime {
+		msg = "Reading time hidden"
+	}
+	return m.showStatusMessage(pagerStatusMessage{msg, false})
+}
+
+func isExternalHref(href string) bool {
+	return strings.Contains(href, "://") || strings.HasPrefix(strings.ToLower(href), "mailto:")
+}
+
+func statsOverlayItems(s documentStats) []overlayItem {
+	row := func(label string, n int) overlayItem {
+		return overlayItem{Label: fmt.Sprintf("%-12s %8s", label, humanize.Comma(int64(n)))}
+	}
+	return []overlayItem{
+		row("Words", s.Words),
+		row("Lines", s.Lines),
+		row("Characters", s.Characters),
+		row("Headings", s.Headings),
+		row("Links", s.Links),
+		{Label: fmt.Sprintf("  %-10s %8s", "followable", humanize.Comma(int64(s.Followable)))},
+		{Label: fmt.Sprintf("  %-10s %8s", "external", humanize.Comma(int64(s.External)))},
+		row("Code blocks", s.CodeBlocks),
+	}
+}
@@ -0,0 +1,274 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	east "github.com/yuin/goldmark/extension/ast"
+	"github.com/yuin/goldmark/text"
+)
+
+// Columns scrolled sideways per key press in the table overlay.
+const tableScrollStep = 8
+
+var tableHeaderStyle = lipgloss.NewStyle().Bold(true).Render
+
+// table is a markdown table and its position in the rendered output.
+type table struct {
+	Header     []string
+	Rows       [][]string
+	Alignments []east.Alignment
+
+	// First and last line in the rendered output, or -1 if the table
+	// couldn't be located.
+	Line    int
+	EndLine int
+}
+
+// extractTables returns the tables of a markdown document in document
+// order.
+func extractTables(markdown string) []table {
+	source := []byte(markdown)
+	doc := goldmark.New(goldmark.WithExtensions(extension.Table)).
+		Parser().Parse(text.NewReader(source))
+
+	var out []table
+	_ = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		t, ok := n.(*east.Table)
+		if !ok {
+			return ast.WalkContinue, nil
+		}
+
+		tbl := table{Alignments: t.Alignments, Line: -1, EndLine: -1}
+		for row := t.FirstChild(); row != nil; row = row.NextSibling() {
+			var cells []string
+			for cell := row.FirstChild(); cell != nil; cell = cell.NextSibling() {
+				cells = append(cells, normalizeSpace(nodeText(cell, source)))
+			}
+			if _, header := row.(*east.TableHeader); header {
+				tbl.Header = cells
+			} else {
+				tbl.Rows = append(tbl.Rows, cells)
+			}
+		}
+		out = append(out, tbl)
+		return ast.WalkSkipChildren, nil
+	})
+	return out
+}
+
+// locateTables sets the rendered lines of each table by looking for its
+// header and last row in the rendered output, top to bottom.
+func locateTables(rendered string, tables []table) {
+	lines := strings.Split(stripANSI(rendered), "\n")
+
+	from := 0
+	for i := range tables {
+		t := &tables[i]
+		t.Line, t.EndLine = -1, -1
+
+		t.Line = findCells(lines, from, t.Header)
+		if t.Line < 0 {
+			continue
+		}
+		t.EndLine = t.Line
+		if len(t.Rows) > 0 {
+			if l := findCells(lines, t.Line+1, t.Rows[len(t.Rows)-1]); l >= 0 {
+				t.EndLine = l
+			}
+		}
+		from = t.EndLine + 1
+	}
+}
+
+// findCells returns the first line from the given one on that contains the
+// non-empty cells of a row in order, or -1.
+func findCells(lines []string, from int, cells []string) int {
+	for l := from; l < len(lines); l++ {
+		line := normalizeSpace(lines[l])
+		found, pos := true, 0
+		for _, c := range cells {
+			if c == "" {
+				continue
+			}
+			i := strings.Index(line[pos:], c)
+			if i < 0 {
+				found = false
+				break
+			}
+			pos += i + len(c)
+		}
+		if found {
+			return l
+		}
+	}
+	return -1
+}
+
+// tableAt returns the table the given rendered line is in, or else the first
+// one starting within height lines below it, or -1.
+func tableAt(tables []table, line, height int) int {
+	for i, t := range tables {
+		if t.Line >= 0 && t.Line <= line && line <= t.EndLine {
+			return i
+		}
+	}
+	for i, t := range tables {
+		if t.Line > line && t.Line < line+height {
+			return i
+		}
+	}
+	return -1
+}
+
+// tableOverlay shows a table unwrapped, scrolling in both directions, with
+// the header row kept in place.
+type tableOverlay struct {
+	title  string
+	header []string
+	rows   []string
+	width  int // of the widest line
+
+	x, y int
+}
+
+func newTableOverlay(title string, t table) *tableOverlay {
+	columns := len(t.Header)
+	for _, r := range t.Rows {
+		columns = max(columns, len(r))
+	}
+	widths := make([]int, columns)
+	for _, r := range append([][]string{t.Header}, t.Rows...) {
+		for i, c := range r {
+			widths[i] = max(widths[i], runewidth.StringWidth(c))
+		}
+	}
+
+	formatRow := func(cells []string) string {
+		parts := make([]string, columns)
+		for i := range columns {
+			c := ""
+			if i < len(cells) {
+				c = cells[i]
+			}
+			align := east.AlignNone
+			if i < len(t.Alignments) {
+				align = t.Alignments[i]
+			}
+			parts[i] = alignCell(c, widths[i], align)
+		}
+		return " " + strings.Join(parts, " │ ")
+	}
+
+	rules := make([]string, columns)
+	for i, w := range widths {
+		rules[i] = strings.Repeat("─", w)
+	}
+
+	o := &tableOverlay{
+		title:  title,
+		header: []string{formatRow(t.Header), "─" + strings.Join(rules, "─┼─") + "─"},
+	}
+	for _, r := range t.Rows {
+		o.rows = append(o.rows, formatRow(r))
+	}
+	for _, l := range append(o.header, o.rows...) {
+		o.width = max(o.width, runewidth.StringWidth(l))
+	}
+	return o
+}
+
+func alignCell(s string, width int, align east.Alignment) string {
+	pad := width - runewidth.StringWidth(s)
+	switch align {
+	case east.AlignRight:
+		return strings.Repeat(" ", pad) + s
+	case east.AlignCenter:
+		return strings.Repeat(" ", pad/2) + s + strings.Repeat(" ", pad-pad/2)
+	default:
+		return s + strings.Repeat(" ", pad)
+	}
+}
+
+// Lines taken by the title, a gap and the header.
+const tableOverlayChrome = 4
+
+// scroll moves the visible part of the table, keeping it within bounds for
+// an overlay of the given size.
+func (o *tableOverlay) scroll(dx, dy, width, height int) {
+	maxX := max(0, o.width-width)
+	maxY := max(0, len(o.rows)-(height-tableOverlayChrome))
+	o.x = max(0, min(maxX, o.x+dx))
+	o.y = max(0, min(maxY, o.y+dy))
+}
+
+// view renders the overlay so that it fills exactly width x height cells.
+func (o tableOverlay) view(width, height int) string {
+	title := o.title
+	if o.width > width {
+		title += fmt.Sprintf(" (columns %d-%d of %d)", o.x+1, min(o.width, o.x+width), o.width)
+	}
+	lines := []string{" " + overlayTitleStyle(title), ""}
+
+	cut := func(s string) string {
+		return runewidth.Truncate(runewidth.TruncateLeft(s, o.x, ""), width, "")
+	}
+	lines = append(lines, tableHeaderStyle(cut(o.header[0])), overlayDetailStyle(cut(o.header[1])))
+	for i := o.y; i < len(o.rows) && len(lines) < height; i++ {
+		lines = append(lines, cut(o.rows[i]))
+	}
+
+	for len(lines) < height {
+		lines = append(lines, "")
+	}
+	return strings.Join(lines[:max(0, height)], "\n")
+}
+
+// openTable shows the table at the top of the viewport in an overlay.
+func (m *pagerModel) openTable() tea.Cmd {
+	i := tableAt(m.tables, m.viewport.YOffset, m.viewport.Height)
+	if i < 0 {
+		return m.showStatusMessage(pagerStatusMessage{"No table here", false})
+	}
+	m.table = newTableOverlay(fmt.Sprintf("Table %d/%d", i+1, len(m.tables)), m.tables[i])
+	return nil
+}
+
+// updateTable handles key presses while a table is shown.
+func (m *pagerModel) updateTable(msg tea.KeyMsg) tea.Cmd {
+	w, h := m.viewport.Width, m.viewport.Height
+	switch msg.String() {
+	case "k", "up", "ctrl+k":
+		m.table.scroll(0, -1, w, h)
+	case "j", "down", "ctrl+j":
+		m.table.scroll(0, 1, w, h)
+	case "b", "pgup":
+		m.table.scroll(0, -(h - tableOverlayChrome), w, h)
+	case "f", "pgdown", " ":
+		m.table.scroll(0, h-tableOverlayChrome, w, h)
+	case "g", "home":
+		m.table.scroll(0, -len(m.table.rows), w, h)
+	case "G", "end":
+		m.table.scroll(0, len(m.table.rows), w, h)
+	case "h", "left":
+		m.table.scroll(-tableScrollStep, 0, w, h)
+	case "l", "right":
+		m.table.scroll(tableScrollStep, 0, w, h)
+	case "0":
+		m.table.scroll(-m.table.width, 0, w, h)
+	case "$":
+		m.table.scroll(m.table.width, 0, w, h)
+	case "q", keyEsc, "|":
+		m.table = nil
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTableOverlay(t *testing.T) {
+	wide := strings.Repeat("w", 150)
+	body := "# Title\n\nIntro.\n\n" +
+		"| Name | Value | Notes |\n| :--- | ---: | --- |\n| a | 1 | " + wide + " |\n| bb | 22 | short |\n\n" +
+		strings.Repeat("Outro.\n\n", 50)
+
+	tables := extractTables(body)
+	if len(tables) != 1 {
+		t.Fatalf("expected one table, got %d", len(tables))
+	}
+	if got := strings.Join(tables[0].Header, "|"); got != "Name|Value|Notes" {
+		t.Fatalf("unexpected header %q", got)
+	}
+	if len(tables[0].Rows) != 2 || tables[0].Rows[1][0] != "bb" {
+		t.Fatalf("unexpected rows %q", tables[0].Rows)
+	}
+
+	m := newTestPager(t, Config{}, "README.md", 80)
+	m.currentDocument.Body = body
+	rendered, err := glamourRender(m, body)
+	if err != nil {
+		t.Fatalf("glamourRender returned error: %v", err)
+	}
+	m, _ = m.update(contentRenderedMsg(rendered))
+	if tbl := m.tables[0]; tbl.Line < 0 || tbl.EndLine <= tbl.Line {
+		t.Fatalf("expected the table to be located, got lines %d-%d", tbl.Line, tbl.EndLine)
+	}
+
+	m = typeKeys(t, m, "|")
+	if m.table == nil {
+		t.Fatal("expected the table in view to open")
+	}
+	lines := strings.Split(stripANSI(m.View()), "\n")
+	if !strings.Contains(lines[2], "Name") || !strings.Contains(lines[2], "Value") {
+		t.Fatalf("expected the header row, got %q", lines[2])
+	}
+	if !strings.Contains(lines[5], "bb   │    22 │") {
+		t.Fatalf("expected right aligned values, got %q", lines[5])
+	}
+
+	// Scrolling sideways keeps the header in place above the rows.
+	m = typeKeys(t, m, "$")
+	lines = strings.Split(stripANSI(m.View()), "\n")
+	if m.table.x == 0 || !strings.HasSuffix(strings.TrimRight(lines[4], " "), "w") {
+		t.Fatalf("expected to scroll to the end of the widest row, got %q", lines[4])
+	}
+	if strings.Contains(lines[2], "Name") || !strings.Contains(lines[3], "─") {
+		t.Fatalf("expected the header to scroll along, got %q", lines[2:4])
+	}
+	m = typeKeys(t, m, "0")
+	if m.table.x != 0 {
+		t.Fatalf("expected 0 to scroll back to the start, got %d", m.table.x)
+	}
+
+	m = typeKeys(t, m, keyEsc)
+	if m.table != nil {
+		t.Fatal("expected escape to close the table")
+	}
+
+	m.viewport.GotoBottom()
+	m = typeKeys(t, m, "|")
+	if m.table != nil || m.statusMessage != "No table here" {
+		t.Fatalf("expected no table past the end, got %q", m.statusMessage)
+	}
+}
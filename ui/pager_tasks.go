@@ -0,0 +1,208 @@
+package ui
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/glow/v2/utils"
+	"github.com/charmbracelet/log"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	east "github.com/yuin/goldmark/extension/ast"
+	"github.com/yuin/goldmark/text"
+)
+
+// taskItemPattern matches the start of a task list item up to its checkbox,
+// like "- [ ]" or "1. [x]", possibly in a block quote. The second group is
+// the mark in the checkbox.
+var taskItemPattern = regexp.MustCompile(`^(\s*(?:>\s*)*(?:[-*+]|\d+[.)])\s+\[)([ xX])\]`)
+
+// errTaskChanged is returned when the line of a task in the file isn't the
+// one we have, because the file changed since we read it.
+var errTaskChanged = errors.New("document changed on disk")
+
+// errTaskUnknown is returned when it isn't clear which task a checkbox in the
+// rendered output belongs to.
+var errTaskUnknown = errors.New("can't tell which task this is")
+
+// taskLines returns the lines of a markdown document, starting at 1, that
+// start a task list item, in document order. Checkboxes in code and the like
+// aren't tasks.
+func taskLines(markdown string) []int {
+	source := []byte(markdown)
+	doc := goldmark.New(goldmark.WithExtensions(extension.TaskList)).
+		Parser().Parse(text.NewReader(source))
+
+	var lines []int
+	_ = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		if _, ok := n.(*east.TaskCheckBox); !ok {
+			return ast.WalkContinue, nil
+		}
+		if block := n.Parent(); block != nil && block.Lines().Len() > 0 {
+			start := block.Lines().At(0).Start
+			lines = append(lines, bytes.Count(source[:start], []byte("\n"))+1)
+		}
+		return ast.WalkSkipChildren, nil
+	})
+	return lines
+}
+
+// renderedCheckboxLines returns the lines of the rendered output, without a
+// gutter of the given width, that start with one of the checkboxes of the
+// style, in order. Block quote bars in front of them are skipped.
+func renderedCheckboxLines(rendered string, gutter int, checkboxes []string) []int {
+	var out []int
+	for i, l := range strings.Split(stripANSI(rendered), "\n") {
+		l = stripGutter(l, gutter)
+		l = strings.TrimLeft(l, " │")
+		for _, c := range checkboxes {
+			if c != "" && strings.HasPrefix(l, c) {
+				out = append(out, i)
+				break
+			}
+		}
+	}
+	return out
+}
+
+// taskSourceLine returns the source line, starting at 1, of the task whose
+// checkbox is on a rendered line, or 0 if there's none. Checkboxes are
+// matched to tasks by their order, which is only done if there are as many
+// of each, so that the wrong line of the file is never changed.
+func (m pagerModel) taskSourceLine(line int) (int, error) {
+	tasks := taskLines(m.currentDocument.Body)
+	if len(tasks) == 0 {
+		return 0, nil
+	}
+
+	checkboxes := []string{"[✓] ", "[x] ", "[ ] "}
+	if sc, err := utils.GlamourStyleConfig(styleName(m.common.cfg)); err == nil && sc.Task.Ticked+sc.Task.Unticked != "" {
+		checkboxes = []string{sc.Task.Ticked, sc.Task.Unticked}
+	}
+	rendered := renderedCheckboxLines(m.rendered, m.gutterWidth(), checkboxes)
+	i := slices.Index(rendered, line)
+	if i < 0 {
+		return 0, nil
+	}
+	if len(rendered) != len(tasks) {
+		return 0, errTaskUnknown
+	}
+	return tasks[i], nil
+}
+
+// toggleCheckbox checks the checkbox of a task list item line, or unchecks
+// it if it's checked already. It reports whether the task is done now.
+func toggleCheckbox(line string) (string, bool, bool) {
+	loc := taskItemPattern.FindStringSubmatchIndex(line)
+	if loc == nil {
+		return line, false, false
+	}
+	mark, done := "x", true
+	if line[loc[4]:loc[5]] != " " {
+		mark, done = " ", false
+	}
+	return line[:loc[4]] + mark + line[loc[5]:], done, true
+}
+
+// toggleTaskInFile toggles the task on the given line of the body of a
+// markdown file, starting at 1, given the contents of the file. The line
+// must still be the one we expect, so that we don't change the wrong line
+// of a file that was changed in the meantime.
+func toggleTaskInFile(data []byte, line int, expected string) ([]byte, error) {
+	_, body := utils.SplitFrontmatter(data)
+	offset := bytes.Count(data[:len(data)-len(body)], []byte("\n"))
+
+	lines := bytes.Split(data, []byte("\n"))
+	i := offset + line - 1
+	if i < 0 || i >= len(lines) {
+		return nil, errTaskChanged
+	}
+	l := string(lines[i])
+	cr := strings.HasSuffix(l, "\r")
+	if strings.TrimSuffix(l, "\r") != strings.TrimSuffix(expected, "\r") {
+		return nil, errTaskChanged
+	}
+
+	toggled, _, ok := toggleCheckbox(strings.TrimSuffix(l, "\r"))
+	if !ok {
+		return nil, errTaskChanged
+	}
+	if cr {
+		toggled += "\r"
+	}
+	lines[i] = []byte(toggled)
+	return bytes.Join(lines, []byte("\n")), nil
+}
+
+// toggleTask checks or unchecks the task on the line of the selection
+// cursor, writing the change to the file and showing it right away.
+func (m *pagerModel) toggleTask() tea.Cmd {
+	doc := &m.currentDocument
+	if doc.localPath == "" || !utils.IsMarkdownFile(doc.Note) || doc.raw {
+		return m.showStatusMessage(pagerStatusMessage{"Not a task item", false})
+	}
+
+	line, err := m.taskSourceLine(m.selection.cursor)
+	if err != nil {
+		return m.showStatusMessage(pagerStatusMessage{"Couldn't toggle task: " + err.Error(), true})
+	}
+	if line == 0 {
+		return m.showStatusMessage(pagerStatusMessage{"Not a task item", false})
+	}
+
+	bodyLines := strings.Split(doc.Body, "\n")
+	toggled, done, ok := toggleCheckbox(bodyLines[line-1])
+	if !ok {
+		return m.showStatusMessage(pagerStatusMessage{"Not a task item", false})
+	}
+
+	data, err := os.ReadFile(doc.localPath)
+	if err == nil {
+		data, err = toggleTaskInFile(data, line, bodyLines[line-1])
+	}
+	if err == nil {
+		err = os.WriteFile(doc.localPath, data, 0o600)
+	}
+	if err != nil {
+		log.Debug("error toggling task", "path", doc.localPath, "error", err)
+		return m.showStatusMessage(pagerStatusMessage{"Couldn't toggle task: " + err.Error(), true})
+	}
+
+	// The watcher will see our own write, which there's no need to reload
+	// the document for.
+	m.ownWrite = string(data)
+	bodyLines[line-1] = toggled
+	doc.Body = strings.Join(bodyLines, "\n")
+
+	msg := "Unchecked task"
+	if done {
+		msg = "Checked task"
+	}
+	return tea.Batch(
+		m.showStatusMessage(pagerStatusMessage{msg, false}),
+		renderWithGlamour(*m, doc.Body),
+	)
+}
+
+// isOwnWrite reports whether the file of the document is as we last wrote
+// it, in which case a change to it needn't be reloaded.
+func (m *pagerModel) isOwnWrite() bool {
+	if m.ownWrite == "" {
+		return false
+	}
+	data, err := os.ReadFile(m.currentDocument.localPath)
+	if err != nil || string(data) != m.ownWrite {
+		m.ownWrite = ""
+		return false
+	}
+	return true
+}
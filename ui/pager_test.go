@@ -0,0 +1,1794 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/reflow/ansi"
+)
+
+// newTestPager returns a pager for rendering tests with glamour enabled.
+func newTestPager(t *testing.T, cfg Config, note string, width int) pagerModel {
+	t.Helper()
+
+	orig := config
+	config = Config{GlamourEnabled: true}
+	t.Cleanup(func() { config = orig })
+
+	if cfg.GlamourStyle == "" {
+		cfg.GlamourStyle = "dark"
+	}
+	// Tests don't run in a terminal, so there's no color to detect.
+	if cfg.ColorDepth == "" {
+		cfg.ColorDepth = colorDepthTrueColor
+	}
+	common := &commonModel{cfg: cfg, width: width, height: 40}
+	m := newPagerModel(common)
+	m.setSize(width, 40)
+	m.currentDocument = markdown{Note: note}
+	return m
+}
+
+func TestGlamourRender_CodeWrapWidth(t *testing.T) {
+	const wrapAt = 40
+	m := newTestPager(t, Config{CodeWrapWidth: wrapAt}, "main.go", 300)
+
+	long := "var x = \"" + strings.Repeat("a", 100) + "\"\n"
+	src := "package main\n\n" + long + "func main() {}\n"
+
+	out, err := glamourRender(m, src)
+	if err != nil {
+		t.Fatalf("glamourRender returned error: %v", err)
+	}
+
+	lines := strings.Split(out, "\n")
+	for _, l := range lines {
+		if w := ansi.PrintableRuneWidth(l); w > lineNumberWidth+wrapAt {
+			t.Fatalf("expected lines to wrap at %d columns, got width %d: %q", wrapAt, w, stripANSI(l))
+		}
+	}
+
+	// Line numbers must keep matching the source, with an empty gutter on
+	// continuation lines.
+	var numbered []string
+	for _, l := range lines {
+		gutter := strings.TrimSpace(stripANSI(l)[:lineNumberWidth])
+		if gutter != "" {
+			numbered = append(numbered, gutter)
+		}
+	}
+	want := []string{"1", "2", "3", "4", "5"}
+	if strings.Join(numbered, ",") != strings.Join(want, ",") {
+		t.Fatalf("expected gutter numbers %v, got %v", want, numbered)
+	}
+	if len(lines) <= len(want) {
+		t.Fatalf("expected the long line to be wrapped, got %d lines", len(lines))
+	}
+}
+
+func TestGlamourRender_InlineCodeEmphasis(t *testing.T) {
+	const md = "Run `glow config` or see [the docs](docs.md).\n"
+
+	plain := newTestPager(t, Config{}, "README.md", 80)
+	want, err := glamourRender(plain, md)
+	if err != nil {
+		t.Fatalf("glamourRender returned error: %v", err)
+	}
+
+	m := newTestPager(t, Config{InlineCodeEmphasis: "bold,background"}, "README.md", 80)
+	got, err := glamourRender(m, md)
+	if err != nil {
+		t.Fatalf("glamourRender returned error: %v", err)
+	}
+
+	if got == want {
+		t.Fatal("expected inline code emphasis to change the rendered output")
+	}
+	if stripANSI(got) != stripANSI(want) {
+		t.Fatalf("expected printable text to be unchanged:\nwant %q\ngot  %q", stripANSI(want), stripANSI(got))
+	}
+	if !strings.Contains(got, ";48;5;238;1m") {
+		t.Fatalf("expected bold code span on the emphasized background, got %q", got)
+	}
+
+	// Link labels next to inline code must still be found.
+	links := []followableLink{{Label: "the docs"}}
+	if highlightFocusedLink(got, links, 0, reverseSpan) == got {
+		t.Fatal("expected focused link label to be highlighted")
+	}
+}
+
+func TestGlamourRender_InlineCodeBackgroundAuto(t *testing.T) {
+	defer lipgloss.SetHasDarkBackground(lipgloss.HasDarkBackground())
+
+	for _, tc := range []struct {
+		dark bool
+		want string
+	}{
+		{true, ";48;5;238m"},
+		{false, ";48;5;253m"},
+	} {
+		t.Run(fmt.Sprintf("dark_%v", tc.dark), func(t *testing.T) {
+			lipgloss.SetHasDarkBackground(tc.dark)
+			m := newTestPager(t, Config{GlamourStyle: "auto", InlineCodeEmphasis: inlineCodeBackground}, "README.md", 80)
+			out, err := glamourRender(m, "Run `glow config`.\n")
+			if err != nil {
+				t.Fatalf("glamourRender returned error: %v", err)
+			}
+			if !strings.Contains(out, tc.want) {
+				t.Fatalf("expected the background to suit the terminal's, got %q", out)
+			}
+		})
+	}
+}
+
+func TestGutterLine_CodeLineAnchor(t *testing.T) {
+	var src strings.Builder
+	for i := 1; i <= 60; i++ {
+		fmt.Fprintf(&src, "// line %d\n", i)
+	}
+
+	for _, wrapAt := range []uint{0, 5} {
+		m := newTestPager(t, Config{CodeWrapWidth: wrapAt}, "main.go", 80)
+		out, err := glamourRender(m, src.String())
+		if err != nil {
+			t.Fatalf("glamourRender returned error: %v", err)
+		}
+
+		line := gutterLine(out, 42)
+		if line < 0 {
+			t.Fatalf("wrap %d: expected line 42 to be found", wrapAt)
+		}
+		if got := stripANSI(strings.Split(out, "\n")[line]); !strings.HasPrefix(got, "  42  //") {
+			t.Fatalf("wrap %d: expected rendered line for source line 42, got %q", wrapAt, got)
+		}
+		if wrapAt == 0 && line != 41 {
+			t.Fatalf("expected unwrapped line 42 to be rendered line 41, got %d", line)
+		}
+	}
+}
+
+func TestHelpView_MaxWidth(t *testing.T) {
+	const width = 300
+
+	t.Run("wide_is_centered", func(t *testing.T) {
+		m := newTestPager(t, Config{HelpMaxWidth: 80}, "README.md", width)
+		lines := strings.Split(stripANSI(m.helpView()), "\n")
+
+		for i, l := range lines {
+			if w := ansi.PrintableRuneWidth(l); w != width {
+				t.Fatalf("line %d: expected help to fill %d columns, got %d", i, width, w)
+			}
+		}
+		wantIndent := (width-80)/2 + 2
+		if got := len(lines[1]) - len(strings.TrimLeft(lines[1], " ")); got != wantIndent {
+			t.Fatalf("expected help content to start at column %d, got %d", wantIndent, got)
+		}
+	})
+
+	t.Run("narrow_stacks_columns", func(t *testing.T) {
+		wide := newTestPager(t, Config{HelpMaxWidth: 80}, "README.md", width)
+		narrow := newTestPager(t, Config{HelpMaxWidth: 30}, "README.md", width)
+
+		if n, w := len(narrow.helpRows()), len(wide.helpRows()); n <= w {
+			t.Fatalf("expected stacked help to be taller, got %d <= %d", n, w)
+		}
+		narrowHeight := strings.Count(narrow.helpView(), "\n")
+
+		narrow.toggleHelp()
+		if want := 40 - statusBarHeight*2 - narrowHeight; narrow.viewport.Height != want {
+			t.Fatalf("expected viewport height %d with help shown, got %d", want, narrow.viewport.Height)
+		}
+	})
+}
+
+func TestHelpView_Pages(t *testing.T) {
+	m := newTestPager(t, Config{}, "README.md", 80)
+	m.setSize(80, 24)
+	m.common.height = 24
+	m.currentDocument.Body = "# Notes\n"
+	m, _ = m.update(contentRenderedMsg("# Notes\n"))
+
+	rows := len(m.helpRows())
+	var seen []string
+	for page := 1; ; page++ {
+		m = typeKeys(t, m, "?")
+		if !m.showHelp {
+			if page == 1 {
+				t.Fatal("expected ? to show the help")
+			}
+			break
+		}
+		if m.viewport.Height < 24/2-statusBarHeight {
+			t.Fatalf("page %d: expected the help to leave room for the document, got a viewport of %d lines", page, m.viewport.Height)
+		}
+		help := strings.Split(stripANSI(m.View()), "\n")
+		if len(help) != 24 {
+			t.Fatalf("page %d: expected the pager to fill 24 lines, got %d", page, len(help))
+		}
+		var lines []string
+		for _, l := range help[m.viewport.Height+statusBarHeight:] {
+			if l = strings.TrimSpace(l); l != "" {
+				lines = append(lines, l)
+			}
+		}
+		footer := lines[len(lines)-1]
+		if !strings.HasPrefix(footer, fmt.Sprintf("page %d/", page)) {
+			t.Fatalf("page %d: expected the page to be shown, got %q", page, footer)
+		}
+		seen = append(seen, lines[:len(lines)-1]...)
+	}
+	if len(seen) != rows {
+		t.Fatalf("expected every line of the help to be on a page, got %d of %d", len(seen), rows)
+	}
+}
+
+func TestGlamourRender_HeadingGutterIndicator(t *testing.T) {
+	const width = 60
+	src := "# Title\n\nSome text that is long enough to need wrapping at sixty columns, really.\n\n## Section\n\nMore text.\n"
+
+	for _, lineNumbers := range []bool{false, true} {
+		t.Run(fmt.Sprintf("line_numbers_%v", lineNumbers), func(t *testing.T) {
+			m := newTestPager(t, Config{
+				HeadingGutterIndicator: "#",
+				ShowLineNumbers:        lineNumbers,
+				GlamourMaxWidth:        width,
+			}, "README.md", width)
+
+			out, err := glamourRender(m, src)
+			if err != nil {
+				t.Fatalf("glamourRender returned error: %v", err)
+			}
+
+			// The indicator goes after the line numbers.
+			column := 0
+			if lineNumbers {
+				column = lineNumberWidth
+			}
+			var marked []string
+			for _, l := range strings.Split(stripANSI(out), "\n") {
+				if w := ansi.PrintableRuneWidth(strings.TrimRight(l, " ")); w > width {
+					t.Fatalf("expected lines to fit in %d columns, got %d: %q", width, w, l)
+				}
+				if len(l) > column && strings.HasPrefix(l[column:], "#") {
+					marked = append(marked, strings.TrimSpace(l))
+				}
+			}
+			if len(marked) != 2 || !strings.HasSuffix(marked[0], "Title") || !strings.HasSuffix(marked[1], "Section") {
+				t.Fatalf("expected indicators on the two heading lines, got %q", marked)
+			}
+			if !lineNumbers {
+				return
+			}
+
+			// Line numbers can still be read off the gutter.
+			if l := gutterLine(out, 2); l != 1 {
+				t.Fatalf("expected line 2, the heading, to be rendered line 1, got %d", l)
+			}
+			lines := renderedSourceLines(src, out, true)
+			for i, n := range lines {
+				if n != i+1 {
+					t.Fatalf("expected rendered line %d to be numbered %d, got %v", i, i+1, lines)
+				}
+			}
+			relative := strings.Split(stripANSI(relativeLineNumbers(out, 1, 2)), "\n")
+			if !strings.HasPrefix(relative[1], "   1# ") || !strings.HasPrefix(relative[2], "   3  ") {
+				t.Fatalf("expected relative numbers in front of the indicators, got %q", relative[:3])
+			}
+		})
+	}
+}
+
+func TestShowStatusMessage_CountRepeats(t *testing.T) {
+	for _, enabled := range []bool{false, true} {
+		t.Run(fmt.Sprintf("enabled_%v", enabled), func(t *testing.T) {
+			m := pagerModel{common: &commonModel{cfg: Config{CountRepeatedStatusMessages: enabled}}}
+
+			_ = m.showStatusMessage(pagerStatusMessage{"Copied contents", false})
+			_ = m.showStatusMessage(pagerStatusMessage{"Copied contents", false})
+			_ = m.showStatusMessage(pagerStatusMessage{"Copied contents", false})
+
+			want := "Copied contents"
+			if enabled {
+				want = "Copied contents (x3)"
+			}
+			if m.statusMessage != want {
+				t.Fatalf("expected %q, got %q", want, m.statusMessage)
+			}
+
+			_ = m.showStatusMessage(pagerStatusMessage{"Copied 2 links", false})
+			if m.statusMessage != "Copied 2 links" {
+				t.Fatalf("expected a different message to reset the count, got %q", m.statusMessage)
+			}
+
+			m, _ = m.update(statusMessageTimeoutMsg(pagerContext))
+			_ = m.showStatusMessage(pagerStatusMessage{"Copied 2 links", false})
+			if m.statusMessage != "Copied 2 links" {
+				t.Fatalf("expected the count to reset once the message timed out, got %q", m.statusMessage)
+			}
+		})
+	}
+}
+
+func TestReloadIndicator(t *testing.T) {
+	for _, enabled := range []bool{false, true} {
+		t.Run(fmt.Sprintf("enabled_%v", enabled), func(t *testing.T) {
+			m := newTestPager(t, Config{ReloadIndicator: enabled}, "README.md", 80)
+
+			m, _ = m.update(reloadMsg{})
+			m, _ = m.update(contentRenderedMsg("# Reloaded"))
+
+			if got := m.state == pagerStateStatusMessage && m.statusMessage == "⟳ reloaded"; got != enabled {
+				t.Fatalf("expected reload indicator %v, got status %q", enabled, m.statusMessage)
+			}
+
+			// Regular renders, like after a resize, don't count as reloads.
+			m.state = pagerStateBrowse
+			m, _ = m.update(contentRenderedMsg("# Resized"))
+			if m.state == pagerStateStatusMessage {
+				t.Fatalf("expected no status message for a regular render, got %q", m.statusMessage)
+			}
+		})
+	}
+}
+
+func TestCountPrefixGoToLine(t *testing.T) {
+	m := newTestPager(t, Config{}, "README.md", 80)
+
+	var lines []string
+	for i := range 200 {
+		lines = append(lines, fmt.Sprintf("line %d", i+1))
+	}
+	m.rendered = strings.Join(lines, "\n")
+	m.applyRenderedContent()
+
+	m = typeKeys(t, m, "4", "2", "G")
+	if m.viewport.YOffset != 41 {
+		t.Fatalf("expected 42G to scroll to line 42, got offset %d", m.viewport.YOffset)
+	}
+	if m.count != 0 {
+		t.Fatalf("expected count to be consumed, got %d", m.count)
+	}
+
+	m = typeKeys(t, m, "1", "0", "g", "g")
+	if m.viewport.YOffset != 0 {
+		t.Fatalf("expected gg after 10g to go to the top, got offset %d", m.viewport.YOffset)
+	}
+	m = typeKeys(t, m, "1", "0", "g")
+	if m.viewport.YOffset != 9 {
+		t.Fatalf("expected 10g to scroll to line 10, got offset %d", m.viewport.YOffset)
+	}
+
+	m = typeKeys(t, m, "G")
+	if !m.viewport.AtBottom() {
+		t.Fatalf("expected G to go to the bottom, got offset %d", m.viewport.YOffset)
+	}
+
+	m = typeKeys(t, m, "9", "9", "9", "9", "G")
+	if !m.viewport.AtBottom() {
+		t.Fatalf("expected counts past the end to be clamped, got offset %d", m.viewport.YOffset)
+	}
+
+	m = typeKeys(t, m, "0", "G")
+	if !m.viewport.AtBottom() || m.count != 0 {
+		t.Fatalf("expected a leading zero not to count, got offset %d count %d", m.viewport.YOffset, m.count)
+	}
+}
+
+func TestLinePrompt(t *testing.T) {
+	const wrapAt = 40
+	m := newTestPager(t, Config{CodeWrapWidth: wrapAt}, "main.go", 80)
+
+	// Every other line wraps, so source lines and rendered lines differ.
+	var src []string
+	for i := range 100 {
+		if i%2 == 0 {
+			src = append(src, "// "+strings.Repeat("x", 2*wrapAt))
+		} else {
+			src = append(src, fmt.Sprintf("var v%d = %d", i+1, i+1))
+		}
+	}
+	m.currentDocument.Note = "main.go"
+	m.currentDocument.Body = strings.Join(src, "\n")
+	out, err := glamourRender(m, m.currentDocument.Body)
+	if err != nil {
+		t.Fatalf("glamourRender returned error: %v", err)
+	}
+	m.rendered = out
+	m.applyRenderedContent()
+
+	m = typeKeys(t, m, ":", "2", "0", keyEnter)
+	if m.promptingCommand {
+		t.Fatal("expected enter to close the line prompt")
+	}
+	if got := strings.TrimSpace(stripANSI(m.viewport.View())[:lineNumberWidth]); got != "20" {
+		t.Fatalf("expected :20 to show source line 20 at the top, got gutter %q", got)
+	}
+
+	m = typeKeys(t, m, ":", "0", keyEnter)
+	if m.viewport.YOffset != 0 {
+		t.Fatalf("expected :0 to go to the top, got offset %d", m.viewport.YOffset)
+	}
+	m = typeKeys(t, m, ":", "-", "5", keyEnter)
+	if m.viewport.YOffset != 0 {
+		t.Fatalf("expected a negative line to go to the top, got offset %d", m.viewport.YOffset)
+	}
+
+	m = typeKeys(t, m, ":", "9", "9", "9", "9", keyEnter)
+	if !m.viewport.AtBottom() {
+		t.Fatalf("expected lines past the end to go to the bottom, got offset %d", m.viewport.YOffset)
+	}
+
+	m = typeKeys(t, m, ":", "5", keyEsc)
+	if m.promptingCommand || !m.viewport.AtBottom() {
+		t.Fatalf("expected esc to cancel the prompt without moving, got offset %d", m.viewport.YOffset)
+	}
+}
+
+func TestStripGutter(t *testing.T) {
+	m := newTestPager(t, Config{}, "main.go", 80)
+	src := "package main\n\nfunc main() {}\n"
+
+	out, err := glamourRender(m, src)
+	if err != nil {
+		t.Fatalf("glamourRender returned error: %v", err)
+	}
+
+	text := visibleText(out, 0, 3)
+	if !strings.HasPrefix(text, "   1") {
+		t.Fatalf("expected the gutter in the rendered text, got %q", text)
+	}
+
+	stripped := stripGutter(text, m.gutterWidth())
+	for i, l := range strings.Split(strings.TrimSuffix(stripped, "\n"), "\n") {
+		want := strings.Split(src, "\n")[i]
+		if strings.TrimSpace(l) != want {
+			t.Fatalf("line %d: expected %q without the gutter, got %q", i+1, want, l)
+		}
+		if strings.ContainsAny(l, "0123456789") {
+			t.Fatalf("line %d: expected line numbers to be removed, got %q", i+1, l)
+		}
+	}
+}
+
+func TestToggleWrap_PreservesAnchor(t *testing.T) {
+	m := newTestPager(t, Config{GlamourMaxWidth: 40}, "README.md", 40)
+
+	var b strings.Builder
+	for i := range 20 {
+		fmt.Fprintf(&b, "## Section %d\n\n", i)
+		for j := range 3 {
+			fmt.Fprintf(&b, "Paragraph %d.%d has enough words in it to wrap at forty columns quite a few times over.\n\n", i, j)
+		}
+	}
+	body := b.String()
+	m.currentDocument.Body = body
+
+	render := func() {
+		t.Helper()
+		out, err := glamourRender(m, body)
+		if err != nil {
+			t.Fatalf("glamourRender returned error: %v", err)
+		}
+		m, _ = m.update(contentRenderedMsg(out))
+	}
+	topLine := func() string {
+		return strings.TrimSpace(stripANSI(strings.Split(m.rendered, "\n")[m.viewport.YOffset]))
+	}
+
+	render()
+	wrappedLines := m.viewport.TotalLineCount()
+
+	// Scroll to the start of a paragraph in the middle of the document.
+	for i, l := range strings.Split(stripANSI(m.rendered), "\n") {
+		if strings.Contains(l, "Paragraph 12.1") {
+			m.viewport.SetYOffset(i)
+			break
+		}
+	}
+	if !strings.HasPrefix(topLine(), "Paragraph 12.1") {
+		t.Fatalf("unexpected top line %q", topLine())
+	}
+
+	_ = m.toggleWrap()
+	render()
+	if m.viewport.TotalLineCount() >= wrappedLines {
+		t.Fatalf("expected fewer lines without wrapping, got %d >= %d", m.viewport.TotalLineCount(), wrappedLines)
+	}
+	if !strings.HasPrefix(topLine(), "Paragraph 12.1") {
+		t.Fatalf("expected the same paragraph at the top after unwrapping, got %q", topLine())
+	}
+
+	_ = m.toggleWrap()
+	render()
+	if !strings.HasPrefix(topLine(), "Paragraph 12.1") {
+		t.Fatalf("expected the same paragraph at the top after wrapping, got %q", topLine())
+	}
+}
+
+func TestGlamourRender_EmptyDocument(t *testing.T) {
+	for name, body := range map[string]string{
+		"empty":           "",
+		"whitespace_only": "  \n\t\n\n",
+	} {
+		t.Run(name, func(t *testing.T) {
+			m := newTestPager(t, Config{}, "empty.md", 80)
+			m.currentDocument.Body = body
+
+			out, err := glamourRender(m, body)
+			if err != nil {
+				t.Fatalf("glamourRender returned error: %v", err)
+			}
+			if out != emptyDocumentNotice {
+				t.Fatalf("expected the empty document placeholder, got %q", out)
+			}
+
+			m, _ = m.update(contentRenderedMsg(out))
+			if m.state != pagerStateStatusMessage || m.statusMessage != "Document is empty" {
+				t.Fatalf("expected a status message about the empty document, got %q", m.statusMessage)
+			}
+		})
+	}
+}
+
+func TestContentMargin(t *testing.T) {
+	m := newTestPager(t, Config{ContentMargin: 6}, "README.md", 80)
+	if m.viewport.Width != 68 {
+		t.Fatalf("expected the margins to take 12 columns, got a viewport %d wide", m.viewport.Width)
+	}
+
+	out, err := glamourRender(m, "# Title\n\nSome text.\n")
+	if err != nil {
+		t.Fatalf("glamourRender returned error: %v", err)
+	}
+	m.rendered = out
+	m.applyRenderedContent()
+
+	view := stripANSI(m.View())
+	lines := strings.Split(view, "\n")
+	for i, l := range lines[:m.viewport.Height] {
+		if !strings.HasPrefix(l, strings.Repeat(" ", 6)) {
+			t.Fatalf("line %d isn't indented by the margin: %q", i, l)
+		}
+	}
+	if !strings.Contains(view, "Some text.") {
+		t.Fatalf("expected the rendered content in the view, got:\n%s", view)
+	}
+
+	// Narrow terminals give up margin before text.
+	m.common.width = 24
+	m.setSize(24, 40)
+	if m.viewport.Width != minContentWidth {
+		t.Fatalf("expected the viewport to keep %d columns, got %d", minContentWidth, m.viewport.Width)
+	}
+}
+
+func TestScheduleSync_Coalesces(t *testing.T) {
+	m := newTestPager(t, Config{HighPerformancePager: true}, "README.md", 80)
+	m.viewport.HighPerformanceRendering = true
+	m.viewport.SetContent(strings.Repeat("line\n", 200))
+
+	var cmd tea.Cmd
+	m, cmd = m.update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("G")})
+	if cmd == nil {
+		t.Fatal("expected the first jump to schedule a sync")
+	}
+	for _, k := range []string{"g", "G", "g"} {
+		m, cmd = m.update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)})
+		if cmd != nil {
+			t.Fatalf("expected %q to share the pending sync", k)
+		}
+	}
+
+	m, cmd = m.update(viewportSyncMsg{})
+	if cmd == nil || m.syncPending {
+		t.Fatal("expected the scheduled sync to be issued once")
+	}
+	if m.viewport.YOffset != 0 {
+		t.Fatalf("expected the sync to reflect the last jump, got offset %d", m.viewport.YOffset)
+	}
+
+	if _, cmd = m.update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("G")}); cmd == nil {
+		t.Fatal("expected a new sync to be scheduled after the previous one went out")
+	}
+}
+
+func TestToggleHelp_ScrollPosition(t *testing.T) {
+	newPager := func(pin bool) pagerModel {
+		m := newTestPager(t, Config{PinHelpToggle: pin}, "README.md", 80)
+		var lines []string
+		for i := range 100 {
+			lines = append(lines, fmt.Sprintf("line %d", i+1))
+		}
+		m.rendered = strings.Join(lines, "\n")
+		m.applyRenderedContent()
+		return m
+	}
+
+	for _, pin := range []bool{false, true} {
+		for _, pos := range []string{"top", "middle", "bottom"} {
+			t.Run(fmt.Sprintf("pin_%v_%s", pin, pos), func(t *testing.T) {
+				m := newPager(pin)
+				m.viewport.SetYOffset(30)
+				m.toggleHelp()
+				if m.viewport.YOffset != 30 {
+					t.Fatalf("expected opening help to keep the offset, got %d", m.viewport.YOffset)
+				}
+
+				switch pos {
+				case "top":
+					m.viewport.GotoTop()
+				case "bottom":
+					m.viewport.GotoBottom()
+				}
+				top := m.viewport.YOffset
+
+				// Closing help makes the viewport taller, which at the
+				// bottom leaves blank space unless we scroll up.
+				m.toggleHelp()
+				switch {
+				case pin || pos != "bottom":
+					if m.viewport.YOffset != top {
+						t.Fatalf("expected closing help to keep offset %d, got %d", top, m.viewport.YOffset)
+					}
+				case m.viewport.YOffset >= top || !m.viewport.AtBottom() || m.viewport.PastBottom():
+					t.Fatalf("expected closing help to fill the screen, got offset %d", m.viewport.YOffset)
+				}
+			})
+		}
+	}
+}
+
+func TestToggleZen(t *testing.T) {
+	m := newTestPager(t, Config{ZenWidth: 60}, "README.md", 100)
+	m.toggleHelp()
+	width := m.viewport.Width
+
+	_ = m.toggleZen()
+	if m.zen == nil || m.showHelp {
+		t.Fatal("expected zen mode to hide the help")
+	}
+	if m.viewport.Width != 60 || m.contentMargin() != 20 {
+		t.Fatalf("expected a centered 60 column reading width, got %d with margin %d", m.viewport.Width, m.contentMargin())
+	}
+	if m.statusMessage != "Zen mode on" {
+		t.Fatalf("unexpected status %q", m.statusMessage)
+	}
+
+	m.state = pagerStateBrowse
+	lines := strings.Split(m.View(), "\n")
+	if status := lines[m.viewport.Height]; strings.TrimSpace(status) != "" {
+		t.Fatalf("expected the status bar to be hidden, got %q", status)
+	}
+
+	_ = m.toggleZen()
+	if m.zen != nil || !m.showHelp || m.viewport.Width != width {
+		t.Fatal("expected leaving zen mode to restore the previous layout")
+	}
+	if m.statusMessage != "Zen mode off" {
+		t.Fatalf("unexpected status %q", m.statusMessage)
+	}
+}
+
+func TestStatusBarView_ScrollPercentAtEnds(t *testing.T) {
+	m := newTestPager(t, Config{}, "README.md", 80)
+	m.viewport.SetContent(strings.Repeat("line\n", 1000))
+
+	status := func() string {
+		var b strings.Builder
+		m.statusBarView(&b)
+		return stripANSI(b.String())
+	}
+
+	m.viewport.GotoTop()
+	if s := status(); !strings.Contains(s, "  0%") {
+		t.Fatalf("expected 0%% at the top, got %q", s)
+	}
+
+	m.viewport.SetYOffset(1)
+	if s := status(); !strings.Contains(s, "  1%") {
+		t.Fatalf("expected 1%% just below the top, got %q", s)
+	}
+
+	m.viewport.GotoBottom()
+	m.viewport.SetYOffset(m.viewport.YOffset - 1)
+	if s := status(); !strings.Contains(s, " 99%") {
+		t.Fatalf("expected 99%% just before the bottom, got %q", s)
+	}
+
+	m.viewport.GotoBottom()
+	if s := status(); !strings.Contains(s, "100%") {
+		t.Fatalf("expected 100%% at the bottom, got %q", s)
+	}
+}
+
+func TestRenderWithGlamour_StyleFallback(t *testing.T) {
+	m := newTestPager(t, Config{GlamourStyle: "/no/such/style.json"}, "README.md", 80)
+
+	batch, ok := renderWithGlamour(m, "# Hello\n\nWorld.\n")().(tea.BatchMsg)
+	if !ok || len(batch) != 2 {
+		t.Fatalf("expected the content and a fallback notice, got %#v", batch)
+	}
+	for _, cmd := range batch {
+		m, _ = m.update(cmd())
+	}
+
+	if !strings.Contains(stripANSI(m.rendered), "World.") {
+		t.Fatalf("expected the document to render with the default style, got %q", m.rendered)
+	}
+	if want := "Couldn't load style “/no/such/style.json”, using the default"; m.statusMessage != want {
+		t.Fatalf("expected status %q, got %q", want, m.statusMessage)
+	}
+	if m.common.cfg.GlamourStyle != fallbackGlamourStyle {
+		t.Fatalf("expected the fallback style to stick, got %q", m.common.cfg.GlamourStyle)
+	}
+}
+
+func TestGlamourStylePath(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "style.json")
+	mustWriteFile(t, path, `{"strong": {"block_prefix": "<<", "block_suffix": ">>"}}`)
+
+	m := newTestPager(t, Config{GlamourStylePath: path}, "README.md", 80)
+	m.currentDocument.Body = "Some **bold** text.\n"
+	var render func(tea.Cmd)
+	render = func(cmd tea.Cmd) {
+		t.Helper()
+		msg := cmd()
+		if batch, ok := msg.(tea.BatchMsg); ok {
+			for _, c := range batch {
+				if c != nil {
+					render(c)
+				}
+			}
+			return
+		}
+		m, _ = m.update(msg)
+	}
+
+	render(renderWithGlamour(m, m.currentDocument.Body))
+	if !strings.Contains(stripANSI(m.rendered), "<<bold>>") {
+		t.Fatalf("expected the style file to be used, got %q", stripANSI(m.rendered))
+	}
+
+	// A broken style file falls back to the named style, but is tried again
+	// when reloading the style.
+	mustWriteFile(t, path, `{"strong": `)
+	_ = m.reloadStyle()
+	render(renderWithGlamour(m, m.currentDocument.Body))
+	if strings.Contains(stripANSI(m.rendered), "<<bold>>") || !strings.Contains(stripANSI(m.rendered), "bold") {
+		t.Fatalf("expected the named style to be used, got %q", stripANSI(m.rendered))
+	}
+	if want := "Couldn't load style file “" + path + "”, using “dark”"; m.statusMessage != want {
+		t.Fatalf("expected status %q, got %q", want, m.statusMessage)
+	}
+
+	mustWriteFile(t, path, `{"strong": {"block_prefix": "[", "block_suffix": "]"}}`)
+	_ = m.reloadStyle()
+	render(renderWithGlamour(m, m.currentDocument.Body))
+	if !strings.Contains(stripANSI(m.rendered), "[bold]") || m.statusMessage != "Reloaded style" {
+		t.Fatalf("expected the fixed style file to be used, got %q (%q)", stripANSI(m.rendered), m.statusMessage)
+	}
+
+	m.common.cfg.GlamourStylePath = ""
+	if m.reloadStyle(); m.statusMessage != "No style file configured" {
+		t.Fatalf("unexpected status %q", m.statusMessage)
+	}
+}
+
+func TestFollowOnReload(t *testing.T) {
+	logLines := func(n int) string {
+		lines := make([]string, n)
+		for i := range lines {
+			lines[i] = fmt.Sprintf("entry %d", i)
+		}
+		return strings.Join(lines, "\n")
+	}
+
+	for _, follow := range []bool{false, true} {
+		t.Run(fmt.Sprintf("follow_%v", follow), func(t *testing.T) {
+			m := newTestPager(t, Config{Follow: follow}, "app.log", 80)
+			m, _ = m.update(contentRenderedMsg(logLines(100)))
+			m.viewport.SetYOffset(20)
+
+			m, _ = m.update(reloadMsg{})
+			m, _ = m.update(contentRenderedMsg(logLines(150)))
+
+			if follow && !m.viewport.AtBottom() {
+				t.Fatalf("expected to follow the appended content, got offset %d", m.viewport.YOffset)
+			}
+			if !follow && m.viewport.YOffset != 20 {
+				t.Fatalf("expected the position to be kept, got offset %d", m.viewport.YOffset)
+			}
+
+			// Renders that aren't reloads, like after a resize, stay put.
+			m.viewport.SetYOffset(20)
+			m, _ = m.update(contentRenderedMsg(logLines(150)))
+			if m.viewport.YOffset != 20 {
+				t.Fatalf("expected a regular render to keep the position, got offset %d", m.viewport.YOffset)
+			}
+		})
+	}
+}
+
+func TestComputeStats(t *testing.T) {
+	body := "# Title\n\nSome words [here](a.md) and [there](https://example.com).\n\n" +
+		"## Code\n\n```go\nfmt.Println(\"hi\")\n```\n\nMail [me](mailto:me@example.com).\n"
+
+	got := computeStats(body, 1)
+	want := documentStats{
+		Words:      11,
+		Lines:      11,
+		Characters: len(body),
+		Headings:   2,
+		Links:      3,
+		Followable: 1,
+		External:   2,
+		CodeBlocks: 1,
+	}
+	if got != want {
+		t.Fatalf("expected %+v, got %+v", want, got)
+	}
+
+	m := newTestPager(t, Config{}, "README.md", 80)
+	m.currentDocument.Body = body
+	m = typeKeys(t, m, "I")
+	if m.overlay == nil || m.overlay.kind != overlayStats {
+		t.Fatal("expected the statistics overlay to open")
+	}
+	m = typeKeys(t, m, keyEsc)
+	if m.overlay != nil {
+		t.Fatal("expected escape to close the statistics")
+	}
+}
+
+func TestGlamourRender_ColorDepth(t *testing.T) {
+	// Code blocks are left out as chroma picks its own colors.
+	src := "# Title\n\nSome `code` and a [link](https://example.com).\n"
+
+	cases := []struct {
+		depth   string
+		allowed []string
+		banned  []string
+	}{
+		{depth: colorDepth256, allowed: []string{"38;5;"}, banned: []string{"38;2;"}},
+		{depth: colorDepth16, allowed: []string{"\x1b[9"}, banned: []string{"38;2;", "38;5;"}},
+		{depth: colorDepthNone, banned: []string{"38;", "48;", "\x1b[9"}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.depth, func(t *testing.T) {
+			m := newTestPager(t, Config{ColorDepth: tc.depth}, "README.md", 80)
+			out, err := glamourRender(m, src)
+			if err != nil {
+				t.Fatalf("glamourRender returned error: %v", err)
+			}
+			for _, seq := range tc.allowed {
+				if !strings.Contains(out, seq) {
+					t.Errorf("expected %q colors in the output: %q", seq, out)
+				}
+			}
+			for _, seq := range tc.banned {
+				if strings.Contains(out, seq) {
+					t.Errorf("expected no %q colors in the output: %q", seq, out)
+				}
+			}
+		})
+	}
+}
+
+func TestWatchIdleTimeout(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "README.md")
+	mustWriteFile(t, path, "# Hi\n")
+
+	m := newTestPager(t, Config{WatchIdleTimeout: time.Minute}, "README.md", 80)
+	if m.watcher == nil {
+		t.Skip("no fsnotify watcher available")
+	}
+	t.Cleanup(m.stopWatching)
+	m.currentDocument.localPath = path
+	_ = m.startWatching()
+
+	// Recent activity only pushes the check back.
+	m.lastActivity = time.Now()
+	if cmd := m.checkIdle(); cmd == nil || m.idle || m.watchedDir == "" {
+		t.Fatal("expected to keep watching and check again later")
+	}
+
+	m.idleCheckPending = false
+	m.lastActivity = time.Now().Add(-time.Hour)
+	m, _ = m.update(idleCheckMsg{})
+	if !m.idle || m.watchedDir != "" {
+		t.Fatal("expected to stop watching once idle")
+	}
+
+	_ = m.noteActivity()
+	if m.idle || m.watchedDir == "" {
+		t.Fatal("expected a key press to start watching again")
+	}
+
+	// Changes made while we weren't watching are picked up on wake up.
+	m.lastActivity = time.Now().Add(-time.Hour)
+	_ = m.checkIdle()
+	later := time.Now().Add(time.Hour)
+	if err := os.Chtimes(path, later, later); err != nil {
+		t.Fatal(err)
+	}
+	m.idleCheckPending = true // leave out the next check
+	if _, ok := m.noteActivity()().(reloadMsg); !ok {
+		t.Fatal("expected the document to be reloaded after it changed")
+	}
+}
+
+func TestWatchDeletedFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "README.md")
+	mustWriteFile(t, path, "# Hi\n")
+
+	m := newTestPager(t, Config{}, "README.md", 80)
+	if m.watcher == nil {
+		t.Skip("no fsnotify watcher available")
+	}
+	t.Cleanup(m.stopWatching)
+	m.currentDocument.localPath = path
+
+	// Waits for the watcher to see a change to the file.
+	watch := func(change func()) tea.Msg {
+		t.Helper()
+		cmd := m.startWatching()
+		msgs := make(chan tea.Msg, 1)
+		go func() { msgs <- cmd() }()
+		change()
+		select {
+		case msg := <-msgs:
+			return msg
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for the watcher")
+			return nil
+		}
+	}
+
+	msg := watch(func() {
+		if err := os.Remove(path); err != nil {
+			t.Fatal(err)
+		}
+	})
+	if _, ok := msg.(fileRemovedMsg); !ok {
+		t.Fatalf("expected the removal to be noticed, got %T", msg)
+	}
+	m, _ = m.update(msg)
+	m, _ = m.update(fileRemovalCheckMsg{})
+	var status strings.Builder
+	m.statusBarView(&status)
+	if !m.fileDeleted || m.statusMessage != "File deleted" || !strings.Contains(stripANSI(status.String()), " deleted ") {
+		t.Fatalf("expected the file to be shown as deleted, got status %q", m.statusMessage)
+	}
+	if m, _ = m.update(reloadMsg{}); m.reloading {
+		t.Fatal("expected a deleted file not to be reloaded")
+	}
+
+	// It's picked up again once it's back.
+	msg = watch(func() { mustWriteFile(t, path, "# Back\n") })
+	if _, ok := msg.(reloadMsg); !ok {
+		t.Fatalf("expected the file to be reloaded once it's back, got %T", msg)
+	}
+	m, _ = m.update(msg)
+	if m.fileDeleted || !m.reloading {
+		t.Fatal("expected the file to be reloaded once it's back")
+	}
+
+	// Saving by renaming a new file over the old one, like vim does, isn't
+	// taken for deleting it.
+	m.reloading = false
+	msg = watch(func() {
+		if err := os.Rename(path, path+"~"); err != nil {
+			t.Fatal(err)
+		}
+		mustWriteFile(t, path, "# Saved\n")
+	})
+	m, _ = m.update(msg)
+	m, _ = m.update(fileRemovalCheckMsg{})
+	if m.fileDeleted {
+		t.Fatal("expected a file saved by replacing it not to be taken for deleted")
+	}
+}
+
+func TestWatchDebounce(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "README.md")
+	mustWriteFile(t, path, "# Hi\n")
+
+	const debounce = 100 * time.Millisecond
+	m := newTestPager(t, Config{WatchDebounce: debounce}, "README.md", 80)
+	if m.watcher == nil {
+		t.Skip("no fsnotify watcher available")
+	}
+	t.Cleanup(m.stopWatching)
+	m.currentDocument.localPath = path
+
+	msgs := make(chan tea.Msg, 1)
+	cmd := m.startWatching()
+	go func() { msgs <- cmd() }()
+
+	// A burst of writes is reloaded once, after it's over.
+	start := time.Now()
+	for i := range 5 {
+		mustWriteFile(t, path, fmt.Sprintf("# Write %d\n", i))
+		time.Sleep(debounce / 4)
+	}
+	last := time.Now()
+	select {
+	case msg := <-msgs:
+		if _, ok := msg.(reloadMsg); !ok {
+			t.Fatalf("expected a reload, got %T", msg)
+		}
+		if time.Since(last) < debounce/2 {
+			t.Fatalf("expected to wait for the writes to stop, reloaded %v after the first one", time.Since(start))
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the reload")
+	}
+
+	// Stopping doesn't wait for a pending reload.
+	cmd = m.startWatching()
+	go func() { msgs <- cmd() }()
+	mustWriteFile(t, path, "# Again\n")
+	time.Sleep(debounce / 4)
+	m.stopWatching()
+	select {
+	case msg := <-msgs:
+		if msg != nil {
+			t.Fatalf("expected no reload after stopping, got %T", msg)
+		}
+	case <-time.After(debounce / 2):
+		t.Fatal("expected watching to stop right away")
+	}
+}
+
+func TestToggleWatching(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "README.md")
+	mustWriteFile(t, path, "# Hi\n")
+
+	m := newTestPager(t, Config{}, "README.md", 80)
+	if m.watcher == nil {
+		t.Skip("no fsnotify watcher available")
+	}
+	t.Cleanup(m.stopWatching)
+	m.currentDocument.localPath = path
+	_ = m.startWatching()
+	statusBar := func() string {
+		var b strings.Builder
+		m.statusBarView(&b)
+		return stripANSI(b.String())
+	}
+
+	m = typeKeys(t, m, "W")
+	if !m.watchPaused || m.watchedDir != "" || m.statusMessage != "Watching off, r to reload" {
+		t.Fatalf("expected watching to be off, got status %q", m.statusMessage)
+	}
+	if !strings.Contains(statusBar(), " nowatch ") {
+		t.Fatalf("expected the status bar to show watching is off, got %q", statusBar())
+	}
+	if m.startWatching() != nil || m.watchedDir != "" {
+		t.Fatal("expected rendering the document not to start watching it again")
+	}
+
+	m = typeKeys(t, m, "W")
+	if m.watchPaused || m.watchedDir == "" || strings.Contains(statusBar(), "nowatch") {
+		t.Fatal("expected watching to be on again")
+	}
+
+	// Changes made while watching was off are picked up.
+	m = typeKeys(t, m, "W")
+	later := time.Now().Add(time.Hour)
+	if err := os.Chtimes(path, later, later); err != nil {
+		t.Fatal(err)
+	}
+	cmds := m.toggleWatching()().(tea.BatchMsg)
+	if _, ok := cmds[len(cmds)-1]().(reloadMsg); !ok {
+		t.Fatal("expected the document to be reloaded after it changed")
+	}
+
+	m = typeKeys(t, m, "W")
+	m.unload()
+	if m.watchPaused {
+		t.Fatal("expected watching to be back on for the next document")
+	}
+}
+
+func TestReloadConfig(t *testing.T) {
+	m := newTestPager(t, Config{}, "README.md", 80)
+	m.currentDocument.Body = "# Title\n\nText.\n"
+
+	_ = m.reloadConfig()
+	if m.statusMessage != "Can't reload the configuration" {
+		t.Fatalf("unexpected status %q", m.statusMessage)
+	}
+
+	reloaded := Config{GlamourStyle: "light", ColorDepth: colorDepthTrueColor, ShowLineNumbers: true}
+	var fail error
+	m.common.cfg.ReloadConfig = func() (Config, error) {
+		return reloaded, fail
+	}
+
+	fail = errors.New("yaml: bad indentation")
+	_ = m.reloadConfig()
+	if m.common.cfg.ShowLineNumbers || m.statusMessage != "Couldn't reload config: yaml: bad indentation" {
+		t.Fatalf("expected to keep the old config, got status %q", m.statusMessage)
+	}
+
+	fail = nil
+	cmd := m.reloadConfig()
+	if !m.common.cfg.ShowLineNumbers || m.common.cfg.GlamourStyle != "light" {
+		t.Fatalf("expected the new config to be used, got %+v", m.common.cfg)
+	}
+	if m.common.cfg.ReloadConfig == nil {
+		t.Fatal("expected to be able to reload again")
+	}
+	if config.ShowLineNumbers {
+		t.Fatal("expected the package's config to be left as it was")
+	}
+	if m.statusMessage != "Reloaded configuration" || m.pendingAnchor == nil || cmd == nil {
+		t.Fatalf("expected a re-render at the same place, got status %q", m.statusMessage)
+	}
+}
+
+func TestGoForward(t *testing.T) {
+	root := absEvalSymlinks(t, t.TempDir())
+	for _, name := range []string{"a.md", "b.md", "c.md"} {
+		mustWriteFile(t, filepath.Join(root, name), "")
+	}
+	m := newTestPager(t, Config{}, "a.md", 80)
+	m.common.cwd = root
+	var lines []string
+	for i := range 100 {
+		lines = append(lines, fmt.Sprintf("line %d", i+1))
+	}
+	m.rendered = strings.Join(lines, "\n")
+	m.applyRenderedContent()
+
+	// open pretends a document was loaded and scrolled.
+	open := func(name string, y int) {
+		m.currentDocument = markdown{localPath: filepath.Join(root, name), Note: name}
+		m.pendingRestoreYOffset = nil
+		m.viewport.SetYOffset(y)
+	}
+
+	open("a.md", 10)
+	m = typeKeys(t, m, ">")
+	if m.statusMessage != "Nothing to go forward to" {
+		t.Fatalf("unexpected status %q", m.statusMessage)
+	}
+
+	m.navigateTo(filepath.Join(root, "b.md"), "b.md")
+	open("b.md", 20)
+	m = typeKeys(t, m, keyBackspace)
+	if len(m.history) != 0 || len(m.forward) != 1 || m.forward[0].YOffset != 20 {
+		t.Fatalf("expected b.md to be remembered for going forward, got %v", m.forward)
+	}
+
+	open("a.md", 10)
+	m = typeKeys(t, m, ">")
+	if len(m.forward) != 0 || len(m.history) != 1 || m.history[0].YOffset != 10 {
+		t.Fatalf("expected a.md in the history, got %v", m.history)
+	}
+	if m.pendingRestoreYOffset == nil || *m.pendingRestoreYOffset != 20 {
+		t.Fatal("expected the offset of b.md to be restored")
+	}
+	if m.statusMessage != "Forward: b.md" {
+		t.Fatalf("unexpected status %q", m.statusMessage)
+	}
+
+	// Following a link drops the way forward.
+	open("b.md", 20)
+	m = typeKeys(t, m, keyBackspace)
+	open("a.md", 10)
+	m.navigateTo(filepath.Join(root, "c.md"), "c.md")
+	if len(m.forward) != 0 {
+		t.Fatalf("expected navigating to clear the forward stack, got %v", m.forward)
+	}
+}
+
+func TestBreadcrumb(t *testing.T) {
+	root := absEvalSymlinks(t, t.TempDir())
+	m := newTestPager(t, Config{}, "auth.md", 80)
+	m.common.cwd = root
+
+	if got := m.breadcrumb(80); got != "auth.md" {
+		t.Fatalf("expected just the current note without history, got %q", got)
+	}
+
+	for _, name := range []string{"index.md", "docs/api.md"} {
+		path := filepath.Join(root, name)
+		mustWriteFile(t, path, "")
+		m.history = append(m.history, navEntry{Path: path})
+	}
+	if got, want := m.breadcrumb(80), "index.md > "+filepath.Join("docs", "api.md")+" > auth.md"; got != want {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+	if got, want := m.breadcrumb(25), "… > "+filepath.Join("docs", "api.md")+" > auth.md"; got != want {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+	if got, want := m.breadcrumb(5), "… > auth.md"; got != want {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+
+	var b strings.Builder
+	m.statusBarView(&b)
+	if !strings.Contains(stripANSI(b.String()), "index.md > ") {
+		t.Fatalf("expected the breadcrumb in the status bar, got %q", stripANSI(b.String()))
+	}
+}
+
+func TestRelativeLineNumbers(t *testing.T) {
+	rendered := "   1  a\n   2  b\n      wrapped\n▌  3  # c\n   4\x1b[1m\x1b[0m  d"
+	want := "   1  a\n   2  b\n      wrapped\n▌  1  # c\n   2\x1b[1m\x1b[0m  d"
+	if got := relativeLineNumbers(rendered, 1, 2); got != want {
+		t.Fatalf("expected\n%s\ngot\n%s", want, got)
+	}
+
+	var src strings.Builder
+	for i := range 100 {
+		fmt.Fprintf(&src, "x := %d\n", i)
+	}
+	m := newTestPager(t, Config{RelativeLineNumbers: true}, "main.go", 80)
+	m.currentDocument.Body = src.String()
+	out, err := glamourRender(m, src.String())
+	if err != nil {
+		t.Fatalf("glamourRender returned error: %v", err)
+	}
+	m.rendered = out
+	m.applyRenderedContent()
+	m.viewport.SetYOffset(20)
+	m.renumberLines()
+
+	lines := strings.Split(stripANSI(m.viewport.View()), "\n")
+	if got := strings.Fields(lines[0])[0]; got != "21" {
+		t.Fatalf("expected the top line to keep its number, got %q", got)
+	}
+	if got := strings.Fields(lines[3])[0]; got != "3" {
+		t.Fatalf("expected the fourth line to be numbered 3, got %q", got)
+	}
+	if l := gutterLine(m.rendered, 42); l != 41 {
+		t.Fatalf("expected absolute numbers to be kept for going to a line, got %d", l)
+	}
+
+	m = typeKeys(t, m, "#")
+	if got := strings.Fields(stripANSI(m.viewport.View()))[0]; got != "21" || m.relativeNumbers {
+		t.Fatalf("expected # to switch back to absolute numbers, got %q", got)
+	}
+
+	md := newTestPager(t, Config{}, "README.md", 80)
+	md = typeKeys(t, md, "#")
+	if md.statusMessage != "No line numbers" {
+		t.Fatalf("unexpected status %q", md.statusMessage)
+	}
+}
+
+func TestToggleWrap_HorizontalScroll(t *testing.T) {
+	m := newTestPager(t, Config{GlamourMaxWidth: 40}, "README.md", 40)
+	body := "Start " + strings.Repeat("word ", 30) + "end.\n"
+	m.currentDocument.Body = body
+	render := func() {
+		t.Helper()
+		out, err := glamourRender(m, body)
+		if err != nil {
+			t.Fatalf("glamourRender returned error: %v", err)
+		}
+		m, _ = m.update(contentRenderedMsg(out))
+	}
+	statusBar := func() string {
+		var b strings.Builder
+		m.statusBarView(&b)
+		return stripANSI(b.String())
+	}
+
+	render()
+	view := m.viewport.View()
+	m = typeKeys(t, m, "l")
+	if m.viewport.View() != view || strings.Contains(statusBar(), "nowrap") {
+		t.Fatal("expected no sideways scrolling while wrapping")
+	}
+
+	_ = m.toggleWrap()
+	render()
+	if !strings.Contains(statusBar(), "nowrap") {
+		t.Fatalf("expected the status bar to show lines aren't wrapped, got %q", statusBar())
+	}
+	if strings.Contains(stripANSI(m.viewport.View()), "end.") {
+		t.Fatal("expected the end of the long line to be cut off")
+	}
+	for range 20 {
+		m = typeKeys(t, m, "l")
+	}
+	if !strings.Contains(stripANSI(m.viewport.View()), "end.") {
+		t.Fatal("expected l to scroll to the end of the long line")
+	}
+
+	_ = m.toggleWrap()
+	render()
+	if !strings.Contains(stripANSI(m.viewport.View()), "Start") {
+		t.Fatal("expected wrapping to scroll back to the start of the lines")
+	}
+}
+
+func TestToggleWrap_LeftScrollsBeforeLeaving(t *testing.T) {
+	p := newTestPager(t, Config{GlamourMaxWidth: 40}, "README.md", 40)
+	body := "Start " + strings.Repeat("word ", 30) + "end.\n"
+	p.currentDocument.Body = body
+	p.noWrap = true
+	out, err := glamourRender(p, body)
+	if err != nil {
+		t.Fatalf("glamourRender returned error: %v", err)
+	}
+	p, _ = p.update(contentRenderedMsg(out))
+
+	initSections()
+	var m tea.Model = model{
+		common: p.common,
+		state:  stateShowDocument,
+		stash:  newStashModel(p.common),
+		pager:  p,
+	}
+	press := func(key string) {
+		t.Helper()
+		m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)})
+	}
+
+	press("l")
+	press("h")
+	if m.(model).state != stateShowDocument {
+		t.Fatal("expected h to scroll back to the start of the lines rather than leave the document")
+	}
+	if m.(model).pager.xOffset != 0 {
+		t.Fatal("expected h to scroll back to the start of the lines")
+	}
+	press("h")
+	if m.(model).state != stateShowStash {
+		t.Fatal("expected h at the start of the lines to leave the document")
+	}
+}
+
+func TestScrollSideways(t *testing.T) {
+	m := newTestPager(t, Config{}, "main.go", 40)
+	body := "package main\n\nvar s = \"" + strings.Repeat("a", 60) + "end\"\n"
+	m.currentDocument.Body = body
+	out, err := glamourRender(m, body)
+	if err != nil {
+		t.Fatalf("glamourRender returned error: %v", err)
+	}
+	m, _ = m.update(contentRenderedMsg(out))
+	statusBar := func() string {
+		var b strings.Builder
+		m.statusBarView(&b)
+		return stripANSI(b.String())
+	}
+
+	if strings.Contains(stripANSI(m.viewport.View()), "end") {
+		t.Fatal("expected the end of the long line to be cut off")
+	}
+	m = typeKeys(t, m, "l")
+	if m.xOffset != horizontalScrollStep || !strings.Contains(statusBar(), fmt.Sprintf(" col %d ", horizontalScrollStep+1)) {
+		t.Fatalf("expected to scroll sideways, got offset %d and status bar %q", m.xOffset, statusBar())
+	}
+	for range 20 {
+		m = typeKeys(t, m, "right")
+	}
+	if !strings.Contains(stripANSI(m.viewport.View()), "end") {
+		t.Fatal("expected to scroll to the end of the long line")
+	}
+	if want := widestLine(m.rendered) - m.viewport.Width; m.xOffset != want {
+		t.Fatalf("expected to stop at the end of the widest line, offset %d, got %d", want, m.xOffset)
+	}
+	for range 20 {
+		m = typeKeys(t, m, "h")
+	}
+	if m.xOffset != 0 || strings.Contains(statusBar(), " col ") {
+		t.Fatalf("expected to scroll back to the start, got offset %d", m.xOffset)
+	}
+
+	// Nothing moves when everything fits.
+	short := "package main\n"
+	m.currentDocument.Body = short
+	out, err = glamourRender(m, short)
+	if err != nil {
+		t.Fatalf("glamourRender returned error: %v", err)
+	}
+	m, _ = m.update(contentRenderedMsg(out))
+	m = typeKeys(t, m, "l")
+	if m.xOffset != 0 {
+		t.Fatalf("expected no sideways scrolling when lines fit, got offset %d", m.xOffset)
+	}
+}
+
+func TestToggleRaw(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		cfg  Config
+	}{
+		{"plain", Config{}},
+		{"line numbers", Config{ShowLineNumbers: true}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			testToggleRaw(t, tc.cfg)
+		})
+	}
+}
+
+// testToggleRaw switches a long document to its source and back, checking
+// the same line stays at the top.
+func testToggleRaw(t *testing.T, cfg Config) {
+	m := newTestPager(t, cfg, "README.md", 80)
+	var b strings.Builder
+	for i := range 30 {
+		fmt.Fprintf(&b, "## Section %d\n\nParagraph %d is here.\n\n", i, i)
+	}
+	body := b.String()
+	m.currentDocument.Body = body
+	render := func() {
+		t.Helper()
+		out, err := glamourRender(m, body)
+		if err != nil {
+			t.Fatalf("glamourRender returned error: %v", err)
+		}
+		m, _ = m.update(contentRenderedMsg(out))
+	}
+	topLine := func() string {
+		l := stripGutter(stripANSI(strings.Split(m.rendered, "\n")[m.viewport.YOffset]), m.gutterWidth())
+		return strings.TrimSpace(l)
+	}
+
+	render()
+	for i, l := range strings.Split(stripANSI(m.rendered), "\n") {
+		if strings.Contains(l, "Paragraph 12") {
+			m.viewport.SetYOffset(i)
+			break
+		}
+	}
+
+	m = typeKeys(t, m, "~")
+	if m.statusMessage != "Raw" || !m.currentDocument.raw {
+		t.Fatalf("expected to switch to the source, got status %q", m.statusMessage)
+	}
+	render()
+	if m.rendered != body {
+		t.Fatal("expected the source to be shown as is")
+	}
+	if got := topLine(); got != "Paragraph 12 is here." {
+		t.Fatalf("expected the same line at the top of the source, got %q", got)
+	}
+	var status strings.Builder
+	m.statusBarView(&status)
+	if !strings.Contains(stripANSI(status.String()), " raw ") {
+		t.Fatal("expected the status bar to show the source is shown")
+	}
+
+	// Each section takes four lines of the source.
+	m.viewport.SetYOffset(4 * 20)
+	m = typeKeys(t, m, "~")
+	if m.statusMessage != "Rendered" {
+		t.Fatalf("unexpected status %q", m.statusMessage)
+	}
+	render()
+	if got := topLine(); got != "## Section 20" {
+		t.Fatalf("expected the same heading at the top when rendered, got %q", got)
+	}
+}
+
+func TestFrontMatter(t *testing.T) {
+	const content = "---\ntitle: Notes\ntags:\n  - go\n  - tui\n---\n# Notes\n\nSome text.\n"
+
+	doc := markdown{Note: "notes.md"}
+	doc.setContent([]byte(content))
+	if doc.frontMatter != "title: Notes\ntags:\n  - go\n  - tui\n" {
+		t.Fatalf("unexpected front matter %q", doc.frontMatter)
+	}
+	if doc.Body != "# Notes\n\nSome text.\n" {
+		t.Fatalf("expected the front matter to be left out of the body, got %q", doc.Body)
+	}
+
+	// Other files are shown as they are.
+	yaml := markdown{Note: "config.yaml"}
+	yaml.setContent([]byte(content))
+	if yaml.Body != content || yaml.frontMatter != "" {
+		t.Fatalf("expected a YAML file to be kept whole, got %q", yaml.Body)
+	}
+
+	var lines []string
+	for _, l := range frontMatterLines(doc.frontMatter) {
+		lines = append(lines, stripANSI(l))
+	}
+	want := []string{"title  Notes", "tags   ", "       - go", "       - tui"}
+	if !slices.Equal(lines, want) {
+		t.Fatalf("expected aligned keys and values %q, got %q", want, lines)
+	}
+
+	m := newTestPager(t, Config{}, "notes.md", 80)
+	m = typeKeys(t, m, "-")
+	if m.statusMessage != "No front matter" {
+		t.Fatalf("expected no front matter to show, got status %q", m.statusMessage)
+	}
+
+	m.currentDocument = doc
+	height := m.viewport.Height
+	m = typeKeys(t, m, "-")
+	if m.statusMessage != "Front matter shown" {
+		t.Fatalf("expected the front matter to be shown, got status %q", m.statusMessage)
+	}
+	if got := m.viewport.Height; got != height-5 {
+		t.Fatalf("expected the viewport to make room for the front matter, got height %d, want %d", got, height-5)
+	}
+	if !strings.Contains(stripANSI(m.View()), "title  Notes") {
+		t.Fatal("expected the front matter above the document")
+	}
+
+	m = typeKeys(t, m, "-")
+	if m.statusMessage != "Front matter hidden" || m.viewport.Height != height {
+		t.Fatalf("expected the front matter to be hidden, got status %q and height %d", m.statusMessage, m.viewport.Height)
+	}
+}
+
+func TestFrontMatter_LineNumbers(t *testing.T) {
+	content := "---\ntitle: Notes\n---\n# Notes\n\n" + strings.Repeat("Some text.\n\n", 50)
+
+	m := newTestPager(t, Config{ShowLineNumbers: true}, "notes.md", 80)
+	m.currentDocument.setContent([]byte(content))
+	out, err := glamourRender(m, m.currentDocument.Body)
+	if err != nil {
+		t.Fatalf("glamourRender returned error: %v", err)
+	}
+	m, _ = m.update(contentRenderedMsg(out))
+
+	// The three lines of front matter are counted, so the numbers match the
+	// file.
+	if l := gutterLine(m.rendered, 4); l != 0 {
+		t.Fatalf("expected the first line to be numbered 4, got line %d for it", l)
+	}
+	m = typeKeys(t, m, "5", "G")
+	if m.viewport.YOffset != 1 {
+		t.Fatalf("expected 5G to go to the second line, got offset %d", m.viewport.YOffset)
+	}
+
+	m = typeKeys(t, m, "#")
+	if m.statusMessage != "Relative line numbers" {
+		t.Fatalf("unexpected status %q", m.statusMessage)
+	}
+	lines := strings.Split(stripANSI(m.viewport.View()), "\n")
+	if got := strings.TrimSpace(lines[0][:lineNumberWidth]); got != "5" {
+		t.Fatalf("expected the top line to keep its number, got %q", got)
+	}
+	if got := strings.TrimSpace(lines[1][:lineNumberWidth]); got != "1" {
+		t.Fatalf("expected the line below it to be 1 away, got %q", got)
+	}
+}
+
+func TestReadingTime(t *testing.T) {
+	prose := strings.Repeat("word ", 450)
+	body := "# Notes\n\n" + prose + "\n\n```go\nfunc main() { fmt.Println(\"hi\") }\n```\n"
+
+	if got, want := countProseWords(body), countWords("# Notes\n\n"+prose); got != want {
+		t.Fatalf("expected code blocks to be left out of %d words, got %d", want, got)
+	}
+
+	m := newTestPager(t, Config{ShowReadingTime: true, ReadingTimeSkipCode: true}, "notes.md", 100)
+	m.currentDocument.Body = body
+	m, _ = m.update(contentRenderedMsg(body))
+
+	var b strings.Builder
+	m.statusBarView(&b)
+	if got := stripANSI(b.String()); !strings.Contains(got, " 451 words · 3 min") {
+		t.Fatalf("expected the word count and reading time in the status bar, got %q", got)
+	}
+
+	// Too narrow to leave the note enough room.
+	m.common.width = 40
+	b.Reset()
+	m.statusBarView(&b)
+	if got := stripANSI(b.String()); strings.Contains(got, "words") {
+		t.Fatalf("expected the reading time to give way to the note, got %q", got)
+	}
+
+	m = typeKeys(t, m, "t")
+	if m.statusMessage != "Reading time hidden" || m.readingTimeView() != "" {
+		t.Fatalf("expected the reading time to be hidden, got status %q", m.statusMessage)
+	}
+
+	// A reload counts the words again.
+	m = typeKeys(t, m, "t")
+	m.currentDocument.Body = "Just a few words."
+	m, _ = m.update(contentRenderedMsg(m.currentDocument.Body))
+	if got := m.readingTimeView(); got != " 4 words · 1 min" {
+		t.Fatalf("expected the reload to be counted, got %q", got)
+	}
+}
+
+func TestLinePosition(t *testing.T) {
+	lines := make([]string, 100)
+	for i := range lines {
+		lines[i] = fmt.Sprintf("entry %d", i)
+	}
+	m := newTestPager(t, Config{ShowLinePosition: true}, "app.log", 100)
+	m, _ = m.update(contentRenderedMsg(strings.Join(lines, "\n")))
+	m.viewport.SetYOffset(19)
+
+	var b strings.Builder
+	m.statusBarView(&b)
+	if got := stripANSI(b.String()); !strings.Contains(got, " L20/100 ") {
+		t.Fatalf("expected the line position next to the scroll percent, got %q", got)
+	}
+
+	m, _ = m.update(tea.KeyMsg{Type: tea.KeyCtrlG})
+	if m.statusMessage != "Line position hidden" || m.linePositionView() != "" {
+		t.Fatalf("expected the line position to be hidden, got status %q", m.statusMessage)
+	}
+}
+
+func TestGitStatus(t *testing.T) {
+	for out, want := range map[string]gitFileStatus{
+		"":                  gitStatusClean,
+		" M notes.md\n":     gitStatusModified,
+		"A  notes.md\n":     gitStatusModified,
+		"?? notes.md\n":     gitStatusUntracked,
+		"!! notes.md\n":     gitStatusUnknown,
+		"MM notes.md\n?? x": gitStatusModified,
+	} {
+		if got := parseGitStatus([]byte(out)); got != want {
+			t.Errorf("parseGitStatus(%q) = %d, want %d", out, got, want)
+		}
+	}
+
+	m := newTestPager(t, Config{}, "notes.md", 80)
+	m.currentDocument.localPath = "/docs/notes.md"
+	m, _ = m.update(gitStatusMsg{path: "/docs/other.md", status: gitStatusModified})
+	if got := m.gitStatusView(statusBarBg); got != "" {
+		t.Fatalf("expected no indicator for another file, got %q", got)
+	}
+
+	m, _ = m.update(gitStatusMsg{path: "/docs/notes.md", status: gitStatusModified})
+	var b strings.Builder
+	m.statusBarView(&b)
+	if got := stripANSI(b.String()); !strings.Contains(got, " ● ") {
+		t.Fatalf("expected the git indicator in the status bar, got %q", got)
+	}
+	if got := ansi.PrintableRuneWidth(b.String()); got != 80 {
+		t.Fatalf("expected the status bar to keep its width, got %d", got)
+	}
+}
+
+func TestCenterTopLine(t *testing.T) {
+	m := newTestPager(t, Config{}, "app.log", 80)
+	m, _ = m.update(contentRenderedMsg(strings.Repeat("line\n", 100)))
+	m.viewport.SetYOffset(50)
+
+	m = typeKeys(t, m, "z")
+	if m.viewport.YOffset != 50 {
+		t.Fatalf("expected a single z to wait for the second, got offset %d", m.viewport.YOffset)
+	}
+	m = typeKeys(t, m, "z")
+	if want := 50 - m.viewport.Height/2; m.viewport.YOffset != want {
+		t.Fatalf("expected zz to move the top line to the middle, got offset %d, want %d", m.viewport.YOffset, want)
+	}
+
+	m.viewport.SetYOffset(3)
+	m = typeKeys(t, m, "z", "z")
+	if m.viewport.YOffset != 0 {
+		t.Fatalf("expected zz to stop at the top, got offset %d", m.viewport.YOffset)
+	}
+}
+
+func TestScrollStep(t *testing.T) {
+	m := newTestPager(t, Config{ScrollStep: 5}, "app.log", 80)
+	m, _ = m.update(contentRenderedMsg(strings.Repeat("line\n", 100)))
+
+	m = typeKeys(t, m, "d", "d")
+	if m.viewport.YOffset != 10 {
+		t.Fatalf("expected d to scroll by the step, got offset %d", m.viewport.YOffset)
+	}
+	m, _ = m.update(tea.MouseMsg{Button: tea.MouseButtonWheelUp, Action: tea.MouseActionPress})
+	if m.viewport.YOffset != 5 {
+		t.Fatalf("expected the wheel to scroll by the step, got offset %d", m.viewport.YOffset)
+	}
+	m = typeKeys(t, m, "u", "u")
+	if m.viewport.YOffset != 0 {
+		t.Fatalf("expected u to stop at the top, got offset %d", m.viewport.YOffset)
+	}
+
+	// Without a step, d scrolls half a page.
+	m = newTestPager(t, Config{}, "app.log", 80)
+	m, _ = m.update(contentRenderedMsg(strings.Repeat("line\n", 100)))
+	m = typeKeys(t, m, "d")
+	if m.viewport.YOffset != m.viewport.Height/2 {
+		t.Fatalf("expected d to scroll half a page, got offset %d", m.viewport.YOffset)
+	}
+}
+
+func TestSmoothScroll(t *testing.T) {
+	m := newTestPager(t, Config{SmoothScroll: true}, "app.log", 80)
+	m, _ = m.update(contentRenderedMsg(strings.Repeat("line\n", 200)))
+	bottom := m.viewport.TotalLineCount() - m.viewport.Height
+
+	m = typeKeys(t, m, "G")
+	if m.viewport.YOffset != 0 || m.smoothScroll == nil {
+		t.Fatalf("expected G to start scrolling, got offset %d", m.viewport.YOffset)
+	}
+	frames := 0
+	for m.smoothScroll != nil {
+		prev := m.viewport.YOffset
+		m, _ = m.update(smoothScrollMsg{m.smoothScroll})
+		if m.viewport.YOffset <= prev {
+			t.Fatalf("expected every frame to scroll down, got offset %d after %d", m.viewport.YOffset, prev)
+		}
+		frames++
+	}
+	if m.viewport.YOffset != bottom || frames != smoothScrollFrames {
+		t.Fatalf("expected to reach the bottom in %d frames, got offset %d after %d", smoothScrollFrames, m.viewport.YOffset, frames)
+	}
+
+	// A key press mid-scroll jumps to where it was going, and frames of the
+	// scroll are ignored from then on.
+	m = typeKeys(t, m, "g")
+	scroll := m.smoothScroll
+	m, _ = m.update(smoothScrollMsg{scroll})
+	m = typeKeys(t, m, "j")
+	if m.viewport.YOffset != 1 || m.smoothScroll != nil {
+		t.Fatalf("expected j to go on from the top, got offset %d", m.viewport.YOffset)
+	}
+	m, _ = m.update(smoothScrollMsg{scroll})
+	if m.viewport.YOffset != 1 {
+		t.Fatalf("expected a cancelled scroll to stay cancelled, got offset %d", m.viewport.YOffset)
+	}
+
+	// So does the mouse wheel.
+	m = typeKeys(t, m, "G")
+	scroll = m.smoothScroll
+	m, _ = m.update(tea.MouseMsg{Button: tea.MouseButtonWheelUp, Action: tea.MouseActionPress})
+	m, _ = m.update(smoothScrollMsg{scroll})
+	if m.viewport.YOffset >= bottom || m.viewport.YOffset == 1 || m.smoothScroll != nil {
+		t.Fatalf("expected the wheel to scroll up from the bottom, got offset %d", m.viewport.YOffset)
+	}
+}
+
+func TestChromaTheme(t *testing.T) {
+	body := "# Code\n\n```go\nfunc main() {\n\treturn\n}\n```\n"
+	render := func(cfg Config, note string) string {
+		t.Helper()
+		m := newTestPager(t, cfg, note, 80)
+		out, err := glamourRender(m, body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return out
+	}
+
+	styled := render(Config{}, "notes.md")
+	themed := render(Config{ChromaTheme: "monokai"}, "notes.md")
+	if themed == styled || stripANSI(themed) != stripANSI(styled) {
+		t.Fatalf("expected only the colors of the code to change, got\n%s", themed)
+	}
+	if render(Config{ChromaTheme: "monokai", InlineCodeEmphasis: inlineCodeBold}, "notes.md") != themed {
+		t.Fatal("expected inline code emphasis not to affect the code block")
+	}
+	if render(Config{ChromaTheme: "monokai"}, "main.go") == render(Config{}, "main.go") {
+		t.Fatal("expected the theme to apply to source files too")
+	}
+
+	// An unknown theme is said once there's a document, and again when the
+	// configuration is reloaded.
+	warning := `Unknown chroma theme "nope", using the style's`
+	m := newTestPager(t, Config{Warnings: []string{warning}}, "notes.md", 80)
+	m, _ = m.update(contentRenderedMsg(body))
+	if m.statusMessage != warning {
+		t.Fatalf("expected the warning to be shown, got %q", m.statusMessage)
+	}
+	m.common.cfg.ReloadConfig = func() (Config, error) {
+		return Config{GlamourStyle: "dark", ColorDepth: colorDepthTrueColor, Warnings: []string{warning}}, nil
+	}
+	_ = m.reloadConfig()
+	if m.statusMessage != warning {
+		t.Fatalf("expected the warning to be shown again after reloading, got %q", m.statusMessage)
+	}
+}
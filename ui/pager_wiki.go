@@ -0,0 +1,184 @@
+package ui
+
+import (
+	"io/fs"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/charmbracelet/log"
+	"github.com/yuin/goldmark/ast"
+)
+
+var (
+	// wikiLinkPattern matches wiki style links like [[Note Name]],
+	// [[Note Name#Heading]] and [[Note Name|Alias]].
+	wikiLinkPattern = regexp.MustCompile(`\[\[([^\[\]|#\n]*)(?:#([^\[\]|\n]*))?(?:\|([^\[\]\n]*))?\]\]`)
+
+	// codeSpanPattern matches code spans on a single line, which wiki links
+	// inside of are left alone.
+	codeSpanPattern = regexp.MustCompile("`[^`]*`")
+)
+
+// wikiLinks returns the wiki links in the lines of a block, like a paragraph
+// or a heading. goldmark doesn't know about them, so they're found in the
+// source text.
+func wikiLinks(block ast.Node, source []byte) []rawLink {
+	var out []rawLink
+	lines := block.Lines()
+	for i := 0; i < lines.Len(); i++ {
+		seg := lines.At(i)
+		line := seg.Value(source)
+		masked := codeSpanPattern.ReplaceAllFunc(line, func(s []byte) []byte {
+			return []byte(strings.Repeat(" ", len(s)))
+		})
+
+		for _, m := range wikiLinkPattern.FindAllSubmatchIndex(masked, -1) {
+			// ![[Note Name]] embeds a note rather than linking to it.
+			if m[0] > 0 && line[m[0]-1] == '!' {
+				continue
+			}
+			name := strings.TrimSpace(string(line[m[2]:m[3]]))
+			target := name
+			if m[4] >= 0 {
+				target += "#" + strings.TrimSpace(string(line[m[4]:m[5]]))
+			}
+			label := strings.TrimSpace(string(line[m[2]:max(m[3], m[5])]))
+			if m[6] >= 0 {
+				label = strings.TrimSpace(string(line[m[6]:m[7]]))
+			}
+			if strings.Trim(target, "#") == "" {
+				continue
+			}
+			out = append(out, rawLink{wiki: target, label: label, offset: seg.Start + m[0]})
+		}
+	}
+	return out
+}
+
+// resolveWikiLinks sets the href of wiki links, relative to the current file,
+// by looking for the document they name under the root directory. The index
+// of documents is taken from wiki, if given, as walking the root can take a
+// while.
+func resolveWikiLinks(rootDir, currentFilePath string, links []rawLink, wiki *wikiIndexCache) {
+	var index map[string][]string
+	for i, l := range links {
+		if l.wiki == "" {
+			continue
+		}
+		if index == nil {
+			index = wiki.get(rootDir)
+		}
+		links[i].href = wikiHref(rootDir, currentFilePath, l.wiki, index)
+	}
+}
+
+// wikiIndexCache keeps the wiki index of a root directory, so that it's only
+// built once rather than for every document, or every time one is loaded.
+type wikiIndexCache struct {
+	root  string
+	index map[string][]string
+}
+
+// get returns the wiki index of root, building it if it isn't kept yet. A nil
+// cache builds it every time.
+func (c *wikiIndexCache) get(root string) map[string][]string {
+	if c == nil {
+		return wikiIndex(root)
+	}
+	if c.index == nil || c.root != root {
+		c.root, c.index = root, wikiIndex(root)
+	}
+	return c.index
+}
+
+// reset forgets the index, so that it's built again with documents that
+// have been added or removed since.
+func (c *wikiIndexCache) reset() {
+	c.index = nil
+}
+
+// wikiIndex maps the lower case file names of the markdown documents under
+// root to their paths relative to root, skipping hidden directories.
+func wikiIndex(root string) map[string][]string {
+	index := map[string][]string{}
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil //nolint:nilerr
+		}
+		if d.IsDir() {
+			if path != root && strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		name := strings.ToLower(d.Name())
+		if !strings.HasSuffix(name, ".md") && !strings.HasSuffix(name, ".markdown") {
+			return nil
+		}
+		if rel, err := filepath.Rel(root, path); err == nil {
+			index[name] = append(index[name], rel)
+		}
+		return nil
+	})
+	if err != nil {
+		log.Debug("error indexing wiki links", "error", err)
+	}
+	return index
+}
+
+// wikiHref turns the target of a wiki link into an href relative to the
+// current file. Names with a slash are paths from the root directory;
+// otherwise a document of that name next to the current file is preferred,
+// then the one closest to the root. Names that can't be found are kept as
+// is, so that they show up as broken links.
+func wikiHref(rootDir, currentFilePath, target string, index map[string][]string) string {
+	name, frag := splitFragment(target)
+	name = strings.TrimSpace(name)
+	if frag = strings.TrimSpace(frag); frag != "" {
+		frag = "#" + headingSlug(frag)
+	}
+	if name == "" {
+		return frag
+	}
+
+	file := name
+	if lower := strings.ToLower(file); !strings.HasSuffix(lower, ".md") && !strings.HasSuffix(lower, ".markdown") {
+		file += ".md"
+	}
+	file = filepath.FromSlash(file)
+
+	rootAbs, err := filepath.Abs(rootDir)
+	if err != nil {
+		return filepath.ToSlash(file) + frag
+	}
+	dirAbs, err := filepath.Abs(filepath.Dir(currentFilePath))
+	if err != nil {
+		return filepath.ToSlash(file) + frag
+	}
+
+	found := ""
+	if strings.ContainsAny(name, `/\`) {
+		found = file
+	} else {
+		dir, _ := filepath.Rel(rootAbs, dirAbs)
+		for _, p := range index[strings.ToLower(file)] {
+			if filepath.Dir(p) == dir {
+				found = p
+				break
+			}
+			if found == "" || strings.Count(p, string(filepath.Separator)) < strings.Count(found, string(filepath.Separator)) {
+				found = p
+			}
+		}
+	}
+	if found == "" {
+		return filepath.ToSlash(file) + frag
+	}
+
+	rel, err := filepath.Rel(dirAbs, filepath.Join(rootAbs, found))
+	if err != nil {
+		return filepath.ToSlash(file) + frag
+	}
+	return filepath.ToSlash(rel) + frag
+}
@@ -0,0 +1,156 @@
+package ui
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/muesli/reflow/ansi"
+)
+
+// Columns scrolled sideways per key press when lines are too wide.
+const horizontalScrollStep = 8
+
+// Number of words of the top line we look for to find our place again after
+// the document is re-wrapped.
+const anchorWords = 3
+
+// scrollAnchor is a logical position in the document that survives
+// re-rendering with a different wrap width, unlike a line offset.
+type scrollAnchor struct {
+	// Heading at or above the top of the viewport, or -1.
+	heading int
+
+	// Start of the first non-blank line at the top of the viewport, with
+	// whitespace normalized.
+	text string
+}
+
+// normalizeSpace collapses runs of whitespace into single spaces.
+func normalizeSpace(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// currentScrollAnchor records where we are in the document.
+func (m pagerModel) currentScrollAnchor() scrollAnchor {
+	a := scrollAnchor{heading: -1}
+	top := m.viewport.YOffset
+
+	for i, h := range m.headings {
+		if h.Line < 0 || h.Line > top {
+			continue
+		}
+		a.heading = i
+	}
+
+	lines := strings.Split(stripANSI(m.rendered), "\n")
+	for l := top; l < min(len(lines), top+m.viewport.Height); l++ {
+		words := strings.Fields(lines[l])
+		if len(words) == 0 {
+			continue
+		}
+		a.text = strings.Join(words[:min(anchorWords, len(words))], " ")
+		break
+	}
+	return a
+}
+
+// anchorLine returns the rendered line the anchor points at, or -1.
+func (m pagerModel) anchorLine(a scrollAnchor) int {
+	from := 0
+	if a.heading >= 0 && a.heading < len(m.headings) && m.headings[a.heading].Line >= 0 {
+		from = m.headings[a.heading].Line
+	}
+	if a.text == "" {
+		if from > 0 {
+			return from
+		}
+		return -1
+	}
+
+	lines := strings.Split(stripANSI(m.rendered), "\n")
+	for l := from; l < len(lines); l++ {
+		if strings.Contains(normalizeSpace(lines[l]), a.text) {
+			return l
+		}
+	}
+	if from > 0 {
+		return from
+	}
+	return -1
+}
+
+// toggleWrap switches between wrapped and unwrapped rendering, keeping the
+// same part of the document at the top of the viewport.
+func (m *pagerModel) toggleWrap() tea.Cmd {
+	m.noWrap = !m.noWrap
+	a := m.currentScrollAnchor()
+	m.pendingAnchor = &a
+
+	// Long lines are cut off at the edge of the viewport when they aren't
+	// wrapped, so let h and l scroll sideways to read them.
+	msg := "Wrap on"
+	if m.noWrap {
+		msg = "Wrap off, h/l to scroll sideways"
+	} else {
+		m.xOffset = 0
+		m.viewport.SetXOffset(0)
+	}
+	return tea.Batch(
+		m.showStatusMessage(pagerStatusMessage{msg, false}),
+		renderWithGlamour(*m, m.currentDocument.Body),
+	)
+}
+
+func (m *pagerModel) toggleCompact() tea.Cmd {
+	m.compact = !m.compact
+	a := m.currentScrollAnchor()
+	m.pendingAnchor = &a
+
+	msg := "Compact mode on"
+	if !m.compact {
+		msg = "Compact mode off"
+	}
+	return tea.Batch(
+		m.showStatusMessage(pagerStatusMessage{msg, false}),
+		renderWithGlamour(*m, m.currentDocument.Body),
+	)
+}
+
+// widestLine returns the width of the widest line of the rendered output.
+func widestLine(rendered string) int {
+	widest := 0
+	for _, l := range strings.Split(rendered, "\n") {
+		widest = max(widest, ansi.PrintableRuneWidth(l))
+	}
+	return widest
+}
+
+// leavesOn reports whether a key that goes back to the file listing should
+// do so, rather than scroll back to the start of wide lines first or do
+// whatever else it's bound to.
+func (m pagerModel) leavesOn(key string) bool {
+	switch m.keys.action(key) {
+	case "":
+		return true
+	case actionScrollLeft:
+		return m.xOffset == 0
+	default:
+		return false
+	}
+}
+
+// scrollSideways scrolls the content left or right by the given number of
+// columns, as far as there are lines wider than the viewport. Lines that fit
+// don't move.
+func (m *pagerModel) scrollSideways(delta int) tea.Cmd {
+	if delta > 0 || m.xOffset > 0 {
+		maxOffset := max(0, widestLine(m.rendered)-m.viewport.Width)
+		m.xOffset = max(0, min(maxOffset, m.xOffset+delta))
+	}
+	m.viewport.SetXOffset(m.xOffset)
+	if m.common.cfg.HighPerformancePager {
+		return viewport.Sync(m.viewport)
+	}
+	return nil
+}
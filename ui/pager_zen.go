@@ -0,0 +1,63 @@
+package ui
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const defaultZenWidth = 80
+
+var zenDimmedStyle = lipgloss.NewStyle().Foreground(midGray).Render
+
+// zenState is the layout to go back to when leaving zen mode.
+type zenState struct {
+	showHelp bool
+}
+
+// zenMargin returns the margin that centers a column of the zen reading
+// width in the window.
+func (m pagerModel) zenMargin() int {
+	width := int(m.common.cfg.ZenWidth) //nolint:gosec
+	if width <= 0 {
+		width = defaultZenWidth
+	}
+	return max(0, (m.common.width-width)/2)
+}
+
+// toggleZen switches between the regular layout and a distraction-free one
+// without status bar and help, with the document in a centered column.
+func (m *pagerModel) toggleZen() tea.Cmd {
+	a := m.currentScrollAnchor()
+	m.pendingAnchor = &a
+
+	msg := "Zen mode on"
+	if m.zen == nil {
+		m.zen = &zenState{showHelp: m.showHelp}
+		m.showHelp = false
+	} else {
+		m.showHelp = m.zen.showHelp
+		m.zen = nil
+		msg = "Zen mode off"
+	}
+	m.setSize(m.common.width, m.common.height)
+
+	return tea.Batch(
+		m.showStatusMessage(pagerStatusMessage{msg, false}),
+		renderWithGlamour(*m, m.currentDocument.Body),
+	)
+}
+
+// dimUnfocusedLines dims all lines of a viewport view except for the one
+// in the middle, which is where the reader's eyes are supposed to be.
+func dimUnfocusedLines(view string, height int) string {
+	lines := strings.Split(view, "\n")
+	focus := height / 2
+	for i, l := range lines {
+		if i != focus {
+			lines[i] = zenDimmedStyle(stripANSI(l))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
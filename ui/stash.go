@@ -318,7 +318,7 @@ func (m stashModel) getVisibleMarkdowns() []*markdown {
 // alters the model.
 func (m *stashModel) openMarkdown(md *markdown) tea.Cmd {
 	m.viewState = stashStateLoadingDocument
-	cmd := loadLocalMarkdown(md)
+	cmd := loadLocalMarkdown(md, m.common.cfg.Encoding)
 	return tea.Batch(cmd, m.spinner.Tick)
 }
 
@@ -849,7 +849,9 @@ func (m stashModel) populatedView() string {
 
 // COMMANDS
 
-func loadLocalMarkdown(md *markdown) tea.Cmd {
+// loadLocalMarkdown reads a local document, decoding it from the given
+// encoding.
+func loadLocalMarkdown(md *markdown, encoding string) tea.Cmd {
 	return func() tea.Msg {
 		if md.localPath == "" {
 			return errMsg{errors.New("could not load file: missing path")}
@@ -860,7 +862,16 @@ func loadLocalMarkdown(md *markdown) tea.Cmd {
 			log.Debug("error reading local file", "error", err)
 			return errMsg{err}
 		}
+		data, err = decodeDocument(data, encoding)
+		if err != nil {
+			log.Debug("error decoding local file", "error", err)
+			return errMsg{err}
+		}
+		md.binary = isBinaryContent(data)
 		md.Body = string(data)
+		if md.binary {
+			md.Body = ""
+		}
 		return fetchedMarkdownMsg(md)
 	}
 }
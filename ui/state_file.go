@@ -0,0 +1,46 @@
+package ui
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/charmbracelet/log"
+)
+
+// loadStateFile reads state kept between sessions, like where documents were
+// left, from a JSON file into v. It reports whether there was any: a file
+// that's missing, or that can't be read or parsed, is taken as none, the
+// latter being logged as what it holds.
+func loadStateFile(path, what string, v any) bool {
+	if path == "" {
+		return false
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			log.Debug("error reading "+what, "file", path, "error", err)
+		}
+		return false
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		log.Debug("error parsing "+what, "file", path, "error", err)
+		return false
+	}
+	return true
+}
+
+// saveStateFile writes state kept between sessions to a JSON file, creating
+// its directory if needed.
+func saveStateFile(path string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err //nolint:wrapcheck
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil { //nolint:gosec
+		return err //nolint:wrapcheck
+	}
+	return os.WriteFile(path, data, 0o600) //nolint:wrapcheck
+}
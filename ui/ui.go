@@ -10,7 +10,6 @@ import (
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/glamour/styles"
-	"github.com/charmbracelet/glow/v2/utils"
 	"github.com/charmbracelet/log"
 	"github.com/muesli/gitcha"
 	te "github.com/muesli/termenv"
@@ -95,6 +94,9 @@ type commonModel struct {
 	cwd    string
 	width  int
 	height int
+
+	// Markdown documents under cwd, for resolving wiki links.
+	wikiIndex wikiIndexCache
 }
 
 type model struct {
@@ -111,6 +113,16 @@ type model struct {
 	localFileFinder chan gitcha.SearchResult
 }
 
+// quit remembers where the document was scrolled to and the documents
+// viewed, then quits.
+func (m *model) quit() tea.Cmd {
+	if m.state == stateShowDocument {
+		m.pager.savePositions()
+		m.pager.saveRecent()
+	}
+	return tea.Quit
+}
+
 // unloadDocument unloads a document from the pager. Note that while this
 // method alters the model we also need to send along any commands returned.
 func (m *model) unloadDocument() []tea.Cmd {
@@ -199,17 +211,18 @@ func (m model) Init() tea.Cmd {
 			log.Error("unable to read file", "file", m.common.cfg.Path, "error", err)
 			return func() tea.Msg { return errMsg{err} }
 		}
-		body := string(utils.RemoveFrontmatter(content))
-		m.pager.currentDocument.Body = body
-		if m.pager.currentDocument.localPath != "" && m.common.cwd != "" {
-			links, err := followableLinksForDocument(m.common.cwd, m.pager.currentDocument.localPath, body)
-			if err != nil {
-				log.Debug("error extracting followable links", "error", err)
-			}
-			m.pager.links = links
-			m.pager.focusedLink = -1
+		content, err = decodeDocument(content, m.common.cfg.Encoding)
+		if err != nil {
+			log.Error("unable to decode file", "file", m.common.cfg.Path, "error", err)
+			return func() tea.Msg { return errMsg{err} }
 		}
-		cmds = append(cmds, renderWithGlamour(m.pager, body))
+		if isBinaryContent(content) {
+			m.pager.currentDocument.binary = true
+			content = nil
+		}
+		m.pager.currentDocument.setContent(content)
+		m.pager.extractLinks()
+		cmds = append(cmds, renderWithGlamour(m.pager, m.pager.currentDocument.Body))
 	}
 
 	return tea.Batch(cmds...)
@@ -227,6 +240,12 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
+		// pass through all keys if the pager is capturing input, like when
+		// an overlay is open
+		if m.state == stateShowDocument && m.pager.capturesKeys() && msg.String() != "ctrl+c" {
+			break
+		}
+
 		switch msg.String() {
 		case "esc":
 			if m.state == stateShowDocument || m.stash.viewState == stashStateLoadingDocument {
@@ -257,10 +276,10 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				}
 			}
 
-			return m, tea.Quit
+			return m, m.quit()
 
 		case "left", "h", "delete":
-			if m.state == stateShowDocument {
+			if m.state == stateShowDocument && (msg.String() == "delete" || m.pager.leavesOn(msg.String())) {
 				cmds = append(cmds, m.unloadDocument()...)
 				return m, tea.Batch(cmds...)
 			}
@@ -270,7 +289,7 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 		// Ctrl+C always quits no matter where in the application you are.
 		case "ctrl+c":
-			return m, tea.Quit
+			return m, m.quit()
 		}
 
 	// Window size is received when starting up and on every resize
@@ -288,20 +307,20 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case fetchedMarkdownMsg:
 		// We've loaded a markdown file's contents for rendering
 		m.pager.currentDocument = *msg
-		body := string(utils.RemoveFrontmatter([]byte(msg.Body)))
-		m.pager.currentDocument.Body = body
-		if m.pager.currentDocument.localPath != "" && m.common.cwd != "" {
-			links, err := followableLinksForDocument(m.common.cwd, m.pager.currentDocument.localPath, body)
-			if err != nil {
-				log.Debug("error extracting followable links", "error", err)
-			}
-			m.pager.links = links
-			m.pager.focusedLink = -1
-		} else {
-			m.pager.links = nil
-			m.pager.focusedLink = -1
+		m.pager.currentDocument.setContent([]byte(msg.Body))
+		focused := m.pager.focusedLink
+		var prev followableLink
+		if focused >= 0 && focused < len(m.pager.links) {
+			prev = m.pager.links[focused]
+		}
+		m.pager.extractLinks()
+		m.pager.restorePosition()
+		m.pager.rememberRecent()
+		if m.pager.reloading && focused >= 0 {
+			// Keep our place when the document changed on disk.
+			m.pager.refocusLink(prev, focused)
 		}
-		cmds = append(cmds, renderWithGlamour(m.pager, body))
+		cmds = append(cmds, renderWithGlamour(m.pager, m.pager.currentDocument.Body))
 
 	case contentRenderedMsg:
 		m.state = stateShowDocument
@@ -342,6 +361,10 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	case stateShowDocument:
 		newPagerModel, cmd := m.pager.update(msg)
+		if _, ok := msg.(tea.KeyMsg); ok {
+			cmds = append(cmds, newPagerModel.noteActivity())
+		}
+		cmds = append(cmds, newPagerModel.renumberLines())
 		m.pager = newPagerModel
 		cmds = append(cmds, cmd)
 	}
@@ -2,6 +2,8 @@
 package utils
 
 import (
+	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
 	"regexp"
@@ -16,19 +18,21 @@ import (
 
 // RemoveFrontmatter removes the front matter header of a markdown file.
 func RemoveFrontmatter(content []byte) []byte {
-	if frontmatterBoundaries := detectFrontmatter(content); frontmatterBoundaries[0] == 0 {
-		return content[frontmatterBoundaries[1]:]
-	}
-	return content
+	_, body := SplitFrontmatter(content)
+	return body
 }
 
 var yamlPattern = regexp.MustCompile(`(?m)^---\r?\n(\s*\r?\n)?`)
 
-func detectFrontmatter(c []byte) []int {
-	if matches := yamlPattern.FindAllIndex(c, 2); len(matches) > 1 {
-		return []int{matches[0][0], matches[1][1]}
+// SplitFrontmatter splits a markdown file into its front matter header,
+// without the --- lines around it, and the rest. The front matter is nil if
+// there's none.
+func SplitFrontmatter(content []byte) ([]byte, []byte) {
+	matches := yamlPattern.FindAllIndex(content, 2)
+	if len(matches) < 2 || matches[0][0] != 0 {
+		return nil, content
 	}
-	return []int{-1, -1}
+	return content[matches[0][1]:matches[1][0]], content[matches[1][1]:]
 }
 
 // ExpandPath expands tilde and all environment variables from the given path.
@@ -111,3 +115,27 @@ func GlamourStyle(style string, isCode bool) glamour.TermRendererOption {
 
 	return glamour.WithStyles(styleConfig)
 }
+
+// GlamourStyleConfig returns the style configuration for the given style name
+// or JSON path, so that it can be tweaked before rendering.
+func GlamourStyleConfig(style string) (ansi.StyleConfig, error) {
+	if style == styles.AutoStyle {
+		if lipgloss.HasDarkBackground() {
+			return styles.DarkStyleConfig, nil
+		}
+		return styles.LightStyleConfig, nil
+	}
+	if sc, ok := styles.DefaultStyles[style]; ok {
+		return *sc, nil
+	}
+
+	b, err := os.ReadFile(ExpandPath(style))
+	if err != nil {
+		return ansi.StyleConfig{}, fmt.Errorf("unable to read style: %w", err)
+	}
+	var sc ansi.StyleConfig
+	if err := json.Unmarshal(b, &sc); err != nil {
+		return ansi.StyleConfig{}, fmt.Errorf("unable to parse style: %w", err)
+	}
+	return sc, nil
+}